@@ -0,0 +1,356 @@
+// Package audit keeps an append-only, tamper-evident log of content
+// mutations. Each record is a line of JSON carrying the hash of the record
+// before it, so altering, removing or reordering records breaks the chain.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Actions recorded
+const (
+	ActionPut    = "put"
+	ActionRotate = "rotate"
+)
+
+// Outcomes of a mutation other than the gRPC code name of a failed call
+const (
+	// OutcomeOK is a mutation ServiceBus carried out
+	OutcomeOK = "ok"
+	// OutcomeServiceBusError is a mutation ServiceBus answered with an error
+	OutcomeServiceBusError = "servicebus_error"
+)
+
+// Record is an audited content mutation
+type Record struct {
+	// Seq numbers the records of a log from 1
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+
+	Action       string `json:"action"`
+	Caller       string `json:"caller"`
+	Contractorid int64  `json:"contractorid"`
+	Ordernumber  int64  `json:"ordernumber"`
+	Destination  string `json:"destination,omitempty"`
+	Filename     string `json:"filename"`
+	// ContentSHA256 is the hex SHA-256 of the file contents
+	ContentSHA256 string `json:"content_sha256"`
+	Size          int64  `json:"size"`
+
+	// ContentID and GUID identify the stored content if ServiceBus stored
+	// it. ContentID is also the content a rotate was asked to replace.
+	ContentID int64  `json:"content_id,omitempty"`
+	GUID      string `json:"guid,omitempty"`
+	// Outcome is OutcomeOK, OutcomeServiceBusError or the gRPC code name
+	// the call failed with
+	Outcome    string  `json:"outcome"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+
+	// PrevHash is the Hash of the previous record, empty for the first
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex SHA-256 of the record's JSON with Hash empty
+	Hash string `json:"hash"`
+}
+
+// ComputeHash returns the hash r should carry
+func (r Record) ComputeHash() string {
+	r.Hash = ""
+	contents, _ := json.Marshal(r)
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends records to a file, moving it aside to start a new one when it
+// would grow beyond maxBytes. Rotated files are never removed by the log.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+	seq      int64
+	lastHash string
+	now      func() time.Time
+}
+
+// Open opens the log at path, continuing the chain of the records already
+// in it and its rotated files. A maxBytes of 0 never rotates the file. A
+// partial last line, left by a write that failed, is removed.
+func Open(path string, maxBytes int64) (*Log, error) {
+	if err := repair(path); err != nil {
+		return nil, fmt.Errorf("Error repairing audit log %s: %s", path, err)
+	}
+	files, err := Files(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{path: path, maxBytes: maxBytes, now: time.Now}
+	for i := len(files) - 1; i >= 0 && l.seq == 0; i-- {
+		err := ReadFile(files[i], func(r *Record) error {
+			l.seq, l.lastHash = r.Seq, r.Hash
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// repair truncates the file at path after its last complete line, if it
+// exists and does not end with one
+func repair(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for offset := end; offset > 0; {
+		n := int64(len(buf))
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		if _, err := file.ReadAt(buf[:n], offset); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = offset + int64(i) + 1
+			if end == info.Size() {
+				return nil
+			}
+			break
+		}
+		end = offset
+	}
+	if err := file.Truncate(end); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (l *Log) openFile() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// rotatedSuffix formats the time a file was rotated so that rotated files
+// sort oldest first
+const rotatedSuffix = "20060102T150405.000000000"
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(l.path, l.path+"."+l.now().UTC().Format(rotatedSuffix)); err != nil {
+		return err
+	}
+	return l.openFile()
+}
+
+// Append numbers r, chains it to the previous record and writes it to disk
+// before returning. Time is set to now if it is zero.
+func (l *Log) Append(r *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.Time.IsZero() {
+		r.Time = l.now()
+	}
+	r.Time = r.Time.UTC()
+	r.Seq = l.seq + 1
+	r.PrevHash = l.lastHash
+	r.Hash = r.ComputeHash()
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return fmt.Errorf("Error rotating audit log %s: %s", l.path, err)
+		}
+	}
+	_, err = l.file.Write(line)
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		// Leave no partial line for the next record to follow
+		l.file.Truncate(l.size)
+		return err
+	}
+	l.size += int64(len(line))
+	l.seq, l.lastHash = r.Seq, r.Hash
+	return nil
+}
+
+// Close closes the current file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Files returns the rotated files of the log at path, oldest first, followed
+// by path itself if it exists
+func Files(path string) ([]string, error) {
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, filename := range rotated {
+		if _, err := time.Parse(rotatedSuffix, strings.TrimPrefix(filename, path+".")); err == nil {
+			files = append(files, filename)
+		}
+	}
+	sort.Strings(files)
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Read calls fn with each record read from r, stopping at the first error
+func Read(r io.Reader, fn func(*Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		record := &Record{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return fmt.Errorf("Error parsing line %d: %s", line, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ReadFile calls fn with each record in filename
+func ReadFile(filename string, fn func(*Record) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := Read(file, fn); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	return nil
+}
+
+// Verifier checks that records are numbered consecutively and chained by
+// their hashes
+type Verifier struct {
+	// Count is the number of records verified
+	Count int64
+	// First and Last are the first and last records verified
+	First, Last *Record
+}
+
+// Verify checks r follows the records verified before it. The first record
+// verified may start anywhere in a log, so that a log whose oldest files
+// were archived can still be checked.
+func (v *Verifier) Verify(r *Record) error {
+	if hash := r.ComputeHash(); r.Hash != hash {
+		return fmt.Errorf("Record %d has been altered: hash is %s, expected %s", r.Seq, r.Hash, hash)
+	}
+	if v.Last != nil {
+		if r.Seq != v.Last.Seq+1 {
+			return fmt.Errorf("Record %d follows record %d", r.Seq, v.Last.Seq)
+		}
+		if r.PrevHash != v.Last.Hash {
+			return fmt.Errorf("Record %d does not chain to record %d: prev_hash is %s, expected %s", r.Seq, v.Last.Seq, r.PrevHash, v.Last.Hash)
+		}
+	} else {
+		v.First = r
+	}
+	v.Last = r
+	v.Count++
+	return nil
+}
+
+// VerifyFiles verifies the records in files, in order
+func VerifyFiles(files []string) (*Verifier, error) {
+	v := &Verifier{}
+	for _, filename := range files {
+		if err := ReadFile(filename, v.Verify); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// Filter selects records. Zero fields place no restriction.
+type Filter struct {
+	Caller        string
+	Contractorid  int64
+	Ordernumber   int64
+	Filename      string
+	ContentSHA256 string
+	GUID          string
+	Outcome       string
+	Since         time.Time
+	Until         time.Time
+}
+
+// Matches reports whether r is selected by the filter
+func (f *Filter) Matches(r *Record) bool {
+	switch {
+	case f.Caller != "" && r.Caller != f.Caller:
+		return false
+	case f.Contractorid != 0 && r.Contractorid != f.Contractorid:
+		return false
+	case f.Ordernumber != 0 && r.Ordernumber != f.Ordernumber:
+		return false
+	case f.Filename != "" && r.Filename != f.Filename:
+		return false
+	case f.ContentSHA256 != "" && !strings.EqualFold(r.ContentSHA256, f.ContentSHA256):
+		return false
+	case f.GUID != "" && !strings.EqualFold(r.GUID, f.GUID):
+		return false
+	case f.Outcome != "" && r.Outcome != f.Outcome:
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLog(t *testing.T, maxBytes int64) (*Log, string) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "audit.log")
+	l, err := Open(path, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1489000000, 0)
+	l.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return l, path
+}
+
+func appendRecords(t *testing.T, l *Log, contractorIDs ...int64) {
+	for _, contractorID := range contractorIDs {
+		record := &Record{Action: ActionPut, Caller: "batchimport", Contractorid: contractorID, Filename: "photo.png", Outcome: OutcomeOK}
+		if err := l.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLogChainsRecordsAcrossRotation(t *testing.T) {
+	l, path := newTestLog(t, 600)
+	defer os.RemoveAll(filepath.Dir(path))
+	appendRecords(t, l, 1, 2, 3, 4, 5)
+	l.Close()
+
+	files, err := Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) < 3 || files[len(files)-1] != path {
+		t.Fatalf("Expected rotated files followed by %s but got %v", path, files)
+	}
+	v, err := VerifyFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Count != 5 || v.First.Seq != 1 || v.First.PrevHash != "" || v.Last.Seq != 5 {
+		t.Errorf("Expected records 1 to 5 but got %d records %+v to %+v", v.Count, v.First, v.Last)
+	}
+
+	// A reopened log continues the chain
+	l, err = Open(path, 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendRecords(t, l, 6)
+	l.Close()
+	files, _ = Files(path)
+	if v, err = VerifyFiles(files); err != nil || v.Last.Seq != 6 {
+		t.Errorf("Expected records to 6 but got %+v, %v", v.Last, err)
+	}
+}
+
+func TestOpenRemovesPartialLine(t *testing.T) {
+	for _, records := range [][]int64{{1, 2}, nil} {
+		l, path := newTestLog(t, 0)
+		defer os.RemoveAll(filepath.Dir(path))
+		appendRecords(t, l, records...)
+		l.Close()
+		// A write that failed partway through a record
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString(`{"seq":3,"time":"2017-03-08T19:06:40Z","act`)
+		file.Close()
+
+		l, err = Open(path, 0)
+		if err != nil {
+			t.Fatalf("Expected log with a partial line to open but got %v", err)
+		}
+		appendRecords(t, l, 3)
+		l.Close()
+		v, err := VerifyFiles([]string{path})
+		if err != nil || v.Count != int64(len(records)+1) || v.Last.Seq != v.Count {
+			t.Errorf("Expected records 1 to %d but got %+v, %v", len(records)+1, v.Last, err)
+		}
+	}
+}
+
+var tamperCases = []struct {
+	name     string
+	tamper   func(lines []string) []string
+	expected string
+}{
+	{
+		name: "altered",
+		tamper: func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"contractorid":2`, `"contractorid":9`, 1)
+			return lines
+		},
+		expected: "Record 2 has been altered",
+	},
+	{
+		name:     "removed",
+		tamper:   func(lines []string) []string { return append(lines[:1], lines[2:]...) },
+		expected: "Record 3 follows record 1",
+	},
+	{
+		name: "reordered",
+		tamper: func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		},
+		expected: "Record 3 follows record 1",
+	},
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	for _, c := range tamperCases {
+		l, path := newTestLog(t, 0)
+		defer os.RemoveAll(filepath.Dir(path))
+		appendRecords(t, l, 1, 2, 3)
+		l.Close()
+		contents, _ := ioutil.ReadFile(path)
+		lines := c.tamper(strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n"))
+		ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0640)
+
+		_, err := VerifyFiles([]string{path})
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: Expected error containing %q but got %v", c.name, c.expected, err)
+		}
+	}
+}
+
+var filterCases = []struct {
+	filter   Filter
+	expected bool
+}{
+	{filter: Filter{}, expected: true},
+	{filter: Filter{Contractorid: 72494, Caller: "batchimport"}, expected: true},
+	{filter: Filter{Contractorid: 1}, expected: false},
+	{filter: Filter{ContentSHA256: "ABCD"}, expected: true},
+	{filter: Filter{Outcome: OutcomeServiceBusError}, expected: false},
+	{filter: Filter{Since: time.Date(2017, 3, 8, 0, 0, 0, 0, time.UTC)}, expected: true},
+	{filter: Filter{Until: time.Date(2017, 3, 8, 0, 0, 0, 0, time.UTC)}, expected: false},
+}
+
+func TestFilter(t *testing.T) {
+	record := &Record{
+		Time:          time.Date(2017, 3, 8, 19, 6, 40, 0, time.UTC),
+		Caller:        "batchimport",
+		Contractorid:  72494,
+		ContentSHA256: "abcd",
+		Outcome:       OutcomeOK,
+	}
+	for _, c := range filterCases {
+		if matches := c.filter.Matches(record); matches != c.expected {
+			t.Errorf("Expected %v for %+v but got %v", c.expected, c.filter, matches)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Command auditlog verifies and queries the audit log written by the content
+// server with -audit_file.
+//
+//	auditlog verify -file /var/log/contentservice/audit.log
+//	auditlog query -file /var/log/contentservice/audit.log -contractorid 72494 -since 2017-03-01
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+)
+
+const usage = `Usage: auditlog verify|query [flags]
+
+verify checks the hash chain of the log and its rotated files
+query prints the records matching the flags as JSON lines
+`
+
+// parseTime accepts an RFC 3339 time or a date
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func verify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	file := fs.String("file", "", "The audit file written by the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files, err := audit.Files(*file)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("No audit files found at %s", *file)
+	}
+	v, err := audit.VerifyFiles(files)
+	if err != nil {
+		return fmt.Errorf("Verification failed after %d records: %s", v.Count, err)
+	}
+	if v.Count == 0 {
+		fmt.Fprintf(stdout, "Verified 0 records in %d files\n", len(files))
+		return nil
+	}
+	fmt.Fprintf(stdout, "Verified %d records %d to %d in %d files\nLast hash %s\n", v.Count, v.First.Seq, v.Last.Seq, len(files), v.Last.Hash)
+	return nil
+}
+
+func query(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	file := fs.String("file", "", "The audit file written by the server")
+	filter := &audit.Filter{}
+	fs.StringVar(&filter.Caller, "caller", "", "Only records of puts by this caller")
+	fs.Int64Var(&filter.Contractorid, "contractorid", 0, "Only records of puts for this contractor")
+	fs.Int64Var(&filter.Ordernumber, "ordernumber", 0, "Only records of puts for this order")
+	fs.StringVar(&filter.Filename, "filename", "", "Only records of puts of this filename")
+	fs.StringVar(&filter.ContentSHA256, "sha256", "", "Only records of puts of contents with this SHA-256")
+	fs.StringVar(&filter.GUID, "guid", "", "Only records of puts stored with this guid")
+	fs.StringVar(&filter.Outcome, "outcome", "", "Only records with this outcome, such as ok, servicebus_error or PermissionDenied")
+	since := fs.String("since", "", "Only records at or after this RFC 3339 time or date")
+	until := fs.String("until", "", "Only records before this RFC 3339 time or date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var err error
+	if filter.Since, err = parseTime(*since); err != nil {
+		return fmt.Errorf("Invalid since: %s", err)
+	}
+	if filter.Until, err = parseTime(*until); err != nil {
+		return fmt.Errorf("Invalid until: %s", err)
+	}
+	files, err := audit.Files(*file)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(stdout)
+	for _, filename := range files {
+		err := audit.ReadFile(filename, func(r *audit.Record) error {
+			if !filter.Matches(r) {
+				return nil
+			}
+			return encoder.Encode(r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes the subcommand named by args[0]
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+	switch args[0] {
+	case "verify":
+		return verify(args[1:], stdout)
+	case "query":
+		return query(args[1:], stdout)
+	}
+	return fmt.Errorf("Unknown command %q\n%s", args[0], usage)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
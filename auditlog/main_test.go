@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+)
+
+func writeTestLog(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "auditlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "audit.log")
+	l, err := audit.Open(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i, contractorID := range []int64{72494, 1, 72494} {
+		record := &audit.Record{
+			Time:         time.Date(2017, 3, 8+i, 0, 0, 0, 0, time.UTC),
+			Action:       audit.ActionPut,
+			Contractorid: contractorID,
+			Outcome:      audit.OutcomeOK,
+		}
+		if err := l.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestVerify(t *testing.T) {
+	path := writeTestLog(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	var out bytes.Buffer
+	if err := run([]string{"verify", "-file", path}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Verified 3 records 1 to 3 in 1 files") {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+
+	contents, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(path, bytes.Replace(contents, []byte(`"contractorid":1,`), []byte(`"contractorid":2,`), 1), 0640)
+	if err := run([]string{"verify", "-file", path}, &out); err == nil || !strings.Contains(err.Error(), "Record 2 has been altered") {
+		t.Errorf("Expected record 2 to fail verification but got %v", err)
+	}
+}
+
+var queryCases = []struct {
+	args     []string
+	expected []int64
+}{
+	{args: nil, expected: []int64{1, 2, 3}},
+	{args: []string{"-contractorid", "72494"}, expected: []int64{1, 3}},
+	{args: []string{"-contractorid", "72494", "-since", "2017-03-09"}, expected: []int64{3}},
+	{args: []string{"-until", "2017-03-09T00:00:00Z"}, expected: []int64{1}},
+}
+
+func TestQuery(t *testing.T) {
+	path := writeTestLog(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	for _, c := range queryCases {
+		var out bytes.Buffer
+		if err := run(append([]string{"query", "-file", path}, c.args...), &out); err != nil {
+			t.Fatal(err)
+		}
+		var seqs []int64
+		audit.Read(&out, func(r *audit.Record) error {
+			seqs = append(seqs, r.Seq)
+			return nil
+		})
+		if len(seqs) != len(c.expected) {
+			t.Errorf("Expected records %v for %v but got %v", c.expected, c.args, seqs)
+			continue
+		}
+		for i := range seqs {
+			if seqs[i] != c.expected[i] {
+				t.Errorf("Expected records %v for %v but got %v", c.expected, c.args, seqs)
+				break
+			}
+		}
+	}
+}
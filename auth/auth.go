@@ -0,0 +1,140 @@
+// Package auth identifies callers of the content service from the bearer
+// token they present and makes that identity available to handlers.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnknownToken is returned by an Authenticator that does not recognise a token
+var ErrUnknownToken = errors.New("Unknown token")
+
+// Identity describes an authenticated caller
+type Identity struct {
+	// Subject uniquely names the caller, e.g. a service account or user
+	Subject string
+	// Roles granted to the caller, e.g. "admin"
+	Roles []string
+	// Method is how the caller was authenticated, e.g. "token", "jwt" or "mtls"
+	Method string
+}
+
+// HasRole reports whether the identity was granted role
+func (i *Identity) HasRole(role string) bool {
+	if i == nil {
+		return false
+	}
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies a bearer token and returns the caller it belongs to
+type Authenticator interface {
+	Authenticate(token string) (*Identity, error)
+}
+
+// Chain tries each Authenticator in turn until one recognises the token
+type Chain []Authenticator
+
+// Authenticate returns the identity from the first authenticator which
+// accepts token. An authenticator rejecting a token it recognises, e.g. an
+// expired JWT, stops the chain.
+func (c Chain) Authenticate(token string) (*Identity, error) {
+	for _, authenticator := range c {
+		identity, err := authenticator.Authenticate(token)
+		if err == ErrUnknownToken {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrUnknownToken
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx carrying identity
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity of the caller in ctx, if any
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// tokenFromContext returns the bearer token in the incoming authorization metadata
+func tokenFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", errors.New("missing metadata")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", errors.New("missing authorization header")
+	}
+	parts := strings.SplitN(values[0], " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") || parts[1] == "" {
+		return "", errors.New("authorization header is not a bearer token")
+	}
+	return parts[1], nil
+}
+
+// Authenticate identifies the caller of ctx and returns a context carrying
+// the caller's Identity. A bearer token is verified using authenticator,
+// which may be nil if only client certificates are accepted. Without a
+// token the verified client certificate of the connection is used.
+func Authenticate(ctx context.Context, authenticator Authenticator) (context.Context, error) {
+	token, err := tokenFromContext(ctx)
+	if err != nil || authenticator == nil {
+		if identity, ok := certificateIdentity(ctx); ok {
+			return NewContext(ctx, identity), nil
+		}
+		if err == nil {
+			err = errors.New("bearer tokens are not accepted")
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated: %s", err)
+	}
+	identity, err := authenticator.Authenticate(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated: %s", err)
+	}
+	return NewContext(ctx, identity), nil
+}
+
+// UnaryServerInterceptor rejects calls without a valid bearer token or
+// client certificate with codes.Unauthenticated and passes the caller's
+// Identity to the handler
+func UnaryServerInterceptor(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := Authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequireRole returns an interceptor, to be chained after
+// UnaryServerInterceptor, rejecting callers not granted role with
+// codes.PermissionDenied
+func RequireRole(role string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity, _ := FromContext(ctx)
+		if !identity.HasRole(role) {
+			return nil, status.Errorf(codes.PermissionDenied, "Permission denied: the %s role is required", role)
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func signJWT(header, claims map[string]interface{}, secret []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestVerifier(t *testing.T) *JWTVerifier {
+	verifier, err := NewJWTVerifier(&JSONWebKeySet{Keys: []JSONWebKey{
+		{Kty: "oct", Kid: "k1", Alg: "HS256", K: base64.RawURLEncoding.EncodeToString(testSecret)},
+	}})
+	if err != nil {
+		t.Fatalf("Error creating verifier: %v", err)
+	}
+	verifier.Issuer = "vendorportal"
+	verifier.Audience = "contentservice"
+	verifier.now = func() time.Time { return time.Unix(1489000000, 0) }
+	return verifier
+}
+
+var jwtCases = []struct {
+	header           map[string]interface{}
+	claims           map[string]interface{}
+	secret           []byte
+	expectedIdentity *Identity
+	expectedErr      bool
+}{
+	{
+		header:           map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:           map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1489003600, "roles": []string{"uploader"}},
+		secret:           testSecret,
+		expectedIdentity: &Identity{Subject: "portal", Roles: []string{"uploader"}, Method: "jwt"},
+	},
+	{
+		header:           map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:           map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": []string{"other", "contentservice"}, "exp": 1489003600},
+		secret:           testSecret,
+		expectedIdentity: &Identity{Subject: "portal", Method: "jwt"},
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1489003600},
+		secret:      []byte("wrong secret"),
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1488990000},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "someone", "aud": "contentservice", "exp": 1489003600},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "other", "exp": 1489003600},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "none", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1489003600},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k2"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1489003600},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+	{
+		header:      map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		claims:      map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice"},
+		secret:      testSecret,
+		expectedErr: true,
+	},
+}
+
+func TestJWTVerifier(t *testing.T) {
+	verifier := newTestVerifier(t)
+	for i, c := range jwtCases {
+		identity, err := verifier.Authenticate(signJWT(c.header, c.claims, c.secret))
+		if (err != nil) != c.expectedErr {
+			t.Errorf("Case %d: expected error %v but got %v", i, c.expectedErr, err)
+		}
+		if !reflect.DeepEqual(identity, c.expectedIdentity) {
+			t.Errorf("Case %d: expected %+v but got %+v", i, c.expectedIdentity, identity)
+		}
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	tokens, err := NewStaticTokens([]StaticToken{{Token: "s3cret", Subject: "batchimport", Roles: []string{"admin"}}})
+	if err != nil {
+		t.Fatalf("Error creating tokens: %v", err)
+	}
+	identity, err := tokens.Authenticate("s3cret")
+	if err != nil || identity.Subject != "batchimport" || !identity.HasRole("admin") {
+		t.Errorf("Expected batchimport admin but got %+v, %v", identity, err)
+	}
+	if _, err := tokens.Authenticate("guess"); err != ErrUnknownToken {
+		t.Errorf("Expected ErrUnknownToken but got %v", err)
+	}
+	if _, err := NewStaticTokens([]StaticToken{{Token: "s3cret"}}); err == nil {
+		t.Errorf("Expected error for token without subject")
+	}
+}
+
+func TestChain(t *testing.T) {
+	tokens, _ := NewStaticTokens([]StaticToken{{Token: "s3cret", Subject: "batchimport"}})
+	chain := Chain{tokens, newTestVerifier(t)}
+
+	if identity, err := chain.Authenticate("s3cret"); err != nil || identity.Method != "token" {
+		t.Errorf("Expected static token identity but got %+v, %v", identity, err)
+	}
+	jwt := signJWT(map[string]interface{}{"alg": "HS256", "kid": "k1"},
+		map[string]interface{}{"sub": "portal", "iss": "vendorportal", "aud": "contentservice", "exp": 1489003600}, testSecret)
+	if identity, err := chain.Authenticate(jwt); err != nil || identity.Method != "jwt" {
+		t.Errorf("Expected jwt identity but got %+v, %v", identity, err)
+	}
+	if _, err := chain.Authenticate("guess"); err != ErrUnknownToken {
+		t.Errorf("Expected ErrUnknownToken but got %v", err)
+	}
+}
+
+var interceptorCases = []struct {
+	md           metadata.MD
+	expectedCode codes.Code
+	expectedSub  string
+}{
+	{md: metadata.Pairs("authorization", "Bearer s3cret"), expectedCode: codes.OK, expectedSub: "batchimport"},
+	{md: metadata.Pairs("authorization", "bearer s3cret"), expectedCode: codes.OK, expectedSub: "batchimport"},
+	{md: metadata.Pairs("authorization", "Bearer guess"), expectedCode: codes.Unauthenticated},
+	{md: metadata.Pairs("authorization", "Basic s3cret"), expectedCode: codes.Unauthenticated},
+	{md: metadata.MD{}, expectedCode: codes.Unauthenticated},
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tokens, _ := NewStaticTokens([]StaticToken{{Token: "s3cret", Subject: "batchimport"}})
+	interceptor := UnaryServerInterceptor(tokens)
+	for _, c := range interceptorCases {
+		var subject string
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			identity, _ := FromContext(ctx)
+			subject = identity.Subject
+			return nil, nil
+		}
+		ctx := metadata.NewIncomingContext(context.Background(), c.md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/contentservice.ContentService/Put"}, handler)
+		if status.Code(err) != c.expectedCode {
+			t.Errorf("Expected code %v but got %v", c.expectedCode, err)
+		}
+		if subject != c.expectedSub {
+			t.Errorf("Expected subject %q but got %q", c.expectedSub, subject)
+		}
+	}
+}
+
+var requireRoleCases = []struct {
+	identity     *Identity
+	expectedCode codes.Code
+}{
+	{identity: &Identity{Subject: "oncall", Roles: []string{"admin"}}, expectedCode: codes.OK},
+	{identity: &Identity{Subject: "batchimport"}, expectedCode: codes.PermissionDenied},
+	{identity: nil, expectedCode: codes.PermissionDenied},
+}
+
+func TestRequireRole(t *testing.T) {
+	interceptor := RequireRole("admin")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	for _, c := range requireRoleCases {
+		ctx := NewContext(context.Background(), c.identity)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/contentservice.admin.ContentServiceAdmin/GetConfig"}, handler)
+		if status.Code(err) != c.expectedCode {
+			t.Errorf("Expected code %v but got %v", c.expectedCode, err)
+		}
+	}
+}
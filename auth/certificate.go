@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// IdentityFromCertificate maps a client certificate to a caller identity.
+// The subject is taken from the first URI, DNS or email SAN, falling back to
+// the subject common name, and the subject's organizational units become
+// the caller's roles.
+func IdentityFromCertificate(cert *x509.Certificate) *Identity {
+	identity := &Identity{Method: "mtls", Roles: cert.Subject.OrganizationalUnit}
+	switch {
+	case len(cert.URIs) > 0:
+		identity.Subject = cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		identity.Subject = cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		identity.Subject = cert.EmailAddresses[0]
+	default:
+		identity.Subject = cert.Subject.CommonName
+	}
+	return identity
+}
+
+// certificateIdentity returns the identity of the verified client
+// certificate presented on the connection of ctx, if any
+func certificateIdentity(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	identity := IdentityFromCertificate(tlsInfo.State.VerifiedChains[0][0])
+	if identity.Subject == "" {
+		return nil, false
+	}
+	return identity, true
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var certificateCases = []struct {
+	cert             *x509.Certificate
+	expectedIdentity *Identity
+}{
+	{
+		cert: &x509.Certificate{
+			Subject: pkix.Name{CommonName: "portal", OrganizationalUnit: []string{"uploader"}},
+			URIs:    []*url.URL{{Scheme: "spiffe", Host: "qa01.local", Path: "/vendorportal"}},
+		},
+		expectedIdentity: &Identity{Subject: "spiffe://qa01.local/vendorportal", Roles: []string{"uploader"}, Method: "mtls"},
+	},
+	{
+		cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "portal"}, DNSNames: []string{"portal.qa01.local"}},
+		expectedIdentity: &Identity{Subject: "portal.qa01.local", Method: "mtls"},
+	},
+	{
+		cert:             &x509.Certificate{Subject: pkix.Name{CommonName: "portal"}},
+		expectedIdentity: &Identity{Subject: "portal", Method: "mtls"},
+	},
+}
+
+func TestIdentityFromCertificate(t *testing.T) {
+	for _, c := range certificateCases {
+		identity := IdentityFromCertificate(c.cert)
+		if !reflect.DeepEqual(identity, c.expectedIdentity) {
+			t.Errorf("Expected %+v but got %+v", c.expectedIdentity, identity)
+		}
+	}
+}
+
+func TestAuthenticateWithCertificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "portal"}}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+
+	ctx, err := Authenticate(ctx, nil)
+	if err != nil {
+		t.Fatalf("Expected certificate to authenticate but got %v", err)
+	}
+	if identity, _ := FromContext(ctx); identity.Subject != "portal" || identity.Method != "mtls" {
+		t.Errorf("Unexpected identity %+v", identity)
+	}
+
+	unverified := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}})
+	if _, err := Authenticate(unverified, nil); err == nil {
+		t.Errorf("Expected unverified certificate to be rejected")
+	}
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+)
+
+// BearerToken is a grpc.PerRPCCredentials sending a static bearer token
+type BearerToken struct {
+	Token string
+	// Secure requires the token to only be sent over TLS
+	Secure bool
+}
+
+// GetRequestMetadata returns the authorization header for each call
+func (b BearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.Token}, nil
+}
+
+// RequireTransportSecurity reports whether the token requires TLS
+func (b BearerToken) RequireTransportSecurity() bool {
+	return b.Secure
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// JSONWebKey is a symmetric ("oct") key from a JSON Web Key Set
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+}
+
+// JSONWebKeySet is the contents of a JWKS file
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type hmacKey struct {
+	alg    string
+	hash   func() hash.Hash
+	secret []byte
+}
+
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// JWTVerifier authenticates callers presenting HMAC signed JSON Web Tokens
+type JWTVerifier struct {
+	// Issuer, if set, must match the token's iss claim
+	Issuer string
+	// Audience, if set, must be one of the token's aud claims
+	Audience string
+	// Leeway allowed when checking exp and nbf
+	Leeway time.Duration
+
+	keys map[string]*hmacKey
+	now  func() time.Time
+}
+
+// NewJWTVerifier creates a verifier accepting tokens signed by any key in keySet
+func NewJWTVerifier(keySet *JSONWebKeySet) (*JWTVerifier, error) {
+	v := &JWTVerifier{keys: make(map[string]*hmacKey), now: time.Now, Leeway: time.Minute}
+	for i, key := range keySet.Keys {
+		if key.Kty != "oct" {
+			return nil, fmt.Errorf("Key %d: unsupported key type %q", i, key.Kty)
+		}
+		alg := key.Alg
+		if alg == "" {
+			alg = "HS256"
+		}
+		hashFunc, ok := hmacAlgorithms[alg]
+		if !ok {
+			return nil, fmt.Errorf("Key %d: unsupported algorithm %q", i, alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.K, "="))
+		if err != nil {
+			return nil, fmt.Errorf("Key %d: invalid key material: %s", i, err)
+		}
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("Key %d: empty key material", i)
+		}
+		if _, ok := v.keys[key.Kid]; ok {
+			return nil, fmt.Errorf("Key %d: duplicate kid %q", i, key.Kid)
+		}
+		v.keys[key.Kid] = &hmacKey{alg: alg, hash: hashFunc, secret: secret}
+	}
+	if len(v.keys) == 0 {
+		return nil, errors.New("Key set contains no keys")
+	}
+	return v, nil
+}
+
+// LoadJWTVerifier reads a JSON Web Key Set from filename
+func LoadJWTVerifier(filename string) (*JWTVerifier, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	keySet := &JSONWebKeySet{}
+	if err := json.Unmarshal(contents, keySet); err != nil {
+		return nil, fmt.Errorf("Error parsing key set %s: %s", filename, err)
+	}
+	return NewJWTVerifier(keySet)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  interface{} `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+	Roles     []string    `json:"roles"`
+}
+
+func (c *jwtClaims) hasAudience(audience string) bool {
+	switch aud := c.Audience.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, v)
+}
+
+// Authenticate verifies the signature and claims of token. Tokens which are
+// not JWTs are reported as ErrUnknownToken.
+func (v *JWTVerifier) Authenticate(token string) (*Identity, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return nil, ErrUnknownToken
+	}
+	header := &jwtHeader{}
+	if err := decodeSegment(segments[0], header); err != nil {
+		return nil, ErrUnknownToken
+	}
+
+	key, ok := v.keys[header.Kid]
+	if !ok {
+		return nil, fmt.Errorf("Unknown signing key %q", header.Kid)
+	}
+	if header.Alg != key.alg {
+		return nil, fmt.Errorf("Unexpected signing algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid signature encoding: %s", err)
+	}
+	mac := hmac.New(key.hash, key.secret)
+	mac.Write([]byte(segments[0] + "." + segments[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("Invalid token signature")
+	}
+
+	claims := &jwtClaims{}
+	if err := decodeSegment(segments[1], claims); err != nil {
+		return nil, fmt.Errorf("Invalid token claims: %s", err)
+	}
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("Token has no expiry")
+	}
+	if now.Add(-v.Leeway).After(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, errors.New("Token has expired")
+	}
+	if claims.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("Token is not valid yet")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return nil, fmt.Errorf("Unexpected token issuer %q", claims.Issuer)
+	}
+	if v.Audience != "" && !claims.hasAudience(v.Audience) {
+		return nil, errors.New("Token audience does not match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("Token has no subject")
+	}
+
+	return &Identity{Subject: claims.Subject, Roles: claims.Roles, Method: "jwt"}, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// StaticToken is an entry of a static token file
+type StaticToken struct {
+	Token   string   `json:"token"`
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles"`
+}
+
+// StaticTokens authenticates callers against a fixed set of tokens
+type StaticTokens struct {
+	identities map[[sha256.Size]byte]*Identity
+}
+
+// NewStaticTokens creates an authenticator accepting tokens
+func NewStaticTokens(tokens []StaticToken) (*StaticTokens, error) {
+	s := &StaticTokens{identities: make(map[[sha256.Size]byte]*Identity)}
+	for i, t := range tokens {
+		if t.Token == "" || t.Subject == "" {
+			return nil, fmt.Errorf("Token %d: token and subject are required", i)
+		}
+		s.identities[sha256.Sum256([]byte(t.Token))] = &Identity{Subject: t.Subject, Roles: t.Roles, Method: "token"}
+	}
+	return s, nil
+}
+
+// LoadStaticTokens reads a JSON array of StaticToken entries from filename
+func LoadStaticTokens(filename string) (*StaticTokens, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []StaticToken
+	if err := json.Unmarshal(contents, &tokens); err != nil {
+		return nil, fmt.Errorf("Error parsing token file %s: %s", filename, err)
+	}
+	return NewStaticTokens(tokens)
+}
+
+// Authenticate returns the identity token was issued to. Tokens are looked
+// up by their hash so that lookups do not leak timing about token contents.
+func (s *StaticTokens) Authenticate(token string) (*Identity, error) {
+	identity, ok := s.identities[sha256.Sum256([]byte(token))]
+	if !ok {
+		return nil, ErrUnknownToken
+	}
+	return identity, nil
+}
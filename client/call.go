@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// Registers the ContentService descriptors used to build calls
+	_ "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+// contentServiceName is the full name of the service invoked by call
+const contentServiceName = "contentservice.ContentService"
+
+// newCallRequest resolves method, given as a bare method name such as "Put"
+// or as "/contentservice.ContentService/Put", and builds its request from
+// JSON input. It returns the full method name with an empty response message.
+func newCallRequest(method string, input []byte) (string, *dynamicpb.Message, *dynamicpb.Message, error) {
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(contentServiceName)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("Error finding %s: %s", contentServiceName, err)
+	}
+	service := descriptor.(protoreflect.ServiceDescriptor)
+	name := method[strings.LastIndex(method, "/")+1:]
+	methodDescriptor := service.Methods().ByName(protoreflect.Name(name))
+	if methodDescriptor == nil {
+		var names []string
+		for i := 0; i < service.Methods().Len(); i++ {
+			names = append(names, string(service.Methods().Get(i).Name()))
+		}
+		return "", nil, nil, fmt.Errorf("Unknown method %q, expected one of %s", method, strings.Join(names, ", "))
+	}
+
+	request := dynamicpb.NewMessage(methodDescriptor.Input())
+	if err := protojson.Unmarshal(input, request); err != nil {
+		return "", nil, nil, fmt.Errorf("Error parsing %s: %s", methodDescriptor.Input().FullName(), err)
+	}
+	response := dynamicpb.NewMessage(methodDescriptor.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), methodDescriptor.Name())
+
+	return fullMethod, request, response, nil
+}
+
+// runCall invokes method with the JSON request in input, or read from stdin
+// if input is empty or "-", and prints the JSON response
+func runCall(conn *grpc.ClientConn, method, input string) error {
+	inputBytes := []byte(input)
+	if input == "" || input == "-" {
+		var err error
+		inputBytes, err = ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("Error reading request from stdin: %s", err)
+		}
+	}
+	fullMethod, request, response, err := newCallRequest(method, inputBytes)
+	if err != nil {
+		return err
+	}
+	if err := conn.Invoke(context.Background(), fullMethod, request, response); err != nil {
+		return err
+	}
+	output, err := protojson.MarshalOptions{Multiline: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+
+	return nil
+}
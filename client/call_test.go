@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+var callRequestCases = []struct {
+	method             string
+	input              string
+	expectedFullMethod string
+	expectedErr        bool
+}{
+	{method: "Put", input: `{"contractorid": "72494", "filename": "test.png"}`, expectedFullMethod: "/contentservice.ContentService/Put"},
+	{method: "/contentservice.ContentService/Put", input: `{}`, expectedFullMethod: "/contentservice.ContentService/Put"},
+	{method: "Delete", input: `{}`, expectedErr: true},
+	{method: "Put", input: `{"contractor": 1}`, expectedErr: true},
+}
+
+func TestNewCallRequest(t *testing.T) {
+	for _, c := range callRequestCases {
+		fullMethod, request, _, err := newCallRequest(c.method, []byte(c.input))
+		if (err != nil) != c.expectedErr {
+			t.Errorf("%s %s: expected error %v but got %v", c.method, c.input, c.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if fullMethod != c.expectedFullMethod {
+			t.Errorf("Expected %s but got %s", c.expectedFullMethod, fullMethod)
+		}
+		if request.Descriptor().FullName() != "contentservice.PutRequest" {
+			t.Errorf("Expected PutRequest but got %s", request.Descriptor().FullName())
+		}
+	}
+}
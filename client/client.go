@@ -3,8 +3,11 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"time"
@@ -13,6 +16,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/grpclog"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+	"github.com/divyag9/gothinnercontentservice/tracing"
 )
 
 type input struct {
@@ -30,6 +36,8 @@ func main() {
 	tls := flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
 	caFile := flag.String("ca_file", "testdata/ca.pem", "The file containning the CA root cert file")
 	serverAddr := flag.String("server_addr", "127.0.0.1:10000", "The server address in the format of host:port")
+	certFile := flag.String("cert_file", "", "The client certificate presented to the server for mutual TLS")
+	keyFile := flag.String("key_file", "", "The key of the client certificate")
 	serverHostOverride := flag.String("server_host_override", "", "The server name use to verify the hostname returned by TLS handshake")
 	contractorID := flag.Int64("contractorid", 72494, "Contractor Id for the PUT call")
 	orderNumber := flag.Int64("ordernumber", 600016555, "OrderNumber for the PUT call")
@@ -39,33 +47,50 @@ func main() {
 	imageHeight := flag.Int("imageheight", 100, "Imageheight for the PUT call")
 	releaseDate := flag.String("releasedate", "2015-08-06", "Releasedate for the PUT call")
 	deptCode := flag.String("deptcode", "01", "Department code for the PUT call")
+	token := flag.String("token", "", "Bearer token or JWT sent to authenticate with the server")
+	traceFile := flag.String("trace_file", "", "File to write trace spans to as JSON lines, - for stdout, empty to disable tracing")
 
 	flag.Parse()
 	var opts []grpc.DialOption
 	if *tls {
-		var sn string
-		if *serverHostOverride != "" {
-			sn = *serverHostOverride
-		}
-		var creds credentials.TransportCredentials
-		if *caFile != "" {
-			var err error
-			creds, err = credentials.NewClientTLSFromFile(*caFile, sn)
-			if err != nil {
-				grpclog.Fatalf("Failed to create TLS credentials %v", err)
-			}
-		} else {
-			creds = credentials.NewClientTLSFromCert(nil, sn)
+		tlsConfig, err := newClientTLSConfig(*caFile, *serverHostOverride, *certFile, *keyFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to create TLS credentials %v", err)
 		}
-		opts = append(opts, grpc.WithTransportCredentials(creds))
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		opts = append(opts, grpc.WithInsecure())
 	}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.BearerToken{Token: *token, Secure: *tls}))
+	}
+	if *traceFile != "" {
+		exporter, err := tracing.NewFileExporter(*traceFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to open trace file %v", err)
+		}
+		tracer := tracing.NewTracer(exporter)
+		opts = append(opts, grpc.WithUnaryInterceptor(tracer.UnaryClientInterceptor()))
+	}
 	conn, err := grpc.Dial(*serverAddr, opts...)
 	if err != nil {
 		grpclog.Fatalf("Failed to dial: %v", err)
 	}
 	defer conn.Close()
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
+		case "call":
+			if flag.NArg() < 2 {
+				log.Fatalf("Usage: client [flags] call METHOD [JSON|-]")
+			}
+			if err := runCall(conn, flag.Arg(1), flag.Arg(2)); err != nil {
+				log.Fatalf("Error calling %s: %v", flag.Arg(1), err)
+			}
+		default:
+			log.Fatalf("Unknown command %q", flag.Arg(0))
+		}
+		return
+	}
 	client := pb.NewContentServiceClient(conn)
 
 	// Create PutRequest
@@ -104,6 +129,31 @@ func main() {
 	fmt.Println("Average elapsed: ", average)
 }
 
+// newClientTLSConfig creates the client TLS configuration, trusting caFile
+// if set and presenting the certificate in certFile and keyFile if set
+func newClientTLSConfig(caFile, serverName, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName}
+	if caFile != "" {
+		pemBytes, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pemBytes) {
+			return nil, fmt.Errorf("No certificates found in %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
 func createPutRequest(in *input) (*pb.PutRequest, error) {
 	putRequest := &pb.PutRequest{}
 	putRequest.Contractorid = in.contracttorid
@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: admin/admin.proto
+
+package contentserviceadmin
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ContentServiceAdmin_GetConfig_FullMethodName            = "/contentservice.admin.ContentServiceAdmin/GetConfig"
+	ContentServiceAdmin_ReloadConfig_FullMethodName         = "/contentservice.admin.ContentServiceAdmin/ReloadConfig"
+	ContentServiceAdmin_SetLogLevel_FullMethodName          = "/contentservice.admin.ContentServiceAdmin/SetLogLevel"
+	ContentServiceAdmin_SetCircuit_FullMethodName           = "/contentservice.admin.ContentServiceAdmin/SetCircuit"
+	ContentServiceAdmin_ListInFlightRequests_FullMethodName = "/contentservice.admin.ContentServiceAdmin/ListInFlightRequests"
+	ContentServiceAdmin_ListRecentErrors_FullMethodName     = "/contentservice.admin.ContentServiceAdmin/ListRecentErrors"
+)
+
+// ContentServiceAdminClient is the client API for ContentServiceAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Operational control of a running content server. It is served on its own
+// listener and only to callers granted the admin role.
+type ContentServiceAdminClient interface {
+	// Returns the effective settings, with secrets redacted
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// Reads the settings again and applies those that may change at runtime
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error)
+	// Changes the least severe level logged until the next config reload
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	// Opens the ServiceBus circuit, failing puts without calling ServiceBus,
+	// or closes it again
+	SetCircuit(ctx context.Context, in *SetCircuitRequest, opts ...grpc.CallOption) (*Circuit, error)
+	// Lists the calls being served and how long they have taken so far
+	ListInFlightRequests(ctx context.Context, in *ListInFlightRequestsRequest, opts ...grpc.CallOption) (*ListInFlightRequestsResponse, error)
+	// Lists the most recent calls which failed, newest first
+	ListRecentErrors(ctx context.Context, in *ListRecentErrorsRequest, opts ...grpc.CallOption) (*ListRecentErrorsResponse, error)
+}
+
+type contentServiceAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewContentServiceAdminClient(cc grpc.ClientConnInterface) ContentServiceAdminClient {
+	return &contentServiceAdminClient{cc}
+}
+
+func (c *contentServiceAdminClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceAdminClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_ReloadConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceAdminClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceAdminClient) SetCircuit(ctx context.Context, in *SetCircuitRequest, opts ...grpc.CallOption) (*Circuit, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Circuit)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_SetCircuit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceAdminClient) ListInFlightRequests(ctx context.Context, in *ListInFlightRequestsRequest, opts ...grpc.CallOption) (*ListInFlightRequestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInFlightRequestsResponse)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_ListInFlightRequests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *contentServiceAdminClient) ListRecentErrors(ctx context.Context, in *ListRecentErrorsRequest, opts ...grpc.CallOption) (*ListRecentErrorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecentErrorsResponse)
+	err := c.cc.Invoke(ctx, ContentServiceAdmin_ListRecentErrors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContentServiceAdminServer is the server API for ContentServiceAdmin service.
+// All implementations must embed UnimplementedContentServiceAdminServer
+// for forward compatibility.
+//
+// Operational control of a running content server. It is served on its own
+// listener and only to callers granted the admin role.
+type ContentServiceAdminServer interface {
+	// Returns the effective settings, with secrets redacted
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// Reads the settings again and applies those that may change at runtime
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error)
+	// Changes the least severe level logged until the next config reload
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	// Opens the ServiceBus circuit, failing puts without calling ServiceBus,
+	// or closes it again
+	SetCircuit(context.Context, *SetCircuitRequest) (*Circuit, error)
+	// Lists the calls being served and how long they have taken so far
+	ListInFlightRequests(context.Context, *ListInFlightRequestsRequest) (*ListInFlightRequestsResponse, error)
+	// Lists the most recent calls which failed, newest first
+	ListRecentErrors(context.Context, *ListRecentErrorsRequest) (*ListRecentErrorsResponse, error)
+	mustEmbedUnimplementedContentServiceAdminServer()
+}
+
+// UnimplementedContentServiceAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedContentServiceAdminServer struct{}
+
+func (UnimplementedContentServiceAdminServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedContentServiceAdminServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedContentServiceAdminServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedContentServiceAdminServer) SetCircuit(context.Context, *SetCircuitRequest) (*Circuit, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetCircuit not implemented")
+}
+func (UnimplementedContentServiceAdminServer) ListInFlightRequests(context.Context, *ListInFlightRequestsRequest) (*ListInFlightRequestsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListInFlightRequests not implemented")
+}
+func (UnimplementedContentServiceAdminServer) ListRecentErrors(context.Context, *ListRecentErrorsRequest) (*ListRecentErrorsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRecentErrors not implemented")
+}
+func (UnimplementedContentServiceAdminServer) mustEmbedUnimplementedContentServiceAdminServer() {}
+func (UnimplementedContentServiceAdminServer) testEmbeddedByValue()                             {}
+
+// UnsafeContentServiceAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ContentServiceAdminServer will
+// result in compilation errors.
+type UnsafeContentServiceAdminServer interface {
+	mustEmbedUnimplementedContentServiceAdminServer()
+}
+
+func RegisterContentServiceAdminServer(s grpc.ServiceRegistrar, srv ContentServiceAdminServer) {
+	// If the following call panics, it indicates UnimplementedContentServiceAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ContentServiceAdmin_ServiceDesc, srv)
+}
+
+func _ContentServiceAdmin_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentServiceAdmin_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentServiceAdmin_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentServiceAdmin_SetCircuit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCircuitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).SetCircuit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_SetCircuit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).SetCircuit(ctx, req.(*SetCircuitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentServiceAdmin_ListInFlightRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInFlightRequestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).ListInFlightRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_ListInFlightRequests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).ListInFlightRequests(ctx, req.(*ListInFlightRequestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ContentServiceAdmin_ListRecentErrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecentErrorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ContentServiceAdminServer).ListRecentErrors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ContentServiceAdmin_ListRecentErrors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ContentServiceAdminServer).ListRecentErrors(ctx, req.(*ListRecentErrorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ContentServiceAdmin_ServiceDesc is the grpc.ServiceDesc for ContentServiceAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ContentServiceAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "contentservice.admin.ContentServiceAdmin",
+	HandlerType: (*ContentServiceAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _ContentServiceAdmin_GetConfig_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _ContentServiceAdmin_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _ContentServiceAdmin_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "SetCircuit",
+			Handler:    _ContentServiceAdmin_SetCircuit_Handler,
+		},
+		{
+			MethodName: "ListInFlightRequests",
+			Handler:    _ContentServiceAdmin_ListInFlightRequests_Handler,
+		},
+		{
+			MethodName: "ListRecentErrors",
+			Handler:    _ContentServiceAdmin_ListRecentErrors_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin/admin.proto",
+}
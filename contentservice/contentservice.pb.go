@@ -1,660 +1,1461 @@
-// Code generated by protoc-gen-go.
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
 // source: contentservice.proto
-// DO NOT EDIT!
-
-/*
-Package contentservice is a generated protocol buffer package.
-
-It is generated from these files:
-	contentservice.proto
-
-It has these top-level messages:
-	PutRequest
-	JSONRPCRequest
-	PutResponse
-	InspiPutResponse
-	VendorWebPutResponse
-	JSONRPCResult
-	JSONRPCError
-	JSONRPCResponse
-*/
-package contentservice
 
-import proto "github.com/golang/protobuf/proto"
-import fmt "fmt"
-import math "math"
+package contentservice
 
 import (
-	context "golang.org/x/net/context"
-	grpc "google.golang.org/grpc"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ = proto.Marshal
-var _ = fmt.Errorf
-var _ = math.Inf
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
 
 // Request sent to the server
 type PutRequest struct {
-	Contractorid int64  `protobuf:"varint,1,opt,name=contractorid" json:"contractorid,omitempty"`
-	Ordernumber  int64  `protobuf:"varint,2,opt,name=ordernumber" json:"ordernumber,omitempty"`
-	Imagetype    int32  `protobuf:"varint,3,opt,name=imagetype" json:"imagetype,omitempty"`
-	Filename     string `protobuf:"bytes,4,opt,name=filename" json:"filename,omitempty"`
-	Imagewidth   int32  `protobuf:"varint,5,opt,name=imagewidth" json:"imagewidth,omitempty"`
-	Imageheight  int32  `protobuf:"varint,6,opt,name=imageheight" json:"imageheight,omitempty"`
-	Releasedate  string `protobuf:"bytes,7,opt,name=releasedate" json:"releasedate,omitempty"`
-	Deptcode     string `protobuf:"bytes,8,opt,name=deptcode" json:"deptcode,omitempty"`
-	Filecontents []byte `protobuf:"bytes,9,opt,name=filecontents,proto3" json:"filecontents,omitempty"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contractorid  int64                  `protobuf:"varint,1,opt,name=contractorid,proto3" json:"contractorid,omitempty"`
+	Ordernumber   int64                  `protobuf:"varint,2,opt,name=ordernumber,proto3" json:"ordernumber,omitempty"`
+	Imagetype     int32                  `protobuf:"varint,3,opt,name=imagetype,proto3" json:"imagetype,omitempty"`
+	Filename      string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	Imagewidth    int32                  `protobuf:"varint,5,opt,name=imagewidth,proto3" json:"imagewidth,omitempty"`
+	Imageheight   int32                  `protobuf:"varint,6,opt,name=imageheight,proto3" json:"imageheight,omitempty"`
+	Releasedate   string                 `protobuf:"bytes,7,opt,name=releasedate,proto3" json:"releasedate,omitempty"`
+	Deptcode      string                 `protobuf:"bytes,8,opt,name=deptcode,proto3" json:"deptcode,omitempty"`
+	Filecontents  []byte                 `protobuf:"bytes,9,opt,name=filecontents,proto3" json:"filecontents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutRequest) Reset() {
+	*x = PutRequest{}
+	mi := &file_contentservice_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutRequest) ProtoMessage() {}
+
+func (x *PutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutRequest.ProtoReflect.Descriptor instead.
+func (*PutRequest) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PutRequest) GetContractorid() int64 {
+	if x != nil {
+		return x.Contractorid
+	}
+	return 0
+}
+
+func (x *PutRequest) GetOrdernumber() int64 {
+	if x != nil {
+		return x.Ordernumber
+	}
+	return 0
+}
+
+func (x *PutRequest) GetImagetype() int32 {
+	if x != nil {
+		return x.Imagetype
+	}
+	return 0
+}
+
+func (x *PutRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *PutRequest) GetImagewidth() int32 {
+	if x != nil {
+		return x.Imagewidth
+	}
+	return 0
+}
+
+func (x *PutRequest) GetImageheight() int32 {
+	if x != nil {
+		return x.Imageheight
+	}
+	return 0
+}
+
+func (x *PutRequest) GetReleasedate() string {
+	if x != nil {
+		return x.Releasedate
+	}
+	return ""
+}
+
+func (x *PutRequest) GetDeptcode() string {
+	if x != nil {
+		return x.Deptcode
+	}
+	return ""
+}
+
+func (x *PutRequest) GetFilecontents() []byte {
+	if x != nil {
+		return x.Filecontents
+	}
+	return nil
+}
+
+// Request sent to the server to rotate stored content
+type RotateRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Contractorid int64                  `protobuf:"varint,1,opt,name=contractorid,proto3" json:"contractorid,omitempty"`
+	Ordernumber  int64                  `protobuf:"varint,2,opt,name=ordernumber,proto3" json:"ordernumber,omitempty"`
+	// The id of the content returned by its Put
+	Id       int32  `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	Deptcode string `protobuf:"bytes,4,opt,name=deptcode,proto3" json:"deptcode,omitempty"`
+	// Degrees clockwise to rotate by: 90, 180 or 270
+	Degrees       int32 `protobuf:"varint,5,opt,name=degrees,proto3" json:"degrees,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateRequest) Reset() {
+	*x = RotateRequest{}
+	mi := &file_contentservice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateRequest) ProtoMessage() {}
+
+func (x *RotateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateRequest.ProtoReflect.Descriptor instead.
+func (*RotateRequest) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RotateRequest) GetContractorid() int64 {
+	if x != nil {
+		return x.Contractorid
+	}
+	return 0
+}
+
+func (x *RotateRequest) GetOrdernumber() int64 {
+	if x != nil {
+		return x.Ordernumber
+	}
+	return 0
+}
+
+func (x *RotateRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RotateRequest) GetDeptcode() string {
+	if x != nil {
+		return x.Deptcode
+	}
+	return ""
+}
+
+func (x *RotateRequest) GetDegrees() int32 {
+	if x != nil {
+		return x.Degrees
+	}
+	return 0
+}
+
+// Params of the servicebus calls made for a Put or Rotate: the fields of the
+// PutRequest and those the server adds to them
+type PutParams struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Contractorid int64                  `protobuf:"varint,1,opt,name=contractorid,proto3" json:"contractorid,omitempty"`
+	Ordernumber  int64                  `protobuf:"varint,2,opt,name=ordernumber,proto3" json:"ordernumber,omitempty"`
+	Imagetype    int32                  `protobuf:"varint,3,opt,name=imagetype,proto3" json:"imagetype,omitempty"`
+	Filename     string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	Imagewidth   int32                  `protobuf:"varint,5,opt,name=imagewidth,proto3" json:"imagewidth,omitempty"`
+	Imageheight  int32                  `protobuf:"varint,6,opt,name=imageheight,proto3" json:"imageheight,omitempty"`
+	Releasedate  string                 `protobuf:"bytes,7,opt,name=releasedate,proto3" json:"releasedate,omitempty"`
+	Deptcode     string                 `protobuf:"bytes,8,opt,name=deptcode,proto3" json:"deptcode,omitempty"`
+	Filecontents []byte                 `protobuf:"bytes,9,opt,name=filecontents,proto3" json:"filecontents,omitempty"`
+	// Resized copies of the image, such as thumbnails
+	Derivatives []*Derivative `protobuf:"bytes,10,rep,name=derivatives,proto3" json:"derivatives,omitempty"`
+	// Degrees clockwise the server rotated the image by, to turn it upright
+	// or for a Rotate call
+	Imagerotated int32 `protobuf:"varint,11,opt,name=imagerotated,proto3" json:"imagerotated,omitempty"`
+	// The stored content a Rotate call gets or replaces
+	Id int32 `protobuf:"varint,12,opt,name=id,proto3" json:"id,omitempty"`
+	// Data for the destination system of a v2 Put, at most one of which is set
+	Inspirequestdata     *InspiPutRequest     `protobuf:"bytes,13,opt,name=inspirequestdata,proto3" json:"inspirequestdata,omitempty"`
+	Vendorwebrequestdata *VendorWebPutRequest `protobuf:"bytes,14,opt,name=vendorwebrequestdata,proto3" json:"vendorwebrequestdata,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *PutParams) Reset() {
+	*x = PutParams{}
+	mi := &file_contentservice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutParams) ProtoMessage() {}
+
+func (x *PutParams) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (m *PutRequest) Reset()                    { *m = PutRequest{} }
-func (m *PutRequest) String() string            { return proto.CompactTextString(m) }
-func (*PutRequest) ProtoMessage()               {}
-func (*PutRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+// Deprecated: Use PutParams.ProtoReflect.Descriptor instead.
+func (*PutParams) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{2}
+}
 
-func (m *PutRequest) GetContractorid() int64 {
-	if m != nil {
-		return m.Contractorid
+func (x *PutParams) GetContractorid() int64 {
+	if x != nil {
+		return x.Contractorid
 	}
 	return 0
 }
 
-func (m *PutRequest) GetOrdernumber() int64 {
-	if m != nil {
-		return m.Ordernumber
+func (x *PutParams) GetOrdernumber() int64 {
+	if x != nil {
+		return x.Ordernumber
 	}
 	return 0
 }
 
-func (m *PutRequest) GetImagetype() int32 {
-	if m != nil {
-		return m.Imagetype
+func (x *PutParams) GetImagetype() int32 {
+	if x != nil {
+		return x.Imagetype
 	}
 	return 0
 }
 
-func (m *PutRequest) GetFilename() string {
-	if m != nil {
-		return m.Filename
+func (x *PutParams) GetFilename() string {
+	if x != nil {
+		return x.Filename
 	}
 	return ""
 }
 
-func (m *PutRequest) GetImagewidth() int32 {
-	if m != nil {
-		return m.Imagewidth
+func (x *PutParams) GetImagewidth() int32 {
+	if x != nil {
+		return x.Imagewidth
 	}
 	return 0
 }
 
-func (m *PutRequest) GetImageheight() int32 {
-	if m != nil {
-		return m.Imageheight
+func (x *PutParams) GetImageheight() int32 {
+	if x != nil {
+		return x.Imageheight
 	}
 	return 0
 }
 
-func (m *PutRequest) GetReleasedate() string {
-	if m != nil {
-		return m.Releasedate
+func (x *PutParams) GetReleasedate() string {
+	if x != nil {
+		return x.Releasedate
 	}
 	return ""
 }
 
-func (m *PutRequest) GetDeptcode() string {
-	if m != nil {
-		return m.Deptcode
+func (x *PutParams) GetDeptcode() string {
+	if x != nil {
+		return x.Deptcode
 	}
 	return ""
 }
 
-func (m *PutRequest) GetFilecontents() []byte {
-	if m != nil {
-		return m.Filecontents
+func (x *PutParams) GetFilecontents() []byte {
+	if x != nil {
+		return x.Filecontents
+	}
+	return nil
+}
+
+func (x *PutParams) GetDerivatives() []*Derivative {
+	if x != nil {
+		return x.Derivatives
+	}
+	return nil
+}
+
+func (x *PutParams) GetImagerotated() int32 {
+	if x != nil {
+		return x.Imagerotated
+	}
+	return 0
+}
+
+func (x *PutParams) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PutParams) GetInspirequestdata() *InspiPutRequest {
+	if x != nil {
+		return x.Inspirequestdata
+	}
+	return nil
+}
+
+func (x *PutParams) GetVendorwebrequestdata() *VendorWebPutRequest {
+	if x != nil {
+		return x.Vendorwebrequestdata
+	}
+	return nil
+}
+
+// An image generated from the content of a PutRequest at a configured size
+type Derivative struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Width         int32                  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Mimetype      string                 `protobuf:"bytes,4,opt,name=mimetype,proto3" json:"mimetype,omitempty"`
+	Contents      []byte                 `protobuf:"bytes,5,opt,name=contents,proto3" json:"contents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Derivative) Reset() {
+	*x = Derivative{}
+	mi := &file_contentservice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Derivative) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Derivative) ProtoMessage() {}
+
+func (x *Derivative) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Derivative.ProtoReflect.Descriptor instead.
+func (*Derivative) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Derivative) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Derivative) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Derivative) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Derivative) GetMimetype() string {
+	if x != nil {
+		return x.Mimetype
+	}
+	return ""
+}
+
+func (x *Derivative) GetContents() []byte {
+	if x != nil {
+		return x.Contents
 	}
 	return nil
 }
 
 // Request sent to the servicebus Put call
 type JSONRPCRequest struct {
-	Jsonrpc        string      `protobuf:"bytes,1,opt,name=jsonrpc" json:"jsonrpc,omitempty"`
-	Method         string      `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
-	Params         *PutRequest `protobuf:"bytes,3,opt,name=params" json:"params,omitempty"`
-	Id             int32       `protobuf:"varint,4,opt,name=id" json:"id,omitempty"`
-	Asyncmessageid int32       `protobuf:"varint,5,opt,name=asyncmessageid" json:"asyncmessageid,omitempty"`
-	Traceid        int32       `protobuf:"varint,6,opt,name=traceid" json:"traceid,omitempty"`
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Jsonrpc        string                 `protobuf:"bytes,1,opt,name=jsonrpc,proto3" json:"jsonrpc,omitempty"`
+	Method         string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Params         *PutParams             `protobuf:"bytes,3,opt,name=params,proto3" json:"params,omitempty"`
+	Id             int32                  `protobuf:"varint,4,opt,name=id,proto3" json:"id,omitempty"`
+	Asyncmessageid int32                  `protobuf:"varint,5,opt,name=asyncmessageid,proto3" json:"asyncmessageid,omitempty"`
+	Traceid        int32                  `protobuf:"varint,6,opt,name=traceid,proto3" json:"traceid,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *JSONRPCRequest) Reset() {
+	*x = JSONRPCRequest{}
+	mi := &file_contentservice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONRPCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONRPCRequest) ProtoMessage() {}
+
+func (x *JSONRPCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (m *JSONRPCRequest) Reset()                    { *m = JSONRPCRequest{} }
-func (m *JSONRPCRequest) String() string            { return proto.CompactTextString(m) }
-func (*JSONRPCRequest) ProtoMessage()               {}
-func (*JSONRPCRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+// Deprecated: Use JSONRPCRequest.ProtoReflect.Descriptor instead.
+func (*JSONRPCRequest) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{4}
+}
 
-func (m *JSONRPCRequest) GetJsonrpc() string {
-	if m != nil {
-		return m.Jsonrpc
+func (x *JSONRPCRequest) GetJsonrpc() string {
+	if x != nil {
+		return x.Jsonrpc
 	}
 	return ""
 }
 
-func (m *JSONRPCRequest) GetMethod() string {
-	if m != nil {
-		return m.Method
+func (x *JSONRPCRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
 	}
 	return ""
 }
 
-func (m *JSONRPCRequest) GetParams() *PutRequest {
-	if m != nil {
-		return m.Params
+func (x *JSONRPCRequest) GetParams() *PutParams {
+	if x != nil {
+		return x.Params
 	}
 	return nil
 }
 
-func (m *JSONRPCRequest) GetId() int32 {
-	if m != nil {
-		return m.Id
+func (x *JSONRPCRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
 	}
 	return 0
 }
 
-func (m *JSONRPCRequest) GetAsyncmessageid() int32 {
-	if m != nil {
-		return m.Asyncmessageid
+func (x *JSONRPCRequest) GetAsyncmessageid() int32 {
+	if x != nil {
+		return x.Asyncmessageid
 	}
 	return 0
 }
 
-func (m *JSONRPCRequest) GetTraceid() int32 {
-	if m != nil {
-		return m.Traceid
+func (x *JSONRPCRequest) GetTraceid() int32 {
+	if x != nil {
+		return x.Traceid
 	}
 	return 0
 }
 
 // Response from the Server
 type PutResponse struct {
-	Result *JSONRPCResult `protobuf:"bytes,1,opt,name=result" json:"result,omitempty"`
-	Error  *JSONRPCError  `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Result *JSONRPCResult         `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Error  *JSONRPCError          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// When and where the photo was taken, read from its metadata if the
+	// metadata policy of its department extracts them
+	Capturemetadata *CaptureMetadata `protobuf:"bytes,3,opt,name=capturemetadata,proto3" json:"capturemetadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PutResponse) Reset() {
+	*x = PutResponse{}
+	mi := &file_contentservice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (m *PutResponse) Reset()                    { *m = PutResponse{} }
-func (m *PutResponse) String() string            { return proto.CompactTextString(m) }
-func (*PutResponse) ProtoMessage()               {}
-func (*PutResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*PutResponse) ProtoMessage() {}
+
+func (x *PutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResponse.ProtoReflect.Descriptor instead.
+func (*PutResponse) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PutResponse) GetResult() *JSONRPCResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
 
-func (m *PutResponse) GetResult() *JSONRPCResult {
-	if m != nil {
-		return m.Result
+func (x *PutResponse) GetError() *JSONRPCError {
+	if x != nil {
+		return x.Error
 	}
 	return nil
 }
 
-func (m *PutResponse) GetError() *JSONRPCError {
-	if m != nil {
-		return m.Error
+func (x *PutResponse) GetCapturemetadata() *CaptureMetadata {
+	if x != nil {
+		return x.Capturemetadata
 	}
 	return nil
 }
 
+type CaptureMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// RFC 3339 if the camera recorded its offset from UTC, and otherwise
+	// the camera's local time as yyyy-mm-ddThh:mm:ss
+	Capturetime   string       `protobuf:"bytes,1,opt,name=capturetime,proto3" json:"capturetime,omitempty"`
+	Gps           *GPSPosition `protobuf:"bytes,2,opt,name=gps,proto3" json:"gps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureMetadata) Reset() {
+	*x = CaptureMetadata{}
+	mi := &file_contentservice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureMetadata) ProtoMessage() {}
+
+func (x *CaptureMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureMetadata.ProtoReflect.Descriptor instead.
+func (*CaptureMetadata) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CaptureMetadata) GetCapturetime() string {
+	if x != nil {
+		return x.Capturetime
+	}
+	return ""
+}
+
+func (x *CaptureMetadata) GetGps() *GPSPosition {
+	if x != nil {
+		return x.Gps
+	}
+	return nil
+}
+
+type GPSPosition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Degrees, negative south and west
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Meters, negative below sea level
+	Altitude      float64 `protobuf:"fixed64,3,opt,name=altitude,proto3" json:"altitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPSPosition) Reset() {
+	*x = GPSPosition{}
+	mi := &file_contentservice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPSPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPSPosition) ProtoMessage() {}
+
+func (x *GPSPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPSPosition.ProtoReflect.Descriptor instead.
+func (*GPSPosition) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GPSPosition) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GPSPosition) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GPSPosition) GetAltitude() float64 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+type InspiPutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Photodetailid int64                  `protobuf:"varint,1,opt,name=photodetailid,proto3" json:"photodetailid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspiPutRequest) Reset() {
+	*x = InspiPutRequest{}
+	mi := &file_contentservice_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspiPutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspiPutRequest) ProtoMessage() {}
+
+func (x *InspiPutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspiPutRequest.ProtoReflect.Descriptor instead.
+func (*InspiPutRequest) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *InspiPutRequest) GetPhotodetailid() int64 {
+	if x != nil {
+		return x.Photodetailid
+	}
+	return 0
+}
+
+type VendorWebPutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documentid    int64                  `protobuf:"varint,1,opt,name=documentid,proto3" json:"documentid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorWebPutRequest) Reset() {
+	*x = VendorWebPutRequest{}
+	mi := &file_contentservice_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorWebPutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorWebPutRequest) ProtoMessage() {}
+
+func (x *VendorWebPutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorWebPutRequest.ProtoReflect.Descriptor instead.
+func (*VendorWebPutRequest) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *VendorWebPutRequest) GetDocumentid() int64 {
+	if x != nil {
+		return x.Documentid
+	}
+	return 0
+}
+
 type InspiPutResponse struct {
-	Photodetailid int64 `protobuf:"varint,1,opt,name=photodetailid" json:"photodetailid,omitempty"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Photodetailid int64                  `protobuf:"varint,1,opt,name=photodetailid,proto3" json:"photodetailid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspiPutResponse) Reset() {
+	*x = InspiPutResponse{}
+	mi := &file_contentservice_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspiPutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspiPutResponse) ProtoMessage() {}
+
+func (x *InspiPutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (m *InspiPutResponse) Reset()                    { *m = InspiPutResponse{} }
-func (m *InspiPutResponse) String() string            { return proto.CompactTextString(m) }
-func (*InspiPutResponse) ProtoMessage()               {}
-func (*InspiPutResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+// Deprecated: Use InspiPutResponse.ProtoReflect.Descriptor instead.
+func (*InspiPutResponse) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{10}
+}
 
-func (m *InspiPutResponse) GetPhotodetailid() int64 {
-	if m != nil {
-		return m.Photodetailid
+func (x *InspiPutResponse) GetPhotodetailid() int64 {
+	if x != nil {
+		return x.Photodetailid
 	}
 	return 0
 }
 
 type VendorWebPutResponse struct {
-	Documentid   int64 `protobuf:"varint,1,opt,name=documentid" json:"documentid,omitempty"`
-	Annotationid int64 `protobuf:"varint,2,opt,name=annotationid" json:"annotationid,omitempty"`
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documentid    int64                  `protobuf:"varint,1,opt,name=documentid,proto3" json:"documentid,omitempty"`
+	Annotationid  int64                  `protobuf:"varint,2,opt,name=annotationid,proto3" json:"annotationid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorWebPutResponse) Reset() {
+	*x = VendorWebPutResponse{}
+	mi := &file_contentservice_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorWebPutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorWebPutResponse) ProtoMessage() {}
+
+func (x *VendorWebPutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (m *VendorWebPutResponse) Reset()                    { *m = VendorWebPutResponse{} }
-func (m *VendorWebPutResponse) String() string            { return proto.CompactTextString(m) }
-func (*VendorWebPutResponse) ProtoMessage()               {}
-func (*VendorWebPutResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+// Deprecated: Use VendorWebPutResponse.ProtoReflect.Descriptor instead.
+func (*VendorWebPutResponse) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{11}
+}
 
-func (m *VendorWebPutResponse) GetDocumentid() int64 {
-	if m != nil {
-		return m.Documentid
+func (x *VendorWebPutResponse) GetDocumentid() int64 {
+	if x != nil {
+		return x.Documentid
 	}
 	return 0
 }
 
-func (m *VendorWebPutResponse) GetAnnotationid() int64 {
-	if m != nil {
-		return m.Annotationid
+func (x *VendorWebPutResponse) GetAnnotationid() int64 {
+	if x != nil {
+		return x.Annotationid
 	}
 	return 0
 }
 
 // Message when Put request succeeded
 type JSONRPCResult struct {
-	Contractorid          int32                 `protobuf:"varint,1,opt,name=contractorid" json:"contractorid,omitempty"`
-	Releasedate           string                `protobuf:"bytes,2,opt,name=releasedate" json:"releasedate,omitempty"`
-	Scandate              string                `protobuf:"bytes,3,opt,name=scandate" json:"scandate,omitempty"`
-	Imagetype             int32                 `protobuf:"varint,4,opt,name=imagetype" json:"imagetype,omitempty"`
-	Imagewidth            int32                 `protobuf:"varint,5,opt,name=imagewidth" json:"imagewidth,omitempty"`
-	Imageheight           int32                 `protobuf:"varint,6,opt,name=imageheight" json:"imageheight,omitempty"`
-	Deptcode              string                `protobuf:"bytes,7,opt,name=deptcode" json:"deptcode,omitempty"`
-	Descprefix            string                `protobuf:"bytes,8,opt,name=descprefix" json:"descprefix,omitempty"`
-	Desctext              string                `protobuf:"bytes,9,opt,name=desctext" json:"desctext,omitempty"`
-	Category              string                `protobuf:"bytes,10,opt,name=category" json:"category,omitempty"`
-	Ordernumber           int64                 `protobuf:"varint,11,opt,name=ordernumber" json:"ordernumber,omitempty"`
-	Archived              string                `protobuf:"bytes,12,opt,name=archived" json:"archived,omitempty"`
-	Datecreated           string                `protobuf:"bytes,13,opt,name=datecreated" json:"datecreated,omitempty"`
-	Datemodefied          string                `protobuf:"bytes,14,opt,name=datemodefied" json:"datemodefied,omitempty"`
-	Filesize              int32                 `protobuf:"varint,15,opt,name=filesize" json:"filesize,omitempty"`
-	Id                    int32                 `protobuf:"varint,16,opt,name=id" json:"id,omitempty"`
-	Imagefilename         string                `protobuf:"bytes,17,opt,name=imagefilename" json:"imagefilename,omitempty"`
-	Imagerotated          int32                 `protobuf:"varint,18,opt,name=imagerotated" json:"imagerotated,omitempty"`
-	Thumbnailsize         int32                 `protobuf:"varint,19,opt,name=thumbnailsize" json:"thumbnailsize,omitempty"`
-	Webfilename           string                `protobuf:"bytes,20,opt,name=webfilename" json:"webfilename,omitempty"`
-	Mimetype              string                `protobuf:"bytes,21,opt,name=mimetype" json:"mimetype,omitempty"`
-	Inspiresponsedata     *InspiPutResponse     `protobuf:"bytes,22,opt,name=inspiresponsedata" json:"inspiresponsedata,omitempty"`
-	Vendorwebresponsedata *VendorWebPutResponse `protobuf:"bytes,23,opt,name=vendorwebresponsedata" json:"vendorwebresponsedata,omitempty"`
-	Guid                  string                `protobuf:"bytes,24,opt,name=guid" json:"guid,omitempty"`
-}
-
-func (m *JSONRPCResult) Reset()                    { *m = JSONRPCResult{} }
-func (m *JSONRPCResult) String() string            { return proto.CompactTextString(m) }
-func (*JSONRPCResult) ProtoMessage()               {}
-func (*JSONRPCResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
-
-func (m *JSONRPCResult) GetContractorid() int32 {
-	if m != nil {
-		return m.Contractorid
-	}
-	return 0
-}
-
-func (m *JSONRPCResult) GetReleasedate() string {
-	if m != nil {
-		return m.Releasedate
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Contractorid          int32                  `protobuf:"varint,1,opt,name=contractorid,proto3" json:"contractorid,omitempty"`
+	Releasedate           string                 `protobuf:"bytes,2,opt,name=releasedate,proto3" json:"releasedate,omitempty"`
+	Scandate              string                 `protobuf:"bytes,3,opt,name=scandate,proto3" json:"scandate,omitempty"`
+	Imagetype             int32                  `protobuf:"varint,4,opt,name=imagetype,proto3" json:"imagetype,omitempty"`
+	Imagewidth            int32                  `protobuf:"varint,5,opt,name=imagewidth,proto3" json:"imagewidth,omitempty"`
+	Imageheight           int32                  `protobuf:"varint,6,opt,name=imageheight,proto3" json:"imageheight,omitempty"`
+	Deptcode              string                 `protobuf:"bytes,7,opt,name=deptcode,proto3" json:"deptcode,omitempty"`
+	Descprefix            string                 `protobuf:"bytes,8,opt,name=descprefix,proto3" json:"descprefix,omitempty"`
+	Desctext              string                 `protobuf:"bytes,9,opt,name=desctext,proto3" json:"desctext,omitempty"`
+	Category              string                 `protobuf:"bytes,10,opt,name=category,proto3" json:"category,omitempty"`
+	Ordernumber           int64                  `protobuf:"varint,11,opt,name=ordernumber,proto3" json:"ordernumber,omitempty"`
+	Archived              string                 `protobuf:"bytes,12,opt,name=archived,proto3" json:"archived,omitempty"`
+	Datecreated           string                 `protobuf:"bytes,13,opt,name=datecreated,proto3" json:"datecreated,omitempty"`
+	Datemodefied          string                 `protobuf:"bytes,14,opt,name=datemodefied,proto3" json:"datemodefied,omitempty"`
+	Filesize              int32                  `protobuf:"varint,15,opt,name=filesize,proto3" json:"filesize,omitempty"`
+	Id                    int32                  `protobuf:"varint,16,opt,name=id,proto3" json:"id,omitempty"`
+	Imagefilename         string                 `protobuf:"bytes,17,opt,name=imagefilename,proto3" json:"imagefilename,omitempty"`
+	Imagerotated          int32                  `protobuf:"varint,18,opt,name=imagerotated,proto3" json:"imagerotated,omitempty"`
+	Thumbnailsize         int32                  `protobuf:"varint,19,opt,name=thumbnailsize,proto3" json:"thumbnailsize,omitempty"`
+	Webfilename           string                 `protobuf:"bytes,20,opt,name=webfilename,proto3" json:"webfilename,omitempty"`
+	Mimetype              string                 `protobuf:"bytes,21,opt,name=mimetype,proto3" json:"mimetype,omitempty"`
+	Inspiresponsedata     *InspiPutResponse      `protobuf:"bytes,22,opt,name=inspiresponsedata,proto3" json:"inspiresponsedata,omitempty"`
+	Vendorwebresponsedata *VendorWebPutResponse  `protobuf:"bytes,23,opt,name=vendorwebresponsedata,proto3" json:"vendorwebresponsedata,omitempty"`
+	Guid                  string                 `protobuf:"bytes,24,opt,name=guid,proto3" json:"guid,omitempty"`
+	// The stored content, returned by ServiceBus to a get
+	Filecontents  []byte `protobuf:"bytes,25,opt,name=filecontents,proto3" json:"filecontents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONRPCResult) Reset() {
+	*x = JSONRPCResult{}
+	mi := &file_contentservice_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONRPCResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONRPCResult) ProtoMessage() {}
+
+func (x *JSONRPCResult) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONRPCResult.ProtoReflect.Descriptor instead.
+func (*JSONRPCResult) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *JSONRPCResult) GetContractorid() int32 {
+	if x != nil {
+		return x.Contractorid
+	}
+	return 0
+}
+
+func (x *JSONRPCResult) GetReleasedate() string {
+	if x != nil {
+		return x.Releasedate
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetScandate() string {
-	if m != nil {
-		return m.Scandate
+func (x *JSONRPCResult) GetScandate() string {
+	if x != nil {
+		return x.Scandate
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetImagetype() int32 {
-	if m != nil {
-		return m.Imagetype
+func (x *JSONRPCResult) GetImagetype() int32 {
+	if x != nil {
+		return x.Imagetype
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetImagewidth() int32 {
-	if m != nil {
-		return m.Imagewidth
+func (x *JSONRPCResult) GetImagewidth() int32 {
+	if x != nil {
+		return x.Imagewidth
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetImageheight() int32 {
-	if m != nil {
-		return m.Imageheight
+func (x *JSONRPCResult) GetImageheight() int32 {
+	if x != nil {
+		return x.Imageheight
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetDeptcode() string {
-	if m != nil {
-		return m.Deptcode
+func (x *JSONRPCResult) GetDeptcode() string {
+	if x != nil {
+		return x.Deptcode
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetDescprefix() string {
-	if m != nil {
-		return m.Descprefix
+func (x *JSONRPCResult) GetDescprefix() string {
+	if x != nil {
+		return x.Descprefix
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetDesctext() string {
-	if m != nil {
-		return m.Desctext
+func (x *JSONRPCResult) GetDesctext() string {
+	if x != nil {
+		return x.Desctext
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetCategory() string {
-	if m != nil {
-		return m.Category
+func (x *JSONRPCResult) GetCategory() string {
+	if x != nil {
+		return x.Category
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetOrdernumber() int64 {
-	if m != nil {
-		return m.Ordernumber
+func (x *JSONRPCResult) GetOrdernumber() int64 {
+	if x != nil {
+		return x.Ordernumber
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetArchived() string {
-	if m != nil {
-		return m.Archived
+func (x *JSONRPCResult) GetArchived() string {
+	if x != nil {
+		return x.Archived
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetDatecreated() string {
-	if m != nil {
-		return m.Datecreated
+func (x *JSONRPCResult) GetDatecreated() string {
+	if x != nil {
+		return x.Datecreated
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetDatemodefied() string {
-	if m != nil {
-		return m.Datemodefied
+func (x *JSONRPCResult) GetDatemodefied() string {
+	if x != nil {
+		return x.Datemodefied
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetFilesize() int32 {
-	if m != nil {
-		return m.Filesize
+func (x *JSONRPCResult) GetFilesize() int32 {
+	if x != nil {
+		return x.Filesize
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetId() int32 {
-	if m != nil {
-		return m.Id
+func (x *JSONRPCResult) GetId() int32 {
+	if x != nil {
+		return x.Id
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetImagefilename() string {
-	if m != nil {
-		return m.Imagefilename
+func (x *JSONRPCResult) GetImagefilename() string {
+	if x != nil {
+		return x.Imagefilename
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetImagerotated() int32 {
-	if m != nil {
-		return m.Imagerotated
+func (x *JSONRPCResult) GetImagerotated() int32 {
+	if x != nil {
+		return x.Imagerotated
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetThumbnailsize() int32 {
-	if m != nil {
-		return m.Thumbnailsize
+func (x *JSONRPCResult) GetThumbnailsize() int32 {
+	if x != nil {
+		return x.Thumbnailsize
 	}
 	return 0
 }
 
-func (m *JSONRPCResult) GetWebfilename() string {
-	if m != nil {
-		return m.Webfilename
+func (x *JSONRPCResult) GetWebfilename() string {
+	if x != nil {
+		return x.Webfilename
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetMimetype() string {
-	if m != nil {
-		return m.Mimetype
+func (x *JSONRPCResult) GetMimetype() string {
+	if x != nil {
+		return x.Mimetype
 	}
 	return ""
 }
 
-func (m *JSONRPCResult) GetInspiresponsedata() *InspiPutResponse {
-	if m != nil {
-		return m.Inspiresponsedata
+func (x *JSONRPCResult) GetInspiresponsedata() *InspiPutResponse {
+	if x != nil {
+		return x.Inspiresponsedata
 	}
 	return nil
 }
 
-func (m *JSONRPCResult) GetVendorwebresponsedata() *VendorWebPutResponse {
-	if m != nil {
-		return m.Vendorwebresponsedata
+func (x *JSONRPCResult) GetVendorwebresponsedata() *VendorWebPutResponse {
+	if x != nil {
+		return x.Vendorwebresponsedata
 	}
 	return nil
 }
 
-func (m *JSONRPCResult) GetGuid() string {
-	if m != nil {
-		return m.Guid
+func (x *JSONRPCResult) GetGuid() string {
+	if x != nil {
+		return x.Guid
 	}
 	return ""
 }
 
-// Message when Put request failed
-type JSONRPCError struct {
-	Code    int32  `protobuf:"varint,1,opt,name=code" json:"code,omitempty"`
-	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
-	Data    string `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
-}
-
-func (m *JSONRPCError) Reset()                    { *m = JSONRPCError{} }
-func (m *JSONRPCError) String() string            { return proto.CompactTextString(m) }
-func (*JSONRPCError) ProtoMessage()               {}
-func (*JSONRPCError) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
-
-func (m *JSONRPCError) GetCode() int32 {
-	if m != nil {
-		return m.Code
+func (x *JSONRPCResult) GetFilecontents() []byte {
+	if x != nil {
+		return x.Filecontents
 	}
-	return 0
+	return nil
 }
 
-func (m *JSONRPCError) GetMessage() string {
-	if m != nil {
-		return m.Message
-	}
-	return ""
+// Message when Put request failed
+type JSONRPCError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Data          string                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (m *JSONRPCError) GetData() string {
-	if m != nil {
-		return m.Data
-	}
-	return ""
+func (x *JSONRPCError) Reset() {
+	*x = JSONRPCError{}
+	mi := &file_contentservice_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-// Response from the servicebus Put call
-type JSONRPCResponse struct {
-	Jsonrpc string         `protobuf:"bytes,1,opt,name=jsonrpc" json:"jsonrpc,omitempty"`
-	Id      int32          `protobuf:"varint,2,opt,name=id" json:"id,omitempty"`
-	Result  *JSONRPCResult `protobuf:"bytes,3,opt,name=result" json:"result,omitempty"`
-	Error   *JSONRPCError  `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+func (x *JSONRPCError) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (m *JSONRPCResponse) Reset()                    { *m = JSONRPCResponse{} }
-func (m *JSONRPCResponse) String() string            { return proto.CompactTextString(m) }
-func (*JSONRPCResponse) ProtoMessage()               {}
-func (*JSONRPCResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*JSONRPCError) ProtoMessage() {}
 
-func (m *JSONRPCResponse) GetJsonrpc() string {
-	if m != nil {
-		return m.Jsonrpc
+func (x *JSONRPCError) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONRPCError.ProtoReflect.Descriptor instead.
+func (*JSONRPCError) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{13}
 }
 
-func (m *JSONRPCResponse) GetId() int32 {
-	if m != nil {
-		return m.Id
+func (x *JSONRPCError) GetCode() int32 {
+	if x != nil {
+		return x.Code
 	}
 	return 0
 }
 
-func (m *JSONRPCResponse) GetResult() *JSONRPCResult {
-	if m != nil {
-		return m.Result
+func (x *JSONRPCError) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-func (m *JSONRPCResponse) GetError() *JSONRPCError {
-	if m != nil {
-		return m.Error
+func (x *JSONRPCError) GetData() string {
+	if x != nil {
+		return x.Data
 	}
-	return nil
+	return ""
 }
 
-func init() {
-	proto.RegisterType((*PutRequest)(nil), "contentservice.PutRequest")
-	proto.RegisterType((*JSONRPCRequest)(nil), "contentservice.JSONRPCRequest")
-	proto.RegisterType((*PutResponse)(nil), "contentservice.PutResponse")
-	proto.RegisterType((*InspiPutResponse)(nil), "contentservice.InspiPutResponse")
-	proto.RegisterType((*VendorWebPutResponse)(nil), "contentservice.VendorWebPutResponse")
-	proto.RegisterType((*JSONRPCResult)(nil), "contentservice.JSONRPCResult")
-	proto.RegisterType((*JSONRPCError)(nil), "contentservice.JSONRPCError")
-	proto.RegisterType((*JSONRPCResponse)(nil), "contentservice.JSONRPCResponse")
+// Response from the servicebus Put call
+type JSONRPCResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jsonrpc       string                 `protobuf:"bytes,1,opt,name=jsonrpc,proto3" json:"jsonrpc,omitempty"`
+	Id            int32                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Result        *JSONRPCResult         `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error         *JSONRPCError          `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for ContentService service
-
-type ContentServiceClient interface {
-	// Makes a Put call
-	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+func (x *JSONRPCResponse) Reset() {
+	*x = JSONRPCResponse{}
+	mi := &file_contentservice_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type contentServiceClient struct {
-	cc *grpc.ClientConn
+func (x *JSONRPCResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func NewContentServiceClient(cc *grpc.ClientConn) ContentServiceClient {
-	return &contentServiceClient{cc}
-}
+func (*JSONRPCResponse) ProtoMessage() {}
 
-func (c *contentServiceClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
-	out := new(PutResponse)
-	err := grpc.Invoke(ctx, "/contentservice.ContentService/Put", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (x *JSONRPCResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_contentservice_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return out, nil
+	return mi.MessageOf(x)
 }
 
-// Server API for ContentService service
-
-type ContentServiceServer interface {
-	// Makes a Put call
-	Put(context.Context, *PutRequest) (*PutResponse, error)
+// Deprecated: Use JSONRPCResponse.ProtoReflect.Descriptor instead.
+func (*JSONRPCResponse) Descriptor() ([]byte, []int) {
+	return file_contentservice_proto_rawDescGZIP(), []int{14}
 }
 
-func RegisterContentServiceServer(s *grpc.Server, srv ContentServiceServer) {
-	s.RegisterService(&_ContentService_serviceDesc, srv)
+func (x *JSONRPCResponse) GetJsonrpc() string {
+	if x != nil {
+		return x.Jsonrpc
+	}
+	return ""
 }
 
-func _ContentService_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PutRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (x *JSONRPCResponse) GetId() int32 {
+	if x != nil {
+		return x.Id
 	}
-	if interceptor == nil {
-		return srv.(ContentServiceServer).Put(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/contentservice.ContentService/Put",
+	return 0
+}
+
+func (x *JSONRPCResponse) GetResult() *JSONRPCResult {
+	if x != nil {
+		return x.Result
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ContentServiceServer).Put(ctx, req.(*PutRequest))
+	return nil
+}
+
+func (x *JSONRPCResponse) GetError() *JSONRPCError {
+	if x != nil {
+		return x.Error
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _ContentService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "contentservice.ContentService",
-	HandlerType: (*ContentServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Put",
-			Handler:    _ContentService_Put_Handler,
+var File_contentservice_proto protoreflect.FileDescriptor
+
+const file_contentservice_proto_rawDesc = "" +
+	"\n" +
+	"\x14contentservice.proto\x12\x0econtentservice\"\xb6\x02\n" +
+	"\n" +
+	"PutRequest\x12\"\n" +
+	"\fcontractorid\x18\x01 \x01(\x03R\fcontractorid\x12 \n" +
+	"\vordernumber\x18\x02 \x01(\x03R\vordernumber\x12\x1c\n" +
+	"\timagetype\x18\x03 \x01(\x05R\timagetype\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x12\x1e\n" +
+	"\n" +
+	"imagewidth\x18\x05 \x01(\x05R\n" +
+	"imagewidth\x12 \n" +
+	"\vimageheight\x18\x06 \x01(\x05R\vimageheight\x12 \n" +
+	"\vreleasedate\x18\a \x01(\tR\vreleasedate\x12\x1a\n" +
+	"\bdeptcode\x18\b \x01(\tR\bdeptcode\x12\"\n" +
+	"\ffilecontents\x18\t \x01(\fR\ffilecontentsJ\x04\b\n" +
+	"\x10\r\"\x9b\x01\n" +
+	"\rRotateRequest\x12\"\n" +
+	"\fcontractorid\x18\x01 \x01(\x03R\fcontractorid\x12 \n" +
+	"\vordernumber\x18\x02 \x01(\x03R\vordernumber\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\x05R\x02id\x12\x1a\n" +
+	"\bdeptcode\x18\x04 \x01(\tR\bdeptcode\x12\x18\n" +
+	"\adegrees\x18\x05 \x01(\x05R\adegrees\"\xc7\x04\n" +
+	"\tPutParams\x12\"\n" +
+	"\fcontractorid\x18\x01 \x01(\x03R\fcontractorid\x12 \n" +
+	"\vordernumber\x18\x02 \x01(\x03R\vordernumber\x12\x1c\n" +
+	"\timagetype\x18\x03 \x01(\x05R\timagetype\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x12\x1e\n" +
+	"\n" +
+	"imagewidth\x18\x05 \x01(\x05R\n" +
+	"imagewidth\x12 \n" +
+	"\vimageheight\x18\x06 \x01(\x05R\vimageheight\x12 \n" +
+	"\vreleasedate\x18\a \x01(\tR\vreleasedate\x12\x1a\n" +
+	"\bdeptcode\x18\b \x01(\tR\bdeptcode\x12\"\n" +
+	"\ffilecontents\x18\t \x01(\fR\ffilecontents\x12<\n" +
+	"\vderivatives\x18\n" +
+	" \x03(\v2\x1a.contentservice.DerivativeR\vderivatives\x12\"\n" +
+	"\fimagerotated\x18\v \x01(\x05R\fimagerotated\x12\x0e\n" +
+	"\x02id\x18\f \x01(\x05R\x02id\x12K\n" +
+	"\x10inspirequestdata\x18\r \x01(\v2\x1f.contentservice.InspiPutRequestR\x10inspirequestdata\x12W\n" +
+	"\x14vendorwebrequestdata\x18\x0e \x01(\v2#.contentservice.VendorWebPutRequestR\x14vendorwebrequestdata\"\x86\x01\n" +
+	"\n" +
+	"Derivative\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12\x1a\n" +
+	"\bmimetype\x18\x04 \x01(\tR\bmimetype\x12\x1a\n" +
+	"\bcontents\x18\x05 \x01(\fR\bcontents\"\xc7\x01\n" +
+	"\x0eJSONRPCRequest\x12\x18\n" +
+	"\ajsonrpc\x18\x01 \x01(\tR\ajsonrpc\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x121\n" +
+	"\x06params\x18\x03 \x01(\v2\x19.contentservice.PutParamsR\x06params\x12\x0e\n" +
+	"\x02id\x18\x04 \x01(\x05R\x02id\x12&\n" +
+	"\x0easyncmessageid\x18\x05 \x01(\x05R\x0easyncmessageid\x12\x18\n" +
+	"\atraceid\x18\x06 \x01(\x05R\atraceid\"\xc3\x01\n" +
+	"\vPutResponse\x125\n" +
+	"\x06result\x18\x01 \x01(\v2\x1d.contentservice.JSONRPCResultR\x06result\x122\n" +
+	"\x05error\x18\x02 \x01(\v2\x1c.contentservice.JSONRPCErrorR\x05error\x12I\n" +
+	"\x0fcapturemetadata\x18\x03 \x01(\v2\x1f.contentservice.CaptureMetadataR\x0fcapturemetadata\"b\n" +
+	"\x0fCaptureMetadata\x12 \n" +
+	"\vcapturetime\x18\x01 \x01(\tR\vcapturetime\x12-\n" +
+	"\x03gps\x18\x02 \x01(\v2\x1b.contentservice.GPSPositionR\x03gps\"c\n" +
+	"\vGPSPosition\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\baltitude\x18\x03 \x01(\x01R\baltitude\"7\n" +
+	"\x0fInspiPutRequest\x12$\n" +
+	"\rphotodetailid\x18\x01 \x01(\x03R\rphotodetailid\"5\n" +
+	"\x13VendorWebPutRequest\x12\x1e\n" +
+	"\n" +
+	"documentid\x18\x01 \x01(\x03R\n" +
+	"documentid\"8\n" +
+	"\x10InspiPutResponse\x12$\n" +
+	"\rphotodetailid\x18\x01 \x01(\x03R\rphotodetailid\"Z\n" +
+	"\x14VendorWebPutResponse\x12\x1e\n" +
+	"\n" +
+	"documentid\x18\x01 \x01(\x03R\n" +
+	"documentid\x12\"\n" +
+	"\fannotationid\x18\x02 \x01(\x03R\fannotationid\"\x87\a\n" +
+	"\rJSONRPCResult\x12\"\n" +
+	"\fcontractorid\x18\x01 \x01(\x05R\fcontractorid\x12 \n" +
+	"\vreleasedate\x18\x02 \x01(\tR\vreleasedate\x12\x1a\n" +
+	"\bscandate\x18\x03 \x01(\tR\bscandate\x12\x1c\n" +
+	"\timagetype\x18\x04 \x01(\x05R\timagetype\x12\x1e\n" +
+	"\n" +
+	"imagewidth\x18\x05 \x01(\x05R\n" +
+	"imagewidth\x12 \n" +
+	"\vimageheight\x18\x06 \x01(\x05R\vimageheight\x12\x1a\n" +
+	"\bdeptcode\x18\a \x01(\tR\bdeptcode\x12\x1e\n" +
+	"\n" +
+	"descprefix\x18\b \x01(\tR\n" +
+	"descprefix\x12\x1a\n" +
+	"\bdesctext\x18\t \x01(\tR\bdesctext\x12\x1a\n" +
+	"\bcategory\x18\n" +
+	" \x01(\tR\bcategory\x12 \n" +
+	"\vordernumber\x18\v \x01(\x03R\vordernumber\x12\x1a\n" +
+	"\barchived\x18\f \x01(\tR\barchived\x12 \n" +
+	"\vdatecreated\x18\r \x01(\tR\vdatecreated\x12\"\n" +
+	"\fdatemodefied\x18\x0e \x01(\tR\fdatemodefied\x12\x1a\n" +
+	"\bfilesize\x18\x0f \x01(\x05R\bfilesize\x12\x0e\n" +
+	"\x02id\x18\x10 \x01(\x05R\x02id\x12$\n" +
+	"\rimagefilename\x18\x11 \x01(\tR\rimagefilename\x12\"\n" +
+	"\fimagerotated\x18\x12 \x01(\x05R\fimagerotated\x12$\n" +
+	"\rthumbnailsize\x18\x13 \x01(\x05R\rthumbnailsize\x12 \n" +
+	"\vwebfilename\x18\x14 \x01(\tR\vwebfilename\x12\x1a\n" +
+	"\bmimetype\x18\x15 \x01(\tR\bmimetype\x12N\n" +
+	"\x11inspiresponsedata\x18\x16 \x01(\v2 .contentservice.InspiPutResponseR\x11inspiresponsedata\x12Z\n" +
+	"\x15vendorwebresponsedata\x18\x17 \x01(\v2$.contentservice.VendorWebPutResponseR\x15vendorwebresponsedata\x12\x12\n" +
+	"\x04guid\x18\x18 \x01(\tR\x04guid\x12\"\n" +
+	"\ffilecontents\x18\x19 \x01(\fR\ffilecontents\"P\n" +
+	"\fJSONRPCError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\tR\x04data\"\xa6\x01\n" +
+	"\x0fJSONRPCResponse\x12\x18\n" +
+	"\ajsonrpc\x18\x01 \x01(\tR\ajsonrpc\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x05R\x02id\x125\n" +
+	"\x06result\x18\x03 \x01(\v2\x1d.contentservice.JSONRPCResultR\x06result\x122\n" +
+	"\x05error\x18\x04 \x01(\v2\x1c.contentservice.JSONRPCErrorR\x05error2\x9a\x01\n" +
+	"\x0eContentService\x12@\n" +
+	"\x03Put\x12\x1a.contentservice.PutRequest\x1a\x1b.contentservice.PutResponse\"\x00\x12F\n" +
+	"\x06Rotate\x12\x1d.contentservice.RotateRequest\x1a\x1b.contentservice.PutResponse\"\x00B;Z9github.com/divyag9/gothinnercontentservice/contentserviceb\x06proto3"
+
+var (
+	file_contentservice_proto_rawDescOnce sync.Once
+	file_contentservice_proto_rawDescData []byte
+)
+
+func file_contentservice_proto_rawDescGZIP() []byte {
+	file_contentservice_proto_rawDescOnce.Do(func() {
+		file_contentservice_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_contentservice_proto_rawDesc), len(file_contentservice_proto_rawDesc)))
+	})
+	return file_contentservice_proto_rawDescData
+}
+
+var file_contentservice_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_contentservice_proto_goTypes = []any{
+	(*PutRequest)(nil),           // 0: contentservice.PutRequest
+	(*RotateRequest)(nil),        // 1: contentservice.RotateRequest
+	(*PutParams)(nil),            // 2: contentservice.PutParams
+	(*Derivative)(nil),           // 3: contentservice.Derivative
+	(*JSONRPCRequest)(nil),       // 4: contentservice.JSONRPCRequest
+	(*PutResponse)(nil),          // 5: contentservice.PutResponse
+	(*CaptureMetadata)(nil),      // 6: contentservice.CaptureMetadata
+	(*GPSPosition)(nil),          // 7: contentservice.GPSPosition
+	(*InspiPutRequest)(nil),      // 8: contentservice.InspiPutRequest
+	(*VendorWebPutRequest)(nil),  // 9: contentservice.VendorWebPutRequest
+	(*InspiPutResponse)(nil),     // 10: contentservice.InspiPutResponse
+	(*VendorWebPutResponse)(nil), // 11: contentservice.VendorWebPutResponse
+	(*JSONRPCResult)(nil),        // 12: contentservice.JSONRPCResult
+	(*JSONRPCError)(nil),         // 13: contentservice.JSONRPCError
+	(*JSONRPCResponse)(nil),      // 14: contentservice.JSONRPCResponse
+}
+var file_contentservice_proto_depIdxs = []int32{
+	3,  // 0: contentservice.PutParams.derivatives:type_name -> contentservice.Derivative
+	8,  // 1: contentservice.PutParams.inspirequestdata:type_name -> contentservice.InspiPutRequest
+	9,  // 2: contentservice.PutParams.vendorwebrequestdata:type_name -> contentservice.VendorWebPutRequest
+	2,  // 3: contentservice.JSONRPCRequest.params:type_name -> contentservice.PutParams
+	12, // 4: contentservice.PutResponse.result:type_name -> contentservice.JSONRPCResult
+	13, // 5: contentservice.PutResponse.error:type_name -> contentservice.JSONRPCError
+	6,  // 6: contentservice.PutResponse.capturemetadata:type_name -> contentservice.CaptureMetadata
+	7,  // 7: contentservice.CaptureMetadata.gps:type_name -> contentservice.GPSPosition
+	10, // 8: contentservice.JSONRPCResult.inspiresponsedata:type_name -> contentservice.InspiPutResponse
+	11, // 9: contentservice.JSONRPCResult.vendorwebresponsedata:type_name -> contentservice.VendorWebPutResponse
+	12, // 10: contentservice.JSONRPCResponse.result:type_name -> contentservice.JSONRPCResult
+	13, // 11: contentservice.JSONRPCResponse.error:type_name -> contentservice.JSONRPCError
+	0,  // 12: contentservice.ContentService.Put:input_type -> contentservice.PutRequest
+	1,  // 13: contentservice.ContentService.Rotate:input_type -> contentservice.RotateRequest
+	5,  // 14: contentservice.ContentService.Put:output_type -> contentservice.PutResponse
+	5,  // 15: contentservice.ContentService.Rotate:output_type -> contentservice.PutResponse
+	14, // [14:16] is the sub-list for method output_type
+	12, // [12:14] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_contentservice_proto_init() }
+func file_contentservice_proto_init() {
+	if File_contentservice_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_contentservice_proto_rawDesc), len(file_contentservice_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
 		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "contentservice.proto",
-}
-
-func init() { proto.RegisterFile("contentservice.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 790 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x55, 0xcd, 0x8e, 0xe3, 0x44,
-	0x10, 0x26, 0xbf, 0x33, 0xa9, 0xfc, 0xec, 0x6e, 0x33, 0xbb, 0xb4, 0xc2, 0xb2, 0x8a, 0xac, 0x11,
-	0xca, 0x69, 0x0f, 0x41, 0x48, 0x1c, 0x91, 0x56, 0x1c, 0xe0, 0xb0, 0x44, 0x5e, 0x09, 0xa4, 0xbd,
-	0x75, 0xdc, 0x35, 0x49, 0xa3, 0xd8, 0x6d, 0xba, 0xdb, 0xf3, 0xc3, 0x03, 0x71, 0xe6, 0x31, 0x78,
-	0x0f, 0x5e, 0x04, 0x75, 0xb5, 0xed, 0xd8, 0x9e, 0x0c, 0x42, 0x70, 0x73, 0x7d, 0xfd, 0x55, 0x57,
-	0xd7, 0xcf, 0x57, 0x86, 0xab, 0x44, 0x67, 0x0e, 0x33, 0x67, 0xd1, 0xdc, 0xaa, 0x04, 0xdf, 0xe6,
-	0x46, 0x3b, 0xcd, 0x16, 0x6d, 0x34, 0xfa, 0xa3, 0x0f, 0xb0, 0x2d, 0x5c, 0x8c, 0xbf, 0x16, 0x68,
-	0x1d, 0x8b, 0x60, 0xe6, 0x09, 0x46, 0x24, 0x4e, 0x1b, 0x25, 0x79, 0x6f, 0xd5, 0x5b, 0x0f, 0xe2,
-	0x16, 0xc6, 0x56, 0x30, 0xd5, 0x46, 0xa2, 0xc9, 0x8a, 0x74, 0x87, 0x86, 0xf7, 0x89, 0xd2, 0x84,
-	0xd8, 0x6b, 0x98, 0xa8, 0x54, 0xec, 0xd1, 0x3d, 0xe4, 0xc8, 0x07, 0xab, 0xde, 0x7a, 0x14, 0x9f,
-	0x00, 0xb6, 0x84, 0xcb, 0x1b, 0x75, 0xc4, 0x4c, 0xa4, 0xc8, 0x87, 0xab, 0xde, 0x7a, 0x12, 0xd7,
-	0x36, 0x7b, 0x03, 0x40, 0xc4, 0x3b, 0x25, 0xdd, 0x81, 0x8f, 0xc8, 0xb5, 0x81, 0xf8, 0xd8, 0x64,
-	0x1d, 0x50, 0xed, 0x0f, 0x8e, 0x8f, 0x89, 0xd0, 0x84, 0x3c, 0xc3, 0xe0, 0x11, 0x85, 0x45, 0x29,
-	0x1c, 0xf2, 0x0b, 0x0a, 0xd0, 0x84, 0x7c, 0x7c, 0x89, 0xb9, 0x4b, 0xb4, 0x44, 0x7e, 0x19, 0xe2,
-	0x57, 0xb6, 0xcf, 0xdf, 0xbf, 0xa5, 0x2a, 0x12, 0x9f, 0xac, 0x7a, 0xeb, 0x59, 0xdc, 0xc2, 0xa2,
-	0x3f, 0x7b, 0xb0, 0xf8, 0xe1, 0xc3, 0x8f, 0xef, 0xe3, 0xed, 0xbb, 0xaa, 0x6c, 0x1c, 0x2e, 0x7e,
-	0xb1, 0x3a, 0x33, 0x79, 0x42, 0x15, 0x9b, 0xc4, 0x95, 0xc9, 0x5e, 0xc1, 0x38, 0x45, 0x77, 0xd0,
-	0x92, 0xea, 0x34, 0x89, 0x4b, 0x8b, 0x6d, 0x60, 0x9c, 0x0b, 0x23, 0x52, 0x4b, 0xf5, 0x99, 0x6e,
-	0x96, 0x6f, 0x3b, 0xed, 0x3a, 0x35, 0x25, 0x2e, 0x99, 0x6c, 0x01, 0x7d, 0x25, 0xa9, 0x64, 0xa3,
-	0xb8, 0xaf, 0x24, 0xfb, 0x12, 0x16, 0xc2, 0x3e, 0x64, 0x49, 0x8a, 0xd6, 0x8a, 0x3d, 0x2a, 0x59,
-	0x16, 0xac, 0x83, 0xfa, 0xd7, 0xf9, 0xee, 0x79, 0x42, 0x28, 0x58, 0x65, 0x46, 0xf7, 0x30, 0xa5,
-	0x38, 0x36, 0xd7, 0x99, 0x45, 0xf6, 0x35, 0x8c, 0x0d, 0xda, 0xe2, 0xe8, 0x28, 0x8b, 0xe9, 0xe6,
-	0x8b, 0xee, 0xa3, 0xea, 0xb4, 0x3d, 0x29, 0x2e, 0xc9, 0x6c, 0x03, 0x23, 0x34, 0x46, 0x87, 0x51,
-	0x98, 0x6e, 0x5e, 0x3f, 0xe1, 0xf5, 0x9d, 0xe7, 0xc4, 0x81, 0x1a, 0x7d, 0x03, 0xcf, 0xbf, 0xcf,
-	0x6c, 0xae, 0x9a, 0xe1, 0xaf, 0x61, 0x9e, 0x1f, 0xb4, 0xd3, 0x12, 0x9d, 0x50, 0xc7, 0x7a, 0xfa,
-	0xda, 0x60, 0xf4, 0x11, 0xae, 0x7e, 0xc2, 0x4c, 0x6a, 0xf3, 0x33, 0xee, 0x9a, 0xde, 0x6f, 0x00,
-	0xa4, 0x4e, 0x8a, 0x14, 0x33, 0x57, 0xbb, 0x36, 0x10, 0xdf, 0x5a, 0x91, 0x65, 0xda, 0x09, 0xa7,
-	0x74, 0xa6, 0x64, 0x39, 0xb7, 0x2d, 0x2c, 0xfa, 0x6b, 0x0c, 0xf3, 0x56, 0x8e, 0x67, 0x05, 0x31,
-	0x7a, 0x2c, 0x88, 0xe6, 0xc8, 0xf5, 0xcf, 0x8e, 0x9c, 0x4d, 0x44, 0x46, 0xc7, 0x83, 0x30, 0x72,
-	0x95, 0xdd, 0x16, 0xcb, 0xb0, 0x2b, 0x96, 0xff, 0x2f, 0x88, 0xe6, 0xb8, 0x5f, 0x74, 0xc6, 0xdd,
-	0xd7, 0x0c, 0x6d, 0x92, 0x1b, 0xbc, 0x51, 0xf7, 0xa5, 0x18, 0x1a, 0x48, 0xf0, 0xb5, 0x89, 0xc3,
-	0x7b, 0x47, 0x52, 0x20, 0xdf, 0x60, 0xfb, 0xb3, 0x44, 0x38, 0xdc, 0x6b, 0xf3, 0xc0, 0x21, 0x9c,
-	0x55, 0x76, 0x77, 0x45, 0x4c, 0x1f, 0xaf, 0x88, 0x25, 0x5c, 0x0a, 0x93, 0x1c, 0xd4, 0x2d, 0x4a,
-	0x3e, 0x0b, 0xde, 0x95, 0xed, 0xbd, 0x7d, 0x65, 0x12, 0x83, 0xc2, 0xa1, 0xe4, 0xf3, 0x50, 0xcf,
-	0x06, 0xe4, 0xbb, 0xe2, 0xcd, 0x54, 0x4b, 0xbc, 0x51, 0x28, 0xf9, 0x82, 0x28, 0x2d, 0xac, 0x5a,
-	0x33, 0x56, 0xfd, 0x86, 0xfc, 0x19, 0x95, 0xa5, 0xb6, 0x4b, 0x25, 0x3d, 0xaf, 0x95, 0x74, 0x0d,
-	0x73, 0x2a, 0x59, 0xbd, 0x97, 0x5e, 0xd0, 0x85, 0x6d, 0xd0, 0x47, 0x25, 0xc0, 0xf8, 0x81, 0x41,
-	0xc9, 0x59, 0x98, 0x85, 0x26, 0xe6, 0x6f, 0x72, 0x87, 0x22, 0xdd, 0x65, 0x42, 0x1d, 0x29, 0xf4,
-	0xa7, 0x44, 0x6a, 0x83, 0x3e, 0xc3, 0x3b, 0xdc, 0xd5, 0xd1, 0xae, 0x42, 0x86, 0x0d, 0xc8, 0xbf,
-	0x3e, 0x55, 0x69, 0x18, 0x8a, 0x97, 0xa1, 0x3e, 0x95, 0xcd, 0xde, 0xc3, 0x0b, 0xe5, 0xb5, 0x63,
-	0xca, 0xd1, 0x97, 0xc2, 0x09, 0xfe, 0x8a, 0xb4, 0xb7, 0xea, 0x6a, 0xaf, 0x2b, 0xb2, 0xf8, 0xb1,
-	0x2b, 0xfb, 0x08, 0x2f, 0x6f, 0x49, 0x51, 0x77, 0xb8, 0x6b, 0xdd, 0xf9, 0x19, 0xdd, 0x79, 0xdd,
-	0xbd, 0xf3, 0x9c, 0xfc, 0xe2, 0xf3, 0x57, 0x30, 0x06, 0xc3, 0x7d, 0xa1, 0x24, 0xe7, 0x94, 0x03,
-	0x7d, 0x47, 0x5b, 0x98, 0x35, 0x57, 0x82, 0xe7, 0xd0, 0x74, 0x06, 0x6d, 0xd1, 0xb7, 0xdf, 0x59,
-	0xe5, 0x02, 0x2b, 0xf5, 0x54, 0x99, 0x9e, 0x4d, 0x8f, 0x0b, 0x3a, 0xa2, 0xef, 0xe8, 0xf7, 0x1e,
-	0x3c, 0x3b, 0xe9, 0x36, 0xec, 0x83, 0xa7, 0x77, 0x72, 0xe8, 0x7e, 0xbf, 0xee, 0xfe, 0x69, 0xed,
-	0x0d, 0xfe, 0xd3, 0xda, 0x1b, 0xfe, 0xeb, 0xb5, 0xb7, 0x89, 0x61, 0xf1, 0x2e, 0xb0, 0x3e, 0x04,
-	0x16, 0xfb, 0x16, 0x06, 0xdb, 0xc2, 0xb1, 0x7f, 0xd8, 0xff, 0xcb, 0xcf, 0xcf, 0x9e, 0x85, 0x34,
-	0xa3, 0x4f, 0x76, 0x63, 0xfa, 0xb3, 0x7f, 0xf5, 0x77, 0x00, 0x00, 0x00, 0xff, 0xff, 0x21, 0x7a,
-	0xcd, 0x0d, 0xf1, 0x07, 0x00, 0x00,
+		GoTypes:           file_contentservice_proto_goTypes,
+		DependencyIndexes: file_contentservice_proto_depIdxs,
+		MessageInfos:      file_contentservice_proto_msgTypes,
+	}.Build()
+	File_contentservice_proto = out.File
+	file_contentservice_proto_goTypes = nil
+	file_contentservice_proto_depIdxs = nil
 }
@@ -0,0 +1,3 @@
+package contentservice
+
+//go:generate buf generate
@@ -0,0 +1,108 @@
+package contentservice
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	pbadmin "github.com/divyag9/gothinnercontentservice/contentservice/admin"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+)
+
+// compileProto compiles filename from source, without relying on protoc
+func compileProto(t *testing.T, filename string) protoreflect.FileDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{".", "third_party"}}),
+	}
+	files, err := compiler.Compile(context.Background(), filename)
+	if err != nil {
+		t.Fatalf("Error compiling %s: %v", filename, err)
+	}
+	return files[0]
+}
+
+// changedDeclarations names the top level messages, enums and services
+// which differ between two versions of a file
+func changedDeclarations(a, b *descriptorpb.FileDescriptorProto) []string {
+	declarations := func(file *descriptorpb.FileDescriptorProto) map[string]proto.Message {
+		m := map[string]proto.Message{"options": file.GetOptions()}
+		for _, message := range file.GetMessageType() {
+			m[message.GetName()] = message
+		}
+		for _, enum := range file.GetEnumType() {
+			m[enum.GetName()] = enum
+		}
+		for _, service := range file.GetService() {
+			m[service.GetName()] = service
+		}
+		return m
+	}
+	aDeclarations, bDeclarations := declarations(a), declarations(b)
+	var changed []string
+	for name, declaration := range aDeclarations {
+		if other, ok := bDeclarations[name]; !ok || !proto.Equal(declaration, other) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range bDeclarations {
+		if _, ok := aDeclarations[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// TestGeneratedCodeIsCurrent fails when the descriptors compiled into the
+// generated code differ from the .proto source. Run go generate to fix it.
+func TestGeneratedCodeIsCurrent(t *testing.T) {
+	cases := []struct {
+		filename  string
+		generated protoreflect.FileDescriptor
+	}{
+		{filename: "contentservice.proto", generated: File_contentservice_proto},
+		{filename: "v2/contentservice.proto", generated: pbv2.File_v2_contentservice_proto},
+		{filename: "admin/admin.proto", generated: pbadmin.File_admin_admin_proto},
+	}
+	for _, c := range cases {
+		source := protodesc.ToFileDescriptorProto(compileProto(t, c.filename))
+		generated := protodesc.ToFileDescriptorProto(c.generated)
+		source.SourceCodeInfo = nil
+		generated.SourceCodeInfo = nil
+		if !proto.Equal(source, generated) {
+			t.Errorf("%s is out of date with its generated code, run go generate. Changed: %v", c.filename, changedDeclarations(source, generated))
+		}
+	}
+}
+
+// TestGeneratedServicesAreCurrent fails when the gRPC service code does not
+// have the methods declared in the .proto source
+func TestGeneratedServicesAreCurrent(t *testing.T) {
+	cases := []struct {
+		filename string
+		desc     grpc.ServiceDesc
+	}{
+		{filename: "contentservice.proto", desc: ContentService_ServiceDesc},
+		{filename: "v2/contentservice.proto", desc: pbv2.ContentService_ServiceDesc},
+		{filename: "admin/admin.proto", desc: pbadmin.ContentServiceAdmin_ServiceDesc},
+	}
+	for _, c := range cases {
+		name := protoreflect.FullName(c.desc.ServiceName)
+		service := compileProto(t, c.filename).Services().ByName(name.Name())
+		if service == nil {
+			t.Errorf("%s does not declare %s, run go generate", c.filename, name)
+			continue
+		}
+		generated := len(c.desc.Methods) + len(c.desc.Streams)
+		if service.Methods().Len() != generated {
+			t.Errorf("%s declares %d methods but the generated service has %d, run go generate", name, service.Methods().Len(), generated)
+		}
+	}
+}
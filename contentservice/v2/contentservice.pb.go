@@ -0,0 +1,1216 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: v2/contentservice.proto
+
+package contentservicev2
+
+import (
+	date "google.golang.org/genproto/googleapis/type/date"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The kind of image being stored. Values match the ServiceBus imagetype codes.
+type ImageType int32
+
+const (
+	ImageType_IMAGE_TYPE_UNSPECIFIED ImageType = 0
+	ImageType_IMAGE_TYPE_PHOTO       ImageType = 1
+	ImageType_IMAGE_TYPE_DOCUMENT    ImageType = 2
+)
+
+// Enum value maps for ImageType.
+var (
+	ImageType_name = map[int32]string{
+		0: "IMAGE_TYPE_UNSPECIFIED",
+		1: "IMAGE_TYPE_PHOTO",
+		2: "IMAGE_TYPE_DOCUMENT",
+	}
+	ImageType_value = map[string]int32{
+		"IMAGE_TYPE_UNSPECIFIED": 0,
+		"IMAGE_TYPE_PHOTO":       1,
+		"IMAGE_TYPE_DOCUMENT":    2,
+	}
+)
+
+func (x ImageType) Enum() *ImageType {
+	p := new(ImageType)
+	*p = x
+	return p
+}
+
+func (x ImageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_v2_contentservice_proto_enumTypes[0].Descriptor()
+}
+
+func (ImageType) Type() protoreflect.EnumType {
+	return &file_v2_contentservice_proto_enumTypes[0]
+}
+
+func (x ImageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageType.Descriptor instead.
+func (ImageType) EnumDescriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{0}
+}
+
+// The consumer system the content is stored for. Each destination is put
+// through its own ServiceBus method and returns its own response data.
+type Destination int32
+
+const (
+	Destination_DESTINATION_UNSPECIFIED Destination = 0
+	Destination_DESTINATION_INSPI       Destination = 1
+	Destination_DESTINATION_VENDORWEB   Destination = 2
+)
+
+// Enum value maps for Destination.
+var (
+	Destination_name = map[int32]string{
+		0: "DESTINATION_UNSPECIFIED",
+		1: "DESTINATION_INSPI",
+		2: "DESTINATION_VENDORWEB",
+	}
+	Destination_value = map[string]int32{
+		"DESTINATION_UNSPECIFIED": 0,
+		"DESTINATION_INSPI":       1,
+		"DESTINATION_VENDORWEB":   2,
+	}
+)
+
+func (x Destination) Enum() *Destination {
+	p := new(Destination)
+	*p = x
+	return p
+}
+
+func (x Destination) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Destination) Descriptor() protoreflect.EnumDescriptor {
+	return file_v2_contentservice_proto_enumTypes[1].Descriptor()
+}
+
+func (Destination) Type() protoreflect.EnumType {
+	return &file_v2_contentservice_proto_enumTypes[1]
+}
+
+func (x Destination) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Destination.Descriptor instead.
+func (Destination) EnumDescriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{1}
+}
+
+// Whether stored content has been archived. ServiceBus represents this as
+// "N" or "Y".
+type ArchiveStatus int32
+
+const (
+	ArchiveStatus_ARCHIVE_STATUS_UNSPECIFIED ArchiveStatus = 0
+	ArchiveStatus_ARCHIVE_STATUS_ACTIVE      ArchiveStatus = 1
+	ArchiveStatus_ARCHIVE_STATUS_ARCHIVED    ArchiveStatus = 2
+)
+
+// Enum value maps for ArchiveStatus.
+var (
+	ArchiveStatus_name = map[int32]string{
+		0: "ARCHIVE_STATUS_UNSPECIFIED",
+		1: "ARCHIVE_STATUS_ACTIVE",
+		2: "ARCHIVE_STATUS_ARCHIVED",
+	}
+	ArchiveStatus_value = map[string]int32{
+		"ARCHIVE_STATUS_UNSPECIFIED": 0,
+		"ARCHIVE_STATUS_ACTIVE":      1,
+		"ARCHIVE_STATUS_ARCHIVED":    2,
+	}
+)
+
+func (x ArchiveStatus) Enum() *ArchiveStatus {
+	p := new(ArchiveStatus)
+	*p = x
+	return p
+}
+
+func (x ArchiveStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ArchiveStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_v2_contentservice_proto_enumTypes[2].Descriptor()
+}
+
+func (ArchiveStatus) Type() protoreflect.EnumType {
+	return &file_v2_contentservice_proto_enumTypes[2]
+}
+
+func (x ArchiveStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ArchiveStatus.Descriptor instead.
+func (ArchiveStatus) EnumDescriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{2}
+}
+
+// Request sent to the server
+type PutRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ContractorId int64                  `protobuf:"varint,1,opt,name=contractor_id,json=contractorId,proto3" json:"contractor_id,omitempty"`
+	OrderNumber  int64                  `protobuf:"varint,2,opt,name=order_number,json=orderNumber,proto3" json:"order_number,omitempty"`
+	ImageType    ImageType              `protobuf:"varint,3,opt,name=image_type,json=imageType,proto3,enum=contentservice.v2.ImageType" json:"image_type,omitempty"`
+	Filename     string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	ImageWidth   int32                  `protobuf:"varint,5,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight  int32                  `protobuf:"varint,6,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	ReleaseDate  *date.Date             `protobuf:"bytes,7,opt,name=release_date,json=releaseDate,proto3" json:"release_date,omitempty"`
+	DeptCode     string                 `protobuf:"bytes,8,opt,name=dept_code,json=deptCode,proto3" json:"dept_code,omitempty"`
+	FileContents []byte                 `protobuf:"bytes,9,opt,name=file_contents,json=fileContents,proto3" json:"file_contents,omitempty"`
+	Destination  Destination            `protobuf:"varint,10,opt,name=destination,proto3,enum=contentservice.v2.Destination" json:"destination,omitempty"`
+	// Data for the destination system, which must be the one set
+	//
+	// Types that are valid to be assigned to DestinationRequest:
+	//
+	//	*PutRequest_Inspi
+	//	*PutRequest_VendorWeb
+	DestinationRequest isPutRequest_DestinationRequest `protobuf_oneof:"destination_request"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *PutRequest) Reset() {
+	*x = PutRequest{}
+	mi := &file_v2_contentservice_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutRequest) ProtoMessage() {}
+
+func (x *PutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutRequest.ProtoReflect.Descriptor instead.
+func (*PutRequest) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PutRequest) GetContractorId() int64 {
+	if x != nil {
+		return x.ContractorId
+	}
+	return 0
+}
+
+func (x *PutRequest) GetOrderNumber() int64 {
+	if x != nil {
+		return x.OrderNumber
+	}
+	return 0
+}
+
+func (x *PutRequest) GetImageType() ImageType {
+	if x != nil {
+		return x.ImageType
+	}
+	return ImageType_IMAGE_TYPE_UNSPECIFIED
+}
+
+func (x *PutRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *PutRequest) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *PutRequest) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *PutRequest) GetReleaseDate() *date.Date {
+	if x != nil {
+		return x.ReleaseDate
+	}
+	return nil
+}
+
+func (x *PutRequest) GetDeptCode() string {
+	if x != nil {
+		return x.DeptCode
+	}
+	return ""
+}
+
+func (x *PutRequest) GetFileContents() []byte {
+	if x != nil {
+		return x.FileContents
+	}
+	return nil
+}
+
+func (x *PutRequest) GetDestination() Destination {
+	if x != nil {
+		return x.Destination
+	}
+	return Destination_DESTINATION_UNSPECIFIED
+}
+
+func (x *PutRequest) GetDestinationRequest() isPutRequest_DestinationRequest {
+	if x != nil {
+		return x.DestinationRequest
+	}
+	return nil
+}
+
+func (x *PutRequest) GetInspi() *InspiPutRequest {
+	if x != nil {
+		if x, ok := x.DestinationRequest.(*PutRequest_Inspi); ok {
+			return x.Inspi
+		}
+	}
+	return nil
+}
+
+func (x *PutRequest) GetVendorWeb() *VendorWebPutRequest {
+	if x != nil {
+		if x, ok := x.DestinationRequest.(*PutRequest_VendorWeb); ok {
+			return x.VendorWeb
+		}
+	}
+	return nil
+}
+
+type isPutRequest_DestinationRequest interface {
+	isPutRequest_DestinationRequest()
+}
+
+type PutRequest_Inspi struct {
+	Inspi *InspiPutRequest `protobuf:"bytes,11,opt,name=inspi,proto3,oneof"`
+}
+
+type PutRequest_VendorWeb struct {
+	VendorWeb *VendorWebPutRequest `protobuf:"bytes,12,opt,name=vendor_web,json=vendorWeb,proto3,oneof"`
+}
+
+func (*PutRequest_Inspi) isPutRequest_DestinationRequest() {}
+
+func (*PutRequest_VendorWeb) isPutRequest_DestinationRequest() {}
+
+type InspiPutRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The photo detail the content is stored for, if not a new one
+	PhotoDetailId int64 `protobuf:"varint,1,opt,name=photo_detail_id,json=photoDetailId,proto3" json:"photo_detail_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspiPutRequest) Reset() {
+	*x = InspiPutRequest{}
+	mi := &file_v2_contentservice_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspiPutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspiPutRequest) ProtoMessage() {}
+
+func (x *InspiPutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspiPutRequest.ProtoReflect.Descriptor instead.
+func (*InspiPutRequest) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *InspiPutRequest) GetPhotoDetailId() int64 {
+	if x != nil {
+		return x.PhotoDetailId
+	}
+	return 0
+}
+
+type VendorWebPutRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The document the content is annotated onto, if not a new one
+	DocumentId    int64 `protobuf:"varint,1,opt,name=document_id,json=documentId,proto3" json:"document_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorWebPutRequest) Reset() {
+	*x = VendorWebPutRequest{}
+	mi := &file_v2_contentservice_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorWebPutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorWebPutRequest) ProtoMessage() {}
+
+func (x *VendorWebPutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorWebPutRequest.ProtoReflect.Descriptor instead.
+func (*VendorWebPutRequest) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VendorWebPutRequest) GetDocumentId() int64 {
+	if x != nil {
+		return x.DocumentId
+	}
+	return 0
+}
+
+// Response from the Server
+type PutResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Result *PutResult             `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Error  *PutError              `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// When and where the photo was taken, read from its metadata if the
+	// metadata policy of its department extracts them
+	CaptureMetadata *CaptureMetadata `protobuf:"bytes,3,opt,name=capture_metadata,json=captureMetadata,proto3" json:"capture_metadata,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PutResponse) Reset() {
+	*x = PutResponse{}
+	mi := &file_v2_contentservice_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutResponse) ProtoMessage() {}
+
+func (x *PutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResponse.ProtoReflect.Descriptor instead.
+func (*PutResponse) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PutResponse) GetResult() *PutResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *PutResponse) GetError() *PutError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *PutResponse) GetCaptureMetadata() *CaptureMetadata {
+	if x != nil {
+		return x.CaptureMetadata
+	}
+	return nil
+}
+
+type CaptureMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Camera local times without an offset from UTC are taken to be in the
+	// ServiceBus time zone
+	CaptureTime   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=capture_time,json=captureTime,proto3" json:"capture_time,omitempty"`
+	Gps           *GPSPosition           `protobuf:"bytes,2,opt,name=gps,proto3" json:"gps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureMetadata) Reset() {
+	*x = CaptureMetadata{}
+	mi := &file_v2_contentservice_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureMetadata) ProtoMessage() {}
+
+func (x *CaptureMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureMetadata.ProtoReflect.Descriptor instead.
+func (*CaptureMetadata) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CaptureMetadata) GetCaptureTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CaptureTime
+	}
+	return nil
+}
+
+func (x *CaptureMetadata) GetGps() *GPSPosition {
+	if x != nil {
+		return x.Gps
+	}
+	return nil
+}
+
+type GPSPosition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Degrees, negative south and west
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Meters, negative below sea level
+	Altitude      float64 `protobuf:"fixed64,3,opt,name=altitude,proto3" json:"altitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPSPosition) Reset() {
+	*x = GPSPosition{}
+	mi := &file_v2_contentservice_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPSPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPSPosition) ProtoMessage() {}
+
+func (x *GPSPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPSPosition.ProtoReflect.Descriptor instead.
+func (*GPSPosition) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GPSPosition) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GPSPosition) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GPSPosition) GetAltitude() float64 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+type InspiPutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PhotoDetailId int64                  `protobuf:"varint,1,opt,name=photo_detail_id,json=photoDetailId,proto3" json:"photo_detail_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspiPutResponse) Reset() {
+	*x = InspiPutResponse{}
+	mi := &file_v2_contentservice_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspiPutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspiPutResponse) ProtoMessage() {}
+
+func (x *InspiPutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspiPutResponse.ProtoReflect.Descriptor instead.
+func (*InspiPutResponse) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InspiPutResponse) GetPhotoDetailId() int64 {
+	if x != nil {
+		return x.PhotoDetailId
+	}
+	return 0
+}
+
+type VendorWebPutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DocumentId    int64                  `protobuf:"varint,1,opt,name=document_id,json=documentId,proto3" json:"document_id,omitempty"`
+	AnnotationId  int64                  `protobuf:"varint,2,opt,name=annotation_id,json=annotationId,proto3" json:"annotation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VendorWebPutResponse) Reset() {
+	*x = VendorWebPutResponse{}
+	mi := &file_v2_contentservice_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VendorWebPutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VendorWebPutResponse) ProtoMessage() {}
+
+func (x *VendorWebPutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VendorWebPutResponse.ProtoReflect.Descriptor instead.
+func (*VendorWebPutResponse) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VendorWebPutResponse) GetDocumentId() int64 {
+	if x != nil {
+		return x.DocumentId
+	}
+	return 0
+}
+
+func (x *VendorWebPutResponse) GetAnnotationId() int64 {
+	if x != nil {
+		return x.AnnotationId
+	}
+	return 0
+}
+
+// Message when Put request succeeded
+type PutResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContractorId  int32                  `protobuf:"varint,1,opt,name=contractor_id,json=contractorId,proto3" json:"contractor_id,omitempty"`
+	ReleaseDate   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=release_date,json=releaseDate,proto3" json:"release_date,omitempty"`
+	ScanDate      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=scan_date,json=scanDate,proto3" json:"scan_date,omitempty"`
+	ImageType     ImageType              `protobuf:"varint,4,opt,name=image_type,json=imageType,proto3,enum=contentservice.v2.ImageType" json:"image_type,omitempty"`
+	ImageWidth    int32                  `protobuf:"varint,5,opt,name=image_width,json=imageWidth,proto3" json:"image_width,omitempty"`
+	ImageHeight   int32                  `protobuf:"varint,6,opt,name=image_height,json=imageHeight,proto3" json:"image_height,omitempty"`
+	DeptCode      string                 `protobuf:"bytes,7,opt,name=dept_code,json=deptCode,proto3" json:"dept_code,omitempty"`
+	DescPrefix    string                 `protobuf:"bytes,8,opt,name=desc_prefix,json=descPrefix,proto3" json:"desc_prefix,omitempty"`
+	DescText      string                 `protobuf:"bytes,9,opt,name=desc_text,json=descText,proto3" json:"desc_text,omitempty"`
+	Category      string                 `protobuf:"bytes,10,opt,name=category,proto3" json:"category,omitempty"`
+	OrderNumber   int64                  `protobuf:"varint,11,opt,name=order_number,json=orderNumber,proto3" json:"order_number,omitempty"`
+	ArchiveStatus ArchiveStatus          `protobuf:"varint,12,opt,name=archive_status,json=archiveStatus,proto3,enum=contentservice.v2.ArchiveStatus" json:"archive_status,omitempty"`
+	DateCreated   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=date_created,json=dateCreated,proto3" json:"date_created,omitempty"`
+	DateModified  *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=date_modified,json=dateModified,proto3" json:"date_modified,omitempty"`
+	FileSize      int32                  `protobuf:"varint,15,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	Id            int32                  `protobuf:"varint,16,opt,name=id,proto3" json:"id,omitempty"`
+	ImageFilename string                 `protobuf:"bytes,17,opt,name=image_filename,json=imageFilename,proto3" json:"image_filename,omitempty"`
+	ImageRotated  int32                  `protobuf:"varint,18,opt,name=image_rotated,json=imageRotated,proto3" json:"image_rotated,omitempty"`
+	ThumbnailSize int32                  `protobuf:"varint,19,opt,name=thumbnail_size,json=thumbnailSize,proto3" json:"thumbnail_size,omitempty"`
+	WebFilename   string                 `protobuf:"bytes,20,opt,name=web_filename,json=webFilename,proto3" json:"web_filename,omitempty"`
+	MimeType      string                 `protobuf:"bytes,21,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// Data returned by the destination system the content was put for
+	//
+	// Types that are valid to be assigned to DestinationResponse:
+	//
+	//	*PutResult_Inspi
+	//	*PutResult_VendorWeb
+	DestinationResponse isPutResult_DestinationResponse `protobuf_oneof:"destination_response"`
+	Guid                string                          `protobuf:"bytes,24,opt,name=guid,proto3" json:"guid,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PutResult) Reset() {
+	*x = PutResult{}
+	mi := &file_v2_contentservice_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutResult) ProtoMessage() {}
+
+func (x *PutResult) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutResult.ProtoReflect.Descriptor instead.
+func (*PutResult) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PutResult) GetContractorId() int32 {
+	if x != nil {
+		return x.ContractorId
+	}
+	return 0
+}
+
+func (x *PutResult) GetReleaseDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReleaseDate
+	}
+	return nil
+}
+
+func (x *PutResult) GetScanDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScanDate
+	}
+	return nil
+}
+
+func (x *PutResult) GetImageType() ImageType {
+	if x != nil {
+		return x.ImageType
+	}
+	return ImageType_IMAGE_TYPE_UNSPECIFIED
+}
+
+func (x *PutResult) GetImageWidth() int32 {
+	if x != nil {
+		return x.ImageWidth
+	}
+	return 0
+}
+
+func (x *PutResult) GetImageHeight() int32 {
+	if x != nil {
+		return x.ImageHeight
+	}
+	return 0
+}
+
+func (x *PutResult) GetDeptCode() string {
+	if x != nil {
+		return x.DeptCode
+	}
+	return ""
+}
+
+func (x *PutResult) GetDescPrefix() string {
+	if x != nil {
+		return x.DescPrefix
+	}
+	return ""
+}
+
+func (x *PutResult) GetDescText() string {
+	if x != nil {
+		return x.DescText
+	}
+	return ""
+}
+
+func (x *PutResult) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *PutResult) GetOrderNumber() int64 {
+	if x != nil {
+		return x.OrderNumber
+	}
+	return 0
+}
+
+func (x *PutResult) GetArchiveStatus() ArchiveStatus {
+	if x != nil {
+		return x.ArchiveStatus
+	}
+	return ArchiveStatus_ARCHIVE_STATUS_UNSPECIFIED
+}
+
+func (x *PutResult) GetDateCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateCreated
+	}
+	return nil
+}
+
+func (x *PutResult) GetDateModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateModified
+	}
+	return nil
+}
+
+func (x *PutResult) GetFileSize() int32 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *PutResult) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PutResult) GetImageFilename() string {
+	if x != nil {
+		return x.ImageFilename
+	}
+	return ""
+}
+
+func (x *PutResult) GetImageRotated() int32 {
+	if x != nil {
+		return x.ImageRotated
+	}
+	return 0
+}
+
+func (x *PutResult) GetThumbnailSize() int32 {
+	if x != nil {
+		return x.ThumbnailSize
+	}
+	return 0
+}
+
+func (x *PutResult) GetWebFilename() string {
+	if x != nil {
+		return x.WebFilename
+	}
+	return ""
+}
+
+func (x *PutResult) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *PutResult) GetDestinationResponse() isPutResult_DestinationResponse {
+	if x != nil {
+		return x.DestinationResponse
+	}
+	return nil
+}
+
+func (x *PutResult) GetInspi() *InspiPutResponse {
+	if x != nil {
+		if x, ok := x.DestinationResponse.(*PutResult_Inspi); ok {
+			return x.Inspi
+		}
+	}
+	return nil
+}
+
+func (x *PutResult) GetVendorWeb() *VendorWebPutResponse {
+	if x != nil {
+		if x, ok := x.DestinationResponse.(*PutResult_VendorWeb); ok {
+			return x.VendorWeb
+		}
+	}
+	return nil
+}
+
+func (x *PutResult) GetGuid() string {
+	if x != nil {
+		return x.Guid
+	}
+	return ""
+}
+
+type isPutResult_DestinationResponse interface {
+	isPutResult_DestinationResponse()
+}
+
+type PutResult_Inspi struct {
+	Inspi *InspiPutResponse `protobuf:"bytes,22,opt,name=inspi,proto3,oneof"`
+}
+
+type PutResult_VendorWeb struct {
+	VendorWeb *VendorWebPutResponse `protobuf:"bytes,23,opt,name=vendor_web,json=vendorWeb,proto3,oneof"`
+}
+
+func (*PutResult_Inspi) isPutResult_DestinationResponse() {}
+
+func (*PutResult_VendorWeb) isPutResult_DestinationResponse() {}
+
+// Message when Put request failed
+type PutError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Data          string                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutError) Reset() {
+	*x = PutError{}
+	mi := &file_v2_contentservice_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutError) ProtoMessage() {}
+
+func (x *PutError) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_contentservice_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutError.ProtoReflect.Descriptor instead.
+func (*PutError) Descriptor() ([]byte, []int) {
+	return file_v2_contentservice_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PutError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PutError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PutError) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+var File_v2_contentservice_proto protoreflect.FileDescriptor
+
+const file_v2_contentservice_proto_rawDesc = "" +
+	"\n" +
+	"\x17v2/contentservice.proto\x12\x11contentservice.v2\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x16google/type/date.proto\"\xc7\x04\n" +
+	"\n" +
+	"PutRequest\x12#\n" +
+	"\rcontractor_id\x18\x01 \x01(\x03R\fcontractorId\x12!\n" +
+	"\forder_number\x18\x02 \x01(\x03R\vorderNumber\x12;\n" +
+	"\n" +
+	"image_type\x18\x03 \x01(\x0e2\x1c.contentservice.v2.ImageTypeR\timageType\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x12\x1f\n" +
+	"\vimage_width\x18\x05 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x06 \x01(\x05R\vimageHeight\x124\n" +
+	"\frelease_date\x18\a \x01(\v2\x11.google.type.DateR\vreleaseDate\x12\x1b\n" +
+	"\tdept_code\x18\b \x01(\tR\bdeptCode\x12#\n" +
+	"\rfile_contents\x18\t \x01(\fR\ffileContents\x12@\n" +
+	"\vdestination\x18\n" +
+	" \x01(\x0e2\x1e.contentservice.v2.DestinationR\vdestination\x12:\n" +
+	"\x05inspi\x18\v \x01(\v2\".contentservice.v2.InspiPutRequestH\x00R\x05inspi\x12G\n" +
+	"\n" +
+	"vendor_web\x18\f \x01(\v2&.contentservice.v2.VendorWebPutRequestH\x00R\tvendorWebB\x15\n" +
+	"\x13destination_request\"9\n" +
+	"\x0fInspiPutRequest\x12&\n" +
+	"\x0fphoto_detail_id\x18\x01 \x01(\x03R\rphotoDetailId\"6\n" +
+	"\x13VendorWebPutRequest\x12\x1f\n" +
+	"\vdocument_id\x18\x01 \x01(\x03R\n" +
+	"documentId\"\xc5\x01\n" +
+	"\vPutResponse\x124\n" +
+	"\x06result\x18\x01 \x01(\v2\x1c.contentservice.v2.PutResultR\x06result\x121\n" +
+	"\x05error\x18\x02 \x01(\v2\x1b.contentservice.v2.PutErrorR\x05error\x12M\n" +
+	"\x10capture_metadata\x18\x03 \x01(\v2\".contentservice.v2.CaptureMetadataR\x0fcaptureMetadata\"\x82\x01\n" +
+	"\x0fCaptureMetadata\x12=\n" +
+	"\fcapture_time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vcaptureTime\x120\n" +
+	"\x03gps\x18\x02 \x01(\v2\x1e.contentservice.v2.GPSPositionR\x03gps\"c\n" +
+	"\vGPSPosition\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\baltitude\x18\x03 \x01(\x01R\baltitude\":\n" +
+	"\x10InspiPutResponse\x12&\n" +
+	"\x0fphoto_detail_id\x18\x01 \x01(\x03R\rphotoDetailId\"\\\n" +
+	"\x14VendorWebPutResponse\x12\x1f\n" +
+	"\vdocument_id\x18\x01 \x01(\x03R\n" +
+	"documentId\x12#\n" +
+	"\rannotation_id\x18\x02 \x01(\x03R\fannotationId\"\x9f\b\n" +
+	"\tPutResult\x12#\n" +
+	"\rcontractor_id\x18\x01 \x01(\x05R\fcontractorId\x12=\n" +
+	"\frelease_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vreleaseDate\x127\n" +
+	"\tscan_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bscanDate\x12;\n" +
+	"\n" +
+	"image_type\x18\x04 \x01(\x0e2\x1c.contentservice.v2.ImageTypeR\timageType\x12\x1f\n" +
+	"\vimage_width\x18\x05 \x01(\x05R\n" +
+	"imageWidth\x12!\n" +
+	"\fimage_height\x18\x06 \x01(\x05R\vimageHeight\x12\x1b\n" +
+	"\tdept_code\x18\a \x01(\tR\bdeptCode\x12\x1f\n" +
+	"\vdesc_prefix\x18\b \x01(\tR\n" +
+	"descPrefix\x12\x1b\n" +
+	"\tdesc_text\x18\t \x01(\tR\bdescText\x12\x1a\n" +
+	"\bcategory\x18\n" +
+	" \x01(\tR\bcategory\x12!\n" +
+	"\forder_number\x18\v \x01(\x03R\vorderNumber\x12G\n" +
+	"\x0earchive_status\x18\f \x01(\x0e2 .contentservice.v2.ArchiveStatusR\rarchiveStatus\x12=\n" +
+	"\fdate_created\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\vdateCreated\x12?\n" +
+	"\rdate_modified\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\fdateModified\x12\x1b\n" +
+	"\tfile_size\x18\x0f \x01(\x05R\bfileSize\x12\x0e\n" +
+	"\x02id\x18\x10 \x01(\x05R\x02id\x12%\n" +
+	"\x0eimage_filename\x18\x11 \x01(\tR\rimageFilename\x12#\n" +
+	"\rimage_rotated\x18\x12 \x01(\x05R\fimageRotated\x12%\n" +
+	"\x0ethumbnail_size\x18\x13 \x01(\x05R\rthumbnailSize\x12!\n" +
+	"\fweb_filename\x18\x14 \x01(\tR\vwebFilename\x12\x1b\n" +
+	"\tmime_type\x18\x15 \x01(\tR\bmimeType\x12;\n" +
+	"\x05inspi\x18\x16 \x01(\v2#.contentservice.v2.InspiPutResponseH\x00R\x05inspi\x12H\n" +
+	"\n" +
+	"vendor_web\x18\x17 \x01(\v2'.contentservice.v2.VendorWebPutResponseH\x00R\tvendorWeb\x12\x12\n" +
+	"\x04guid\x18\x18 \x01(\tR\x04guidB\x16\n" +
+	"\x14destination_response\"L\n" +
+	"\bPutError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\tR\x04data*V\n" +
+	"\tImageType\x12\x1a\n" +
+	"\x16IMAGE_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10IMAGE_TYPE_PHOTO\x10\x01\x12\x17\n" +
+	"\x13IMAGE_TYPE_DOCUMENT\x10\x02*\\\n" +
+	"\vDestination\x12\x1b\n" +
+	"\x17DESTINATION_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11DESTINATION_INSPI\x10\x01\x12\x19\n" +
+	"\x15DESTINATION_VENDORWEB\x10\x02*g\n" +
+	"\rArchiveStatus\x12\x1e\n" +
+	"\x1aARCHIVE_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ARCHIVE_STATUS_ACTIVE\x10\x01\x12\x1b\n" +
+	"\x17ARCHIVE_STATUS_ARCHIVED\x10\x022X\n" +
+	"\x0eContentService\x12F\n" +
+	"\x03Put\x12\x1d.contentservice.v2.PutRequest\x1a\x1e.contentservice.v2.PutResponse\"\x00BOZMgithub.com/divyag9/gothinnercontentservice/contentservice/v2;contentservicev2b\x06proto3"
+
+var (
+	file_v2_contentservice_proto_rawDescOnce sync.Once
+	file_v2_contentservice_proto_rawDescData []byte
+)
+
+func file_v2_contentservice_proto_rawDescGZIP() []byte {
+	file_v2_contentservice_proto_rawDescOnce.Do(func() {
+		file_v2_contentservice_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_v2_contentservice_proto_rawDesc), len(file_v2_contentservice_proto_rawDesc)))
+	})
+	return file_v2_contentservice_proto_rawDescData
+}
+
+var file_v2_contentservice_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_v2_contentservice_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_v2_contentservice_proto_goTypes = []any{
+	(ImageType)(0),                // 0: contentservice.v2.ImageType
+	(Destination)(0),              // 1: contentservice.v2.Destination
+	(ArchiveStatus)(0),            // 2: contentservice.v2.ArchiveStatus
+	(*PutRequest)(nil),            // 3: contentservice.v2.PutRequest
+	(*InspiPutRequest)(nil),       // 4: contentservice.v2.InspiPutRequest
+	(*VendorWebPutRequest)(nil),   // 5: contentservice.v2.VendorWebPutRequest
+	(*PutResponse)(nil),           // 6: contentservice.v2.PutResponse
+	(*CaptureMetadata)(nil),       // 7: contentservice.v2.CaptureMetadata
+	(*GPSPosition)(nil),           // 8: contentservice.v2.GPSPosition
+	(*InspiPutResponse)(nil),      // 9: contentservice.v2.InspiPutResponse
+	(*VendorWebPutResponse)(nil),  // 10: contentservice.v2.VendorWebPutResponse
+	(*PutResult)(nil),             // 11: contentservice.v2.PutResult
+	(*PutError)(nil),              // 12: contentservice.v2.PutError
+	(*date.Date)(nil),             // 13: google.type.Date
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_v2_contentservice_proto_depIdxs = []int32{
+	0,  // 0: contentservice.v2.PutRequest.image_type:type_name -> contentservice.v2.ImageType
+	13, // 1: contentservice.v2.PutRequest.release_date:type_name -> google.type.Date
+	1,  // 2: contentservice.v2.PutRequest.destination:type_name -> contentservice.v2.Destination
+	4,  // 3: contentservice.v2.PutRequest.inspi:type_name -> contentservice.v2.InspiPutRequest
+	5,  // 4: contentservice.v2.PutRequest.vendor_web:type_name -> contentservice.v2.VendorWebPutRequest
+	11, // 5: contentservice.v2.PutResponse.result:type_name -> contentservice.v2.PutResult
+	12, // 6: contentservice.v2.PutResponse.error:type_name -> contentservice.v2.PutError
+	7,  // 7: contentservice.v2.PutResponse.capture_metadata:type_name -> contentservice.v2.CaptureMetadata
+	14, // 8: contentservice.v2.CaptureMetadata.capture_time:type_name -> google.protobuf.Timestamp
+	8,  // 9: contentservice.v2.CaptureMetadata.gps:type_name -> contentservice.v2.GPSPosition
+	14, // 10: contentservice.v2.PutResult.release_date:type_name -> google.protobuf.Timestamp
+	14, // 11: contentservice.v2.PutResult.scan_date:type_name -> google.protobuf.Timestamp
+	0,  // 12: contentservice.v2.PutResult.image_type:type_name -> contentservice.v2.ImageType
+	2,  // 13: contentservice.v2.PutResult.archive_status:type_name -> contentservice.v2.ArchiveStatus
+	14, // 14: contentservice.v2.PutResult.date_created:type_name -> google.protobuf.Timestamp
+	14, // 15: contentservice.v2.PutResult.date_modified:type_name -> google.protobuf.Timestamp
+	9,  // 16: contentservice.v2.PutResult.inspi:type_name -> contentservice.v2.InspiPutResponse
+	10, // 17: contentservice.v2.PutResult.vendor_web:type_name -> contentservice.v2.VendorWebPutResponse
+	3,  // 18: contentservice.v2.ContentService.Put:input_type -> contentservice.v2.PutRequest
+	6,  // 19: contentservice.v2.ContentService.Put:output_type -> contentservice.v2.PutResponse
+	19, // [19:20] is the sub-list for method output_type
+	18, // [18:19] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_v2_contentservice_proto_init() }
+func file_v2_contentservice_proto_init() {
+	if File_v2_contentservice_proto != nil {
+		return
+	}
+	file_v2_contentservice_proto_msgTypes[0].OneofWrappers = []any{
+		(*PutRequest_Inspi)(nil),
+		(*PutRequest_VendorWeb)(nil),
+	}
+	file_v2_contentservice_proto_msgTypes[8].OneofWrappers = []any{
+		(*PutResult_Inspi)(nil),
+		(*PutResult_VendorWeb)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_v2_contentservice_proto_rawDesc), len(file_v2_contentservice_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_v2_contentservice_proto_goTypes,
+		DependencyIndexes: file_v2_contentservice_proto_depIdxs,
+		EnumInfos:         file_v2_contentservice_proto_enumTypes,
+		MessageInfos:      file_v2_contentservice_proto_msgTypes,
+	}.Build()
+	File_v2_contentservice_proto = out.File
+	file_v2_contentservice_proto_goTypes = nil
+	file_v2_contentservice_proto_depIdxs = nil
+}
@@ -0,0 +1,87 @@
+package imaging
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache keeps the derivatives of recently derived contents, evicting the
+// least recently used once their size exceeds MaxBytes. Retried and
+// duplicate uploads are then not decoded and resized again.
+type Cache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	bytes   int64
+	order   *list.List
+	entries map[string]*list.Element
+	hits    int64
+	misses  int64
+}
+
+type cacheEntry struct {
+	key         string
+	derivatives []Derivative
+	bytes       int64
+}
+
+// NewCache returns a cache holding up to maxBytes of derivative contents
+func NewCache(maxBytes int64) *Cache {
+	return &Cache{maxBytes: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get returns the derivatives added with key, which must not be modified
+func (c *Cache) Get(key string) ([]Derivative, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).derivatives, true
+}
+
+// Add keeps derivatives under key, unless they alone exceed the cache size
+func (c *Cache) Add(key string, derivatives []Derivative) {
+	entry := &cacheEntry{key: key, derivatives: derivatives}
+	for _, derivative := range derivatives {
+		entry.bytes += int64(len(derivative.Contents))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry.bytes > c.maxBytes {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += entry.bytes
+	for c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.bytes
+}
+
+// CacheStats describes the contents and effectiveness of a Cache
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// Stats returns the current size of the cache and its hits and misses
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), Bytes: c.bytes, Hits: c.hits, Misses: c.misses}
+}
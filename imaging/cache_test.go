@@ -0,0 +1,30 @@
+package imaging
+
+import "testing"
+
+func derivativesOf(size int) []Derivative {
+	return []Derivative{{Name: "thumbnail", Contents: make([]byte, size)}}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache(100)
+	cache.Add("a", derivativesOf(40))
+	cache.Add("b", derivativesOf(40))
+	cache.Get("a")
+	cache.Add("c", derivativesOf(40))
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+	cache.Add("d", derivativesOf(101))
+	if _, ok := cache.Get("d"); ok {
+		t.Errorf("Expected derivatives larger than the cache not to be cached")
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Bytes != 80 {
+		t.Errorf("Expected 2 entries of 80 bytes but got %+v", stats)
+	}
+}
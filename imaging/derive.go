@@ -0,0 +1,305 @@
+// Package imaging generates resized derivatives of uploaded images, such as
+// thumbnails, using only the standard library's decoders and encoders.
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	// Register the GIF decoder with image.Decode
+	_ "image/gif"
+)
+
+// DefaultMaxPixels is the largest image, in pixels, decoded when a Deriver
+// does not set MaxPixels
+const DefaultMaxPixels = 50000000
+
+// DefaultQuality is the JPEG quality used when a Deriver does not set Quality
+const DefaultQuality = 85
+
+var (
+	// ErrUnsupported is returned for contents which are not a JPEG, PNG or
+	// GIF image, such as PDF documents
+	ErrUnsupported = errors.New("Unsupported image format")
+	// ErrTooLarge is returned for images with more than MaxPixels pixels
+	ErrTooLarge = errors.New("Image is too large to resize")
+)
+
+// Size is a named box a derivative is scaled to fit within
+type Size struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%s:%dx%d", s.Name, s.Width, s.Height)
+}
+
+// ParseSizes parses a comma separated list of sizes such as
+// "thumbnail:150x150,web:1024x1024". An empty value is no sizes.
+func ParseSizes(value string) ([]Size, error) {
+	var sizes []Size
+	names := make(map[string]bool)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Size %q must be name:WIDTHxHEIGHT", field)
+		}
+		dimensions := strings.SplitN(parts[1], "x", 2)
+		if len(dimensions) != 2 {
+			return nil, fmt.Errorf("Size %q must be name:WIDTHxHEIGHT", field)
+		}
+		width, err := strconv.Atoi(dimensions[0])
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("Size %q must have a positive width", field)
+		}
+		height, err := strconv.Atoi(dimensions[1])
+		if err != nil || height <= 0 {
+			return nil, fmt.Errorf("Size %q must have a positive height", field)
+		}
+		if names[parts[0]] {
+			return nil, fmt.Errorf("Size %s is named more than once", parts[0])
+		}
+		names[parts[0]] = true
+		sizes = append(sizes, Size{Name: parts[0], Width: width, Height: height})
+	}
+	return sizes, nil
+}
+
+// Derivative is an image scaled to fit one Size
+type Derivative struct {
+	Name     string
+	Width    int
+	Height   int
+	MimeType string
+	Contents []byte
+}
+
+// Deriver scales images to each of its Sizes. PNG images produce PNG
+// derivatives, keeping their transparency, and other images produce JPEGs.
+type Deriver struct {
+	Sizes []Size
+	// Quality is the JPEG quality from 1 to 100, DefaultQuality if 0
+	Quality int
+	// MaxPixels bounds the images decoded, DefaultMaxPixels if 0
+	MaxPixels int
+	// Cache, if set, keeps the derivatives of recently seen contents
+	Cache *Cache
+}
+
+// Derive returns a derivative of contents for each size, turned upright
+// according to any Exif orientation. Images smaller than a size are
+// re-encoded without being enlarged.
+func (d *Deriver) Derive(contents []byte) ([]Derivative, error) {
+	if len(d.Sizes) == 0 {
+		return nil, nil
+	}
+	key := d.cacheKey(contents)
+	if d.Cache != nil {
+		if derivatives, ok := d.Cache.Get(key); ok {
+			return derivatives, nil
+		}
+	}
+	src, format, err := decode(contents, d.MaxPixels)
+	if err != nil {
+		return nil, err
+	}
+	src = transform(src, orientations[Orientation(contents)])
+	derivatives := make([]Derivative, 0, len(d.Sizes))
+	for _, size := range d.Sizes {
+		width, height := fit(src.Bounds().Dx(), src.Bounds().Dy(), size.Width, size.Height)
+		encoded, mimeType, err := encode(resize(src, width, height), format, d.Quality)
+		if err != nil {
+			return nil, err
+		}
+		derivatives = append(derivatives, Derivative{Name: size.Name, Width: width, Height: height, MimeType: mimeType, Contents: encoded})
+	}
+	if d.Cache != nil {
+		d.Cache.Add(key, derivatives)
+	}
+	return derivatives, nil
+}
+
+// cacheKey identifies contents derived with d's sizes and quality
+func (d *Deriver) cacheKey(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	key := hex.EncodeToString(sum[:]) + " " + strconv.Itoa(d.Quality) + " " + strconv.Itoa(d.MaxPixels)
+	for _, size := range d.Sizes {
+		key += " " + size.String()
+	}
+	return key
+}
+
+// decode returns contents as an RGBA image and the name of its format,
+// rejecting images with more than maxPixels pixels, DefaultMaxPixels if 0
+func decode(contents []byte, maxPixels int) (*image.RGBA, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(contents))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(contents))
+	if err != nil {
+		return nil, "", fmt.Errorf("Error decoding %s image: %s", format, err)
+	}
+	return toRGBA(decoded), format, nil
+}
+
+// encode returns img as a PNG if format is png, keeping its transparency,
+// and otherwise as a JPEG of quality, DefaultQuality if 0, with its MIME type
+func encode(img *image.RGBA, format string, quality int) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if format == "png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", fmt.Errorf("Error encoding png: %s", err)
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if quality <= 0 {
+		quality = DefaultQuality
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, "", fmt.Errorf("Error encoding jpeg: %s", err)
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// fit returns the largest dimensions with the aspect ratio of width x
+// height fitting within maxWidth x maxHeight, without enlarging
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	if width*maxHeight > height*maxWidth {
+		return maxWidth, maxInt(1, (height*maxWidth+width/2)/width)
+	}
+	return maxInt(1, (width*maxHeight+height/2)/height), maxHeight
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// toRGBA returns img as an RGBA image with its origin at 0, 0
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok && bounds.Min == (image.Point{}) {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// contribution is the weight of one source pixel in a destination pixel
+type contribution struct {
+	index  int
+	weight float32
+}
+
+// areaWeights returns, for each of dstSize pixels, the source pixels it
+// covers when srcSize pixels are scaled to dstSize, weighted by how much
+// of each it covers
+func areaWeights(srcSize, dstSize int) [][]contribution {
+	weights := make([][]contribution, dstSize)
+	scale := float64(srcSize) / float64(dstSize)
+	for i := range weights {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		for j := int(start); j < srcSize && float64(j) < end; j++ {
+			covered := minFloat(end, float64(j+1)) - maxFloat(start, float64(j))
+			weights[i] = append(weights[i], contribution{index: j, weight: float32(covered / scale)})
+		}
+	}
+	return weights
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// resize scales src to width x height, averaging the source pixels each
+// destination pixel covers
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if srcWidth == width && srcHeight == height {
+		return src
+	}
+	// Scale rows, then columns
+	columns := areaWeights(srcWidth, width)
+	rows := areaWeights(srcHeight, height)
+	scaled := make([]float32, width*srcHeight*4)
+	for y := 0; y < srcHeight; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, contributions := range columns {
+			var c [4]float32
+			for _, w := range contributions {
+				p := row[w.index*4:]
+				c[0] += float32(p[0]) * w.weight
+				c[1] += float32(p[1]) * w.weight
+				c[2] += float32(p[2]) * w.weight
+				c[3] += float32(p[3]) * w.weight
+			}
+			copy(scaled[(y*width+x)*4:], c[:])
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, contributions := range rows {
+		for x := 0; x < width; x++ {
+			var c [4]float32
+			for _, w := range contributions {
+				p := scaled[(w.index*width+x)*4:]
+				c[0] += p[0] * w.weight
+				c[1] += p[1] * w.weight
+				c[2] += p[2] * w.weight
+				c[3] += p[3] * w.weight
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for i := range c {
+				d[i] = clamp(c[i])
+			}
+		}
+	}
+	return dst
+}
+
+func clamp(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
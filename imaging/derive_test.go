@@ -0,0 +1,159 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodeTestImage returns a width x height image, red on the left half and
+// blue on the right, in format
+func encodeTestImage(t *testing.T, width, height int, format string) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var parseSizesCases = []struct {
+	value    string
+	expected []Size
+	err      bool
+}{
+	{value: "", expected: nil},
+	{value: "thumbnail:150x100, web:1024x768", expected: []Size{{"thumbnail", 150, 100}, {"web", 1024, 768}}},
+	{value: "thumbnail", err: true},
+	{value: "thumbnail:150", err: true},
+	{value: "thumbnail:0x150", err: true},
+	{value: ":150x150", err: true},
+	{value: "web:10x10,web:20x20", err: true},
+}
+
+func TestParseSizes(t *testing.T) {
+	for _, c := range parseSizesCases {
+		sizes, err := ParseSizes(c.value)
+		if (err != nil) != c.err {
+			t.Errorf("Expected error %v for %q but got %v", c.err, c.value, err)
+			continue
+		}
+		if len(sizes) != len(c.expected) {
+			t.Errorf("Expected %v for %q but got %v", c.expected, c.value, sizes)
+			continue
+		}
+		for i := range sizes {
+			if sizes[i] != c.expected[i] {
+				t.Errorf("Expected %v for %q but got %v", c.expected, c.value, sizes)
+			}
+		}
+	}
+}
+
+var fitCases = []struct {
+	width, height, maxWidth, maxHeight int
+	expectedWidth, expectedHeight      int
+}{
+	{width: 4000, height: 3000, maxWidth: 150, maxHeight: 150, expectedWidth: 150, expectedHeight: 113},
+	{width: 3000, height: 4000, maxWidth: 150, maxHeight: 150, expectedWidth: 113, expectedHeight: 150},
+	{width: 100, height: 50, maxWidth: 150, maxHeight: 150, expectedWidth: 100, expectedHeight: 50},
+	{width: 10000, height: 10, maxWidth: 100, maxHeight: 100, expectedWidth: 100, expectedHeight: 1},
+}
+
+func TestFit(t *testing.T) {
+	for _, c := range fitCases {
+		width, height := fit(c.width, c.height, c.maxWidth, c.maxHeight)
+		if width != c.expectedWidth || height != c.expectedHeight {
+			t.Errorf("Expected %dx%d to fit %dx%d as %dx%d but got %dx%d", c.width, c.height, c.maxWidth, c.maxHeight, c.expectedWidth, c.expectedHeight, width, height)
+		}
+	}
+}
+
+var deriveCases = []struct {
+	format           string
+	expectedMimeType string
+}{
+	{format: "jpeg", expectedMimeType: "image/jpeg"},
+	{format: "png", expectedMimeType: "image/png"},
+}
+
+func TestDerive(t *testing.T) {
+	for _, c := range deriveCases {
+		deriver := &Deriver{Sizes: []Size{{"thumbnail", 40, 40}, {"web", 400, 400}}}
+		derivatives, err := deriver.Derive(encodeTestImage(t, 200, 100, c.format))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(derivatives) != 2 {
+			t.Fatalf("Expected 2 derivatives but got %d", len(derivatives))
+		}
+		thumbnail, web := derivatives[0], derivatives[1]
+		if thumbnail.Name != "thumbnail" || thumbnail.Width != 40 || thumbnail.Height != 20 || thumbnail.MimeType != c.expectedMimeType {
+			t.Errorf("Expected a 40x20 %s thumbnail but got %s %dx%d %s", c.expectedMimeType, thumbnail.Name, thumbnail.Width, thumbnail.Height, thumbnail.MimeType)
+		}
+		if web.Width != 200 || web.Height != 100 {
+			t.Errorf("Expected the web derivative not to be enlarged but got %dx%d", web.Width, web.Height)
+		}
+		img, _, err := image.Decode(bytes.NewReader(thumbnail.Contents))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r, _, b, _ := img.At(5, 10).RGBA(); r>>8 < 240 || b>>8 > 15 {
+			t.Errorf("Expected the left of the %s thumbnail to stay red but got %v", c.format, img.At(5, 10))
+		}
+		if r, _, b, _ := img.At(35, 10).RGBA(); b>>8 < 240 || r>>8 > 15 {
+			t.Errorf("Expected the right of the %s thumbnail to stay blue but got %v", c.format, img.At(35, 10))
+		}
+	}
+}
+
+func TestDeriveRejects(t *testing.T) {
+	deriver := &Deriver{Sizes: []Size{{"thumbnail", 40, 40}}}
+	if _, err := deriver.Derive([]byte("%PDF-1.4")); err != ErrUnsupported {
+		t.Errorf("Expected %v but got %v", ErrUnsupported, err)
+	}
+	deriver.MaxPixels = 100
+	if _, err := deriver.Derive(encodeTestImage(t, 20, 10, "png")); err != ErrTooLarge {
+		t.Errorf("Expected %v but got %v", ErrTooLarge, err)
+	}
+	if derivatives, err := (&Deriver{}).Derive([]byte("%PDF-1.4")); derivatives != nil || err != nil {
+		t.Errorf("Expected no derivatives without sizes but got %v, %v", derivatives, err)
+	}
+}
+
+func TestDeriveCaches(t *testing.T) {
+	cache := NewCache(1 << 20)
+	deriver := &Deriver{Sizes: []Size{{"thumbnail", 40, 40}}, Cache: cache}
+	contents := encodeTestImage(t, 200, 100, "jpeg")
+	first, _ := deriver.Derive(contents)
+	second, _ := deriver.Derive(contents)
+	if &first[0] != &second[0] {
+		t.Errorf("Expected the second derive to be served from the cache")
+	}
+	deriver.Sizes = []Size{{"thumbnail", 20, 20}}
+	third, _ := deriver.Derive(contents)
+	if third[0].Width != 20 {
+		t.Errorf("Expected changed sizes to be derived again but got width %d", third[0].Width)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("Expected 1 hit, 2 misses and 2 entries but got %+v", stats)
+	}
+}
@@ -0,0 +1,305 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// JPEG markers
+const (
+	markerSOI   = 0xd8
+	markerSOS   = 0xda
+	markerEOI   = 0xd9
+	markerRST0  = 0xd0
+	markerRST7  = 0xd7
+	markerAPP0  = 0xe0
+	markerAPP1  = 0xe1
+	markerAPP14 = 0xee
+	markerAPPF  = 0xef
+	markerCOM   = 0xfe
+)
+
+// exifHeader starts the payload of an APP1 segment holding Exif data
+var exifHeader = []byte("Exif\x00\x00")
+
+// tagOrientation is the Exif tag of the orientation of the image
+const tagOrientation = 0x0112
+
+var errInvalidJPEG = errors.New("Invalid JPEG")
+
+// segment is a JPEG marker segment, contents[start:end], whose payload
+// follows its two byte marker and two byte length
+type segment struct {
+	marker     byte
+	start, end int
+}
+
+func (s segment) payload(contents []byte) []byte {
+	return contents[s.start+4 : s.end]
+}
+
+// isJPEG reports whether contents starts like a JPEG
+func isJPEG(contents []byte) bool {
+	return len(contents) >= 2 && contents[0] == 0xff && contents[1] == markerSOI
+}
+
+// jpegSegments returns the marker segments of a JPEG which precede its scan
+// data, and the offset at which the scan starts
+func jpegSegments(contents []byte) ([]segment, int, error) {
+	if !isJPEG(contents) {
+		return nil, 0, errInvalidJPEG
+	}
+	var segments []segment
+	offset := 2
+	for {
+		if offset+4 > len(contents) || contents[offset] != 0xff {
+			return nil, 0, errInvalidJPEG
+		}
+		marker := contents[offset+1]
+		if marker == 0xff {
+			// Fill byte
+			offset++
+			continue
+		}
+		if marker == markerSOS {
+			return segments, offset, nil
+		}
+		length := int(binary.BigEndian.Uint16(contents[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(contents) {
+			return nil, 0, errInvalidJPEG
+		}
+		segments = append(segments, segment{marker: marker, start: offset, end: end})
+		offset = end
+	}
+}
+
+// jpegEnd returns the offset following the end of image marker of a JPEG
+// whose first scan starts at scan
+func jpegEnd(contents []byte, scan int) (int, error) {
+	offset := scan
+	for {
+		if offset+2 > len(contents) || contents[offset] != 0xff {
+			return 0, errInvalidJPEG
+		}
+		marker := contents[offset+1]
+		if marker == 0xff {
+			// Fill byte
+			offset++
+			continue
+		}
+		if marker == markerEOI {
+			return offset + 2, nil
+		}
+		if offset+4 > len(contents) {
+			return 0, errInvalidJPEG
+		}
+		length := int(binary.BigEndian.Uint16(contents[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(contents) {
+			return 0, errInvalidJPEG
+		}
+		offset = end
+		if marker != markerSOS {
+			continue
+		}
+		// Within the entropy coded data of a scan, 0xff is followed by a
+		// stuffed zero or a restart marker
+		for ; offset+1 < len(contents); offset++ {
+			if next := contents[offset+1]; contents[offset] == 0xff && next != 0 && (next < markerRST0 || next > markerRST7) {
+				break
+			}
+		}
+	}
+}
+
+// isExif reports whether s holds Exif data
+func (s segment) isExif(contents []byte) bool {
+	return s.marker == markerAPP1 && bytes.HasPrefix(s.payload(contents), exifHeader)
+}
+
+// tiff is the TIFF structure of Exif data, whose offsets are relative to
+// its start
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func parseTIFF(data []byte) (*tiff, bool) {
+	if len(data) < 8 {
+		return nil, false
+	}
+	t := &tiff{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, false
+	}
+	if t.order.Uint16(data[2:]) != 42 {
+		return nil, false
+	}
+	return t, true
+}
+
+// ifdEntry is a 12 byte entry of an image file directory at offset
+type ifdEntry struct {
+	offset int
+	tag    uint16
+	kind   uint16
+	count  uint32
+}
+
+// ifd returns the entries of the image file directory at offset, and the
+// offset of the next directory
+func (t *tiff) ifd(offset uint32) ([]ifdEntry, uint32, bool) {
+	if int64(offset)+2 > int64(len(t.data)) {
+		return nil, 0, false
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+	start := int(offset) + 2
+	if start+count*12+4 > len(t.data) {
+		return nil, 0, false
+	}
+	entries := make([]ifdEntry, count)
+	for i := range entries {
+		o := start + i*12
+		entries[i] = ifdEntry{
+			offset: o,
+			tag:    t.order.Uint16(t.data[o:]),
+			kind:   t.order.Uint16(t.data[o+2:]),
+			count:  t.order.Uint32(t.data[o+4:]),
+		}
+	}
+	return entries, t.order.Uint32(t.data[start+count*12:]), true
+}
+
+// ifd0 returns the entries of the first image file directory
+func (t *tiff) ifd0() ([]ifdEntry, bool) {
+	entries, _, ok := t.ifd(t.order.Uint32(t.data[4:]))
+	return entries, ok
+}
+
+// orientationEntry returns the orientation entry of the first directory,
+// if there is one
+func (t *tiff) orientationEntry() (ifdEntry, bool) {
+	entries, ok := t.ifd0()
+	if !ok {
+		return ifdEntry{}, false
+	}
+	for _, entry := range entries {
+		// A SHORT holding one value, stored in the entry itself
+		if entry.tag == tagOrientation && entry.kind == 3 && entry.count == 1 {
+			return entry, true
+		}
+	}
+	return ifdEntry{}, false
+}
+
+// orientationEntry returns the orientation entry of the Exif data in
+// contents, if there is one
+func orientationEntry(contents []byte) (*tiff, ifdEntry, bool) {
+	segments, _, err := jpegSegments(contents)
+	if err != nil {
+		return nil, ifdEntry{}, false
+	}
+	for _, s := range segments {
+		if !s.isExif(contents) {
+			continue
+		}
+		t, ok := parseTIFF(s.payload(contents)[len(exifHeader):])
+		if !ok {
+			return nil, ifdEntry{}, false
+		}
+		entry, ok := t.orientationEntry()
+		return t, entry, ok
+	}
+	return nil, ifdEntry{}, false
+}
+
+// Orientation returns the Exif orientation of a JPEG, from 1 for upright to
+// 8, or 1 if it has none
+func Orientation(contents []byte) int {
+	t, entry, ok := orientationEntry(contents)
+	if !ok {
+		return 1
+	}
+	orientation := int(t.order.Uint16(t.data[entry.offset+8:]))
+	if orientation < 1 || orientation > 8 {
+		return 1
+	}
+	return orientation
+}
+
+// metadataSegments returns copies of the application and comment segments
+// of a JPEG, with any Exif orientation reset to upright. The Adobe segment
+// is left out as it describes the color encoding of the original.
+func metadataSegments(contents []byte) [][]byte {
+	segments, _, err := jpegSegments(contents)
+	if err != nil {
+		return nil
+	}
+	t, entry, oriented := orientationEntry(contents)
+	var metadata [][]byte
+	exif := false
+	for _, s := range segments {
+		if (s.marker < markerAPP0 || s.marker > markerAPPF || s.marker == markerAPP14) && s.marker != markerCOM {
+			continue
+		}
+		m := append([]byte(nil), contents[s.start:s.end]...)
+		// orientationEntry reads the first Exif segment
+		if s.isExif(contents) && !exif {
+			exif = true
+			if oriented {
+				t.order.PutUint16(m[4+len(exifHeader)+entry.offset+8:], 1)
+			}
+		}
+		metadata = append(metadata, m)
+	}
+	return metadata
+}
+
+// orientationTIFF returns Exif data holding only the orientation recorded
+// in t, or nil if it records none or upright
+func orientationTIFF(t *tiff) []byte {
+	entry, ok := t.orientationEntry()
+	if !ok {
+		return nil
+	}
+	orientation := t.order.Uint16(t.data[entry.offset+8:])
+	if orientation <= 1 || orientation > 8 {
+		return nil
+	}
+	// The header, then a directory of one entry and no next directory
+	data := make([]byte, 8+2+12+4)
+	copy(data, t.data[:4])
+	t.order.PutUint32(data[4:], 8)
+	t.order.PutUint16(data[8:], 1)
+	copy(data[10:22], t.data[entry.offset:entry.offset+12])
+	return data
+}
+
+// appSegment returns a segment with marker and payload
+func appSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xff, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegments returns a JPEG encoded by image/jpeg with segments inserted
+// after its start of image marker
+func withSegments(encoded []byte, segments [][]byte) []byte {
+	if len(segments) == 0 || !isJPEG(encoded) {
+		return encoded
+	}
+	var buf bytes.Buffer
+	buf.Write(encoded[:2])
+	for _, s := range segments {
+		buf.Write(s)
+	}
+	buf.Write(encoded[2:])
+	return buf.Bytes()
+}
@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// exifSegment returns an APP1 segment whose Exif data has one entry, the
+// given orientation, stored in order
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := &bytes.Buffer{}
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(tiff, order, uint16(42))
+	binary.Write(tiff, order, uint32(8))
+	binary.Write(tiff, order, uint16(1))
+	binary.Write(tiff, order, []uint16{tagOrientation, 3})
+	binary.Write(tiff, order, uint32(1))
+	binary.Write(tiff, order, []uint16{orientation, 0})
+	binary.Write(tiff, order, uint32(0))
+	payload := append(append([]byte(nil), exifHeader...), tiff.Bytes()...)
+	segment := []byte{0xff, markerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withOrientation returns a JPEG with an Exif segment of orientation
+func withOrientation(jpeg []byte, order binary.ByteOrder, orientation uint16) []byte {
+	return withSegments(jpeg, [][]byte{exifSegment(order, orientation)})
+}
+
+var orientationCases = []struct {
+	contents func(t *testing.T) []byte
+	expected int
+}{
+	{
+		contents: func(t *testing.T) []byte {
+			return withOrientation(encodeTestImage(t, 4, 2, "jpeg"), binary.LittleEndian, 6)
+		},
+		expected: 6,
+	},
+	{
+		contents: func(t *testing.T) []byte {
+			return withOrientation(encodeTestImage(t, 4, 2, "jpeg"), binary.BigEndian, 8)
+		},
+		expected: 8,
+	},
+	{
+		contents: func(t *testing.T) []byte {
+			return withOrientation(encodeTestImage(t, 4, 2, "jpeg"), binary.BigEndian, 42)
+		},
+		expected: 1,
+	},
+	{
+		contents: func(t *testing.T) []byte { return encodeTestImage(t, 4, 2, "jpeg") },
+		expected: 1,
+	},
+	{
+		contents: func(t *testing.T) []byte { return encodeTestImage(t, 4, 2, "png") },
+		expected: 1,
+	},
+	{
+		contents: func(t *testing.T) []byte { return []byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff} },
+		expected: 1,
+	},
+}
+
+func TestOrientation(t *testing.T) {
+	for i, c := range orientationCases {
+		if orientation := Orientation(c.contents(t)); orientation != c.expected {
+			t.Errorf("%d: Expected orientation %d but got %d", i, c.expected, orientation)
+		}
+	}
+}
+
+func TestMetadataSegmentsResetOrientation(t *testing.T) {
+	contents := withOrientation(encodeTestImage(t, 4, 2, "jpeg"), binary.BigEndian, 6)
+	segments := metadataSegments(contents)
+	if len(segments) != 1 {
+		t.Fatalf("Expected the Exif segment but got %d segments", len(segments))
+	}
+	if orientation := Orientation(withSegments(encodeTestImage(t, 4, 2, "jpeg"), segments)); orientation != 1 {
+		t.Errorf("Expected orientation 1 but got %d", orientation)
+	}
+	if Orientation(contents) != 6 {
+		t.Errorf("Expected the original to keep its orientation")
+	}
+}
@@ -0,0 +1,569 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Exif tags and formats read from the Exif, GPS and first directories
+const (
+	tagExifIFD            = 0x8769
+	tagGPSIFD             = 0x8825
+	tagDateTime           = 0x0132
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+	tagGPSLatitudeRef     = 0x0001
+	tagGPSLatitude        = 0x0002
+	tagGPSLongitudeRef    = 0x0003
+	tagGPSLongitude       = 0x0004
+	tagGPSAltitudeRef     = 0x0005
+	tagGPSAltitude        = 0x0006
+	exifDateTimeLayout    = "2006:01:02 15:04:05"
+	exifTimeOffsetLayout  = "-07:00"
+)
+
+// Headers of APP segments holding XMP and IPTC metadata
+var (
+	xmpHeader         = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtendedHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	iptcHeader        = []byte("Photoshop 3.0\x00")
+)
+
+const markerAPP13 = 0xed
+
+var errInvalidExif = errors.New("Invalid Exif data")
+
+// typeSizes are the sizes in bytes of the TIFF field types
+var typeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// valueRange returns where the value of entry is, which is in the entry
+// itself when it fits in four bytes
+func (t *tiff) valueRange(entry ifdEntry) (int, int, bool) {
+	size := int64(typeSizes[entry.kind]) * int64(entry.count)
+	if size <= 4 {
+		return entry.offset + 8, entry.offset + 8 + int(size), true
+	}
+	start := int64(t.order.Uint32(t.data[entry.offset+8:]))
+	if start+size > int64(len(t.data)) {
+		return 0, 0, false
+	}
+	return int(start), int(start + size), true
+}
+
+func (t *tiff) value(entry ifdEntry) ([]byte, bool) {
+	start, end, ok := t.valueRange(entry)
+	if !ok {
+		return nil, false
+	}
+	return t.data[start:end], true
+}
+
+// ascii returns the value of an ASCII entry without its terminating NULs
+func (t *tiff) ascii(entry ifdEntry) string {
+	value, ok := t.value(entry)
+	if !ok || entry.kind != 2 {
+		return ""
+	}
+	return strings.TrimRight(string(value), "\x00 ")
+}
+
+// rationals returns the values of a RATIONAL entry
+func (t *tiff) rationals(entry ifdEntry) []float64 {
+	value, ok := t.value(entry)
+	if !ok || entry.kind != 5 {
+		return nil
+	}
+	var rationals []float64
+	for i := 0; i+8 <= len(value); i += 8 {
+		denominator := t.order.Uint32(value[i+4:])
+		if denominator == 0 {
+			return nil
+		}
+		rationals = append(rationals, float64(t.order.Uint32(value[i:]))/float64(denominator))
+	}
+	return rationals
+}
+
+// subIFD returns the entries of the directory entry points to
+func (t *tiff) subIFD(entry ifdEntry) ([]ifdEntry, bool) {
+	if entry.kind != 4 && entry.kind != 13 || entry.count != 1 {
+		return nil, false
+	}
+	entries, _, ok := t.ifd(t.order.Uint32(t.data[entry.offset+8:]))
+	return entries, ok
+}
+
+func findEntry(entries []ifdEntry, tag uint16) (ifdEntry, bool) {
+	for _, entry := range entries {
+		if entry.tag == tag {
+			return entry, true
+		}
+	}
+	return ifdEntry{}, false
+}
+
+// exifTIFF returns the Exif data of a JPEG or PNG, if it has any
+func exifTIFF(contents []byte) (*tiff, bool) {
+	if isPNG(contents) {
+		return pngExifTIFF(contents)
+	}
+	segments, _, err := jpegSegments(contents)
+	if err != nil {
+		return nil, false
+	}
+	for _, s := range segments {
+		if s.isExif(contents) {
+			return parseTIFF(s.payload(contents)[len(exifHeader):])
+		}
+	}
+	return nil, false
+}
+
+// GPSPosition is where a photo was taken
+type GPSPosition struct {
+	// Latitude and Longitude are in degrees, negative south and west
+	Latitude  float64
+	Longitude float64
+	// Altitude is in meters, negative below sea level
+	Altitude float64
+}
+
+// Metadata is when and where a photo was taken, read from its Exif data
+type Metadata struct {
+	// CaptureTime is when the photo was taken. Without CaptureTimeOffset it
+	// is the camera's local time, in UTC only for want of a zone.
+	CaptureTime time.Time
+	// CaptureTimeOffset reports whether the camera recorded its offset from
+	// UTC, which CaptureTime is then in
+	CaptureTimeOffset bool
+	// GPS is nil if the photo has no position
+	GPS *GPSPosition
+}
+
+// ExtractMetadata returns the capture time and GPS position of a JPEG or
+// PNG, or nil if it records neither
+func ExtractMetadata(contents []byte) *Metadata {
+	t, ok := exifTIFF(contents)
+	if !ok {
+		return nil
+	}
+	ifd0, ok := t.ifd0()
+	if !ok {
+		return nil
+	}
+	metadata := &Metadata{}
+	if entry, ok := findEntry(ifd0, tagDateTime); ok {
+		metadata.CaptureTime, _ = time.Parse(exifDateTimeLayout, t.ascii(entry))
+	}
+	if pointer, ok := findEntry(ifd0, tagExifIFD); ok {
+		if exif, ok := t.subIFD(pointer); ok {
+			if entry, ok := findEntry(exif, tagDateTimeOriginal); ok {
+				if captured, err := time.Parse(exifDateTimeLayout, t.ascii(entry)); err == nil {
+					metadata.CaptureTime = captured
+				}
+			}
+			if entry, ok := findEntry(exif, tagOffsetTimeOriginal); ok && !metadata.CaptureTime.IsZero() {
+				if offset, err := time.Parse(exifTimeOffsetLayout, t.ascii(entry)); err == nil {
+					_, seconds := offset.Zone()
+					c := metadata.CaptureTime
+					metadata.CaptureTime = time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), 0, time.FixedZone("", seconds))
+					metadata.CaptureTimeOffset = true
+				}
+			}
+		}
+	}
+	if pointer, ok := findEntry(ifd0, tagGPSIFD); ok {
+		if gps, ok := t.subIFD(pointer); ok {
+			metadata.GPS = t.gpsPosition(gps)
+		}
+	}
+	if metadata.CaptureTime.IsZero() && metadata.GPS == nil {
+		return nil
+	}
+	return metadata
+}
+
+// gpsPosition reads the position in a GPS directory, if it has one
+func (t *tiff) gpsPosition(gps []ifdEntry) *GPSPosition {
+	coordinate := func(refTag, tag uint16, negative string) (float64, bool) {
+		ref, ok := findEntry(gps, refTag)
+		if !ok {
+			return 0, false
+		}
+		entry, ok := findEntry(gps, tag)
+		if !ok {
+			return 0, false
+		}
+		dms := t.rationals(entry)
+		if len(dms) != 3 {
+			return 0, false
+		}
+		degrees := dms[0] + dms[1]/60 + dms[2]/3600
+		if t.ascii(ref) == negative {
+			degrees = -degrees
+		}
+		return degrees, true
+	}
+	latitude, ok := coordinate(tagGPSLatitudeRef, tagGPSLatitude, "S")
+	if !ok {
+		return nil
+	}
+	longitude, ok := coordinate(tagGPSLongitudeRef, tagGPSLongitude, "W")
+	if !ok {
+		return nil
+	}
+	position := &GPSPosition{Latitude: latitude, Longitude: longitude}
+	if entry, ok := findEntry(gps, tagGPSAltitude); ok {
+		if altitude := t.rationals(entry); len(altitude) == 1 {
+			position.Altitude = altitude[0]
+			if ref, ok := findEntry(gps, tagGPSAltitudeRef); ok {
+				if value, ok := t.value(ref); ok && len(value) == 1 && value[0] == 1 {
+					position.Altitude = -position.Altitude
+				}
+			}
+		}
+	}
+	return position
+}
+
+// zero clears data[start:end]
+func zero(data []byte, start, end int) {
+	for i := start; i < end; i++ {
+		data[i] = 0
+	}
+}
+
+// removeEntries removes the entries of the directory at offset for which
+// remove is true, clearing their values so they cannot be recovered. The
+// directory is compacted in place, so the offsets of everything else in the
+// Exif data stay valid. Removed entries pointing to sub-directories have
+// them cleared too.
+func (t *tiff) removeEntries(offset uint32, remove func(tag uint16) bool) bool {
+	entries, next, ok := t.ifd(offset)
+	if !ok {
+		return false
+	}
+	var kept [][]byte
+	for _, entry := range entries {
+		raw := t.data[entry.offset : entry.offset+12]
+		if !remove(entry.tag) {
+			kept = append(kept, append([]byte(nil), raw...))
+			continue
+		}
+		if entry.tag == tagGPSIFD || entry.tag == tagExifIFD {
+			t.clearIFD(t.order.Uint32(t.data[entry.offset+8:]))
+		}
+		if start, end, ok := t.valueRange(entry); ok {
+			zero(t.data, start, end)
+		}
+	}
+	if len(kept) == len(entries) {
+		return true
+	}
+	start := int(offset)
+	end := start + 2 + len(entries)*12 + 4
+	zero(t.data, start, end)
+	t.order.PutUint16(t.data[start:], uint16(len(kept)))
+	for i, raw := range kept {
+		copy(t.data[start+2+i*12:], raw)
+	}
+	t.order.PutUint32(t.data[start+2+len(kept)*12:], next)
+	return true
+}
+
+// clearIFD clears the directory at offset and the values of its entries
+func (t *tiff) clearIFD(offset uint32) {
+	entries, _, ok := t.ifd(offset)
+	if !ok {
+		return
+	}
+	for _, entry := range entries {
+		if start, end, ok := t.valueRange(entry); ok {
+			zero(t.data, start, end)
+		}
+	}
+	zero(t.data, int(offset), int(offset)+2+len(entries)*12+4)
+}
+
+// removeTags removes the tags in the Exif data from its first directory
+// and its Exif directory
+func (t *tiff) removeTags(tags map[uint16]bool) bool {
+	ifd0, ok := t.ifd0()
+	if !ok {
+		return false
+	}
+	if pointer, ok := findEntry(ifd0, tagExifIFD); ok && !tags[tagExifIFD] {
+		if !t.removeEntries(t.order.Uint32(t.data[pointer.offset+8:]), func(tag uint16) bool { return tags[tag] }) {
+			return false
+		}
+	}
+	return t.removeEntries(t.order.Uint32(t.data[4:]), func(tag uint16) bool { return tags[tag] })
+}
+
+// Segments of metadata which can be removed whole
+const (
+	segmentExif    = "exif"
+	segmentXMP     = "xmp"
+	segmentIPTC    = "iptc"
+	segmentComment = "comment"
+)
+
+// xmpProperties are the local names of the XMP properties holding the same
+// data as Exif tags, whichever namespace prefix they are written with. A
+// name ending in * stands for every property starting with it.
+var xmpProperties = map[uint16][]string{
+	tagGPSIFD:             {"GPS*"},
+	0x010f:                {"Make"},
+	0x0110:                {"Model"},
+	0x013c:                {"HostComputer"},
+	0x927c:                {"MakerNote"},
+	0xa420:                {"ImageUniqueID"},
+	0xa430:                {"CameraOwnerName", "OwnerName"},
+	0xa431:                {"BodySerialNumber", "SerialNumber"},
+	0xa433:                {"LensMake"},
+	0xa434:                {"LensModel", "Lens"},
+	0xa435:                {"LensSerialNumber"},
+	tagOrientation:        {"Orientation"},
+	tagDateTime:           {"DateTime", "ModifyDate"},
+	tagDateTimeOriginal:   {"DateTimeOriginal", "DateCreated"},
+	0x9004:                {"DateTimeDigitized", "CreateDate"},
+	0x9010:                {"OffsetTime"},
+	tagOffsetTimeOriginal: {"OffsetTimeOriginal"},
+	0x9012:                {"OffsetTimeDigitized"},
+	0x9290:                {"SubSecTime"},
+	0x9291:                {"SubSecTimeOriginal"},
+	0x9292:                {"SubSecTimeDigitized"},
+}
+
+// iptcDatasets are the IPTC datasets of record 2 holding the same data as
+// Exif tags
+var iptcDatasets = map[uint16][]byte{
+	// DateCreated and TimeCreated
+	tagDateTimeOriginal: {55, 60},
+	// DigitalCreationDate and DigitalCreationTime
+	0x9004: {62, 63},
+}
+
+// iptcResource is the Photoshop image resource holding IPTC datasets
+const iptcResource = 0x0404
+
+// xmpHolds reports whether the XMP packet xmp has any of the properties
+// holding the same data as tags
+func xmpHolds(xmp []byte, tags map[uint16]bool) bool {
+	for tag := range tags {
+		for _, name := range xmpProperties[tag] {
+			prefix := strings.HasSuffix(name, "*")
+			needle := []byte(":" + strings.TrimSuffix(name, "*"))
+			for rest := xmp; ; {
+				i := bytes.Index(rest, needle)
+				if i < 0 {
+					break
+				}
+				rest = rest[i+len(needle):]
+				// The name must not be the start of a longer one
+				if prefix || len(rest) == 0 || !isNameByte(rest[0]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isNameByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '-' || b == '.'
+}
+
+// iptcHolds reports whether the Photoshop image resources of an IPTC
+// segment hold any of the datasets holding the same data as tags. Resources
+// which cannot be read are taken to hold them.
+func iptcHolds(resources []byte, tags map[uint16]bool) bool {
+	datasets := iptcDatasetsOf(tags)
+	if len(datasets) == 0 {
+		return false
+	}
+	for len(resources) > 0 {
+		// Signature, id and a padded Pascal string name
+		if len(resources) < 7 || string(resources[:4]) != "8BIM" {
+			return true
+		}
+		id := binary.BigEndian.Uint16(resources[4:])
+		nameLength := (1 + int(resources[6]) + 1) &^ 1
+		if len(resources) < 6+nameLength+4 {
+			return true
+		}
+		size := int(binary.BigEndian.Uint32(resources[6+nameLength:]))
+		start := 6 + nameLength + 4
+		if size < 0 || size > len(resources)-start {
+			return true
+		}
+		if id == iptcResource && iimHolds(resources[start:start+size], datasets) {
+			return true
+		}
+		// Data is padded to an even size, except perhaps the last
+		next := start + size + size&1
+		if next > len(resources) {
+			next = len(resources)
+		}
+		resources = resources[next:]
+	}
+	return false
+}
+
+// iptcDatasetsOf returns the IPTC datasets holding the same data as tags
+func iptcDatasetsOf(tags map[uint16]bool) map[byte]bool {
+	datasets := make(map[byte]bool)
+	for tag := range tags {
+		for _, dataset := range iptcDatasets[tag] {
+			datasets[dataset] = true
+		}
+	}
+	return datasets
+}
+
+// iimHolds reports whether IPTC-IIM data holds any of datasets of record 2.
+// Data which cannot be read is taken to hold them.
+func iimHolds(iim []byte, datasets map[byte]bool) bool {
+	for len(iim) > 0 {
+		if len(iim) < 5 || iim[0] != 0x1c {
+			return true
+		}
+		if iim[1] == 2 && datasets[iim[2]] {
+			return true
+		}
+		size := int(binary.BigEndian.Uint16(iim[3:]))
+		if size&0x8000 != 0 {
+			// Extended datasets are not used for these records
+			return true
+		}
+		if size > len(iim)-5 {
+			return true
+		}
+		iim = iim[5+size:]
+	}
+	return false
+}
+
+// segmentKind returns which kind of metadata s holds, if any
+func (s segment) kind(contents []byte) string {
+	payload := s.payload(contents)
+	switch {
+	case s.isExif(contents):
+		return segmentExif
+	case s.marker == markerAPP1 && (bytes.HasPrefix(payload, xmpHeader) || bytes.HasPrefix(payload, xmpExtendedHeader)):
+		return segmentXMP
+	case s.marker == markerAPP13 && bytes.HasPrefix(payload, iptcHeader):
+		return segmentIPTC
+	case s.marker == markerCOM:
+		return segmentComment
+	}
+	return ""
+}
+
+// unstrippedSignatures start images which can hold metadata that strip
+// cannot remove: GIF and TIFF, and WebP following its RIFF header
+var unstrippedSignatures = [][]byte{[]byte("GIF8"), []byte("II*\x00"), []byte("MM\x00*")}
+
+// isUnstripped reports whether contents is an image strip cannot remove
+// metadata from
+func isUnstripped(contents []byte) bool {
+	if len(contents) >= 12 && string(contents[:4]) == "RIFF" && string(contents[8:12]) == "WEBP" {
+		return true
+	}
+	for _, signature := range unstrippedSignatures {
+		if bytes.HasPrefix(contents, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// extendedXMP returns the data of the extended XMP segments in segments
+// joined, so that no property is split between them
+func extendedXMP(contents []byte, segments []segment) []byte {
+	var xmp []byte
+	for _, s := range segments {
+		payload := s.payload(contents)
+		// The header is followed by a GUID, the full length and an offset
+		if s.marker == markerAPP1 && bytes.HasPrefix(payload, xmpExtendedHeader) && len(payload) >= len(xmpExtendedHeader)+40 {
+			xmp = append(xmp, payload[len(xmpExtendedHeader)+40:]...)
+		}
+	}
+	return xmp
+}
+
+// strip returns a copy of a JPEG or PNG without the kinds of segments named
+// in segments, without tags in its Exif data, and without XMP or IPTC
+// holding the same data as tags, or anything following the image. The
+// orientation is kept when all Exif data is removed, unless tags names it.
+// Other images are rejected
+// with ErrUnsupported if anything is to be removed, and contents which are
+// not an image, such as PDFs, are returned unchanged.
+func strip(contents []byte, segments map[string]bool, tags map[uint16]bool) ([]byte, error) {
+	if len(segments) == 0 && len(tags) == 0 {
+		return contents, nil
+	}
+	if isPNG(contents) {
+		return stripPNG(contents, segments, tags)
+	}
+	if isUnstripped(contents) {
+		return nil, ErrUnsupported
+	}
+	if !isJPEG(contents) {
+		return contents, nil
+	}
+	all, scan, err := jpegSegments(contents)
+	if err != nil {
+		return nil, err
+	}
+	extendedXMPHolds := xmpHolds(extendedXMP(contents, all), tags)
+	var buf bytes.Buffer
+	buf.Grow(len(contents))
+	buf.Write(contents[:2])
+	oriented := false
+	for _, s := range all {
+		kind := s.kind(contents)
+		payload := s.payload(contents)
+		if segments[kind] {
+			// Without its orientation the image would be stored sideways
+			if kind == segmentExif && !tags[tagOrientation] && !oriented {
+				if t, ok := parseTIFF(payload[len(exifHeader):]); ok {
+					if orientation := orientationTIFF(t); orientation != nil {
+						buf.Write(appSegment(markerAPP1, append(append([]byte(nil), exifHeader...), orientation...)))
+						oriented = true
+					}
+				}
+			}
+			continue
+		}
+		// XMP and IPTC cannot be edited, so they are removed whole if they
+		// hold the same data as tags
+		switch {
+		case kind == segmentXMP && bytes.HasPrefix(payload, xmpExtendedHeader) && extendedXMPHolds,
+			kind == segmentXMP && bytes.HasPrefix(payload, xmpHeader) && xmpHolds(payload[len(xmpHeader):], tags),
+			kind == segmentIPTC && iptcHolds(payload[len(iptcHeader):], tags):
+			continue
+		}
+		if kind != segmentExif || len(tags) == 0 {
+			buf.Write(contents[s.start:s.end])
+			continue
+		}
+		exif := append([]byte(nil), contents[s.start:s.end]...)
+		t, ok := parseTIFF(exif[4+len(exifHeader):])
+		if !ok || !t.removeTags(tags) {
+			return nil, errInvalidExif
+		}
+		buf.Write(exif)
+	}
+	// Anything following the end of the image, such as the secondary images
+	// of an MPF or the video of a motion photo, carries its own metadata
+	end, err := jpegEnd(contents, scan)
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(contents[scan:end])
+	return buf.Bytes(), nil
+}
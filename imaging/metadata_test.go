@@ -0,0 +1,346 @@
+package imaging
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// testEntry is an Exif entry of kind whose value is encoded in value
+type testEntry struct {
+	tag   uint16
+	kind  uint16
+	value []byte
+}
+
+func asciiEntry(tag uint16, value string) testEntry {
+	return testEntry{tag: tag, kind: 2, value: append([]byte(value), 0)}
+}
+
+func rationalEntry(order binary.ByteOrder, tag uint16, values ...uint32) testEntry {
+	value := make([]byte, len(values)*4)
+	for i, v := range values {
+		order.PutUint32(value[i*4:], v)
+	}
+	return testEntry{tag: tag, kind: 5, value: value}
+}
+
+// buildTIFF lays out ifd0, followed by the exif and gps directories it
+// points to if they are set, and then the values too large for an entry
+func buildTIFF(order binary.ByteOrder, ifd0, exif, gps []testEntry) []byte {
+	ifdSize := func(entries int) int { return 2 + entries*12 + 4 }
+	ifd0Entries := len(ifd0)
+	if exif != nil {
+		ifd0Entries++
+	}
+	if gps != nil {
+		ifd0Entries++
+	}
+	exifOffset := 8 + ifdSize(ifd0Entries)
+	gpsOffset := exifOffset + ifdSize(len(exif))
+	dataOffset := gpsOffset + ifdSize(len(gps))
+	var data []byte
+	pointer := func(tag uint16, offset int) testEntry {
+		value := make([]byte, 4)
+		order.PutUint32(value, uint32(offset))
+		return testEntry{tag: tag, kind: 4, value: value}
+	}
+	if exif != nil {
+		ifd0 = append(ifd0, pointer(tagExifIFD, exifOffset))
+	}
+	if gps != nil {
+		ifd0 = append(ifd0, pointer(tagGPSIFD, gpsOffset))
+	}
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	for _, entries := range [][]testEntry{ifd0, exif, gps} {
+		if entries == nil {
+			continue
+		}
+		binary.Write(&buf, order, uint16(len(entries)))
+		for _, entry := range entries {
+			binary.Write(&buf, order, entry.tag)
+			binary.Write(&buf, order, entry.kind)
+			binary.Write(&buf, order, uint32(len(entry.value)/typeSizes[entry.kind]))
+			value := make([]byte, 4)
+			if len(entry.value) <= 4 {
+				copy(value, entry.value)
+			} else {
+				order.PutUint32(value, uint32(dataOffset+len(data)))
+				data = append(data, entry.value...)
+			}
+			buf.Write(value)
+		}
+		binary.Write(&buf, order, uint32(0))
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// photoTIFF returns the Exif data of a phone photo taken at
+// 40°26'46"N 79°58'56"W
+func photoTIFF(order binary.ByteOrder) []byte {
+	tiff := buildTIFF(order,
+		[]testEntry{asciiEntry(0x010f, "Acme"), asciiEntry(0x0110, "Phone 12"), {tag: tagOrientation, kind: 3, value: make([]byte, 2)}},
+		[]testEntry{asciiEntry(tagDateTimeOriginal, "2017:03:08 13:06:40"), asciiEntry(tagOffsetTimeOriginal, "-06:00"), asciiEntry(0xa431, "SN123456789")},
+		[]testEntry{asciiEntry(tagGPSLatitudeRef, "N"), rationalEntry(order, tagGPSLatitude, 40, 1, 26, 1, 46, 1), asciiEntry(tagGPSLongitudeRef, "W"), rationalEntry(order, tagGPSLongitude, 79, 1, 58, 1, 56, 1), {tag: tagGPSAltitudeRef, kind: 1, value: []byte{0}}, rationalEntry(order, tagGPSAltitude, 2735, 10)},
+	)
+	// The orientation is the third entry of ifd0
+	order.PutUint16(tiff[8+2+2*12+8:], 1)
+	return tiff
+}
+
+// photo returns a JPEG with the Exif data of photoTIFF, and XMP, IPTC and
+// comment segments
+func photo(t *testing.T, order binary.ByteOrder) []byte {
+	tiff := photoTIFF(order)
+	return withSegments(encodeTestImage(t, 8, 8, "jpeg"), [][]byte{
+		appSegment(markerAPP1, append(append([]byte(nil), exifHeader...), tiff...)),
+		appSegment(markerAPP1, append(append([]byte(nil), xmpHeader...), "<x:xmpmeta/>"...)),
+		appSegment(markerAPP13, append(append([]byte(nil), iptcHeader...), "8BIM"...)),
+		appSegment(markerCOM, []byte("taken by J. Doe")),
+	})
+}
+
+// pngPhoto returns a PNG with the Exif data of photoTIFF in an eXIf chunk,
+// XMP and IPTC in text chunks, and a comment
+func pngPhoto(t *testing.T, order binary.ByteOrder) []byte {
+	contents := encodeTestImage(t, 8, 8, "png")
+	// IHDR follows the signature
+	ihdrEnd := len(pngSignature) + 12 + 13
+	var buf bytes.Buffer
+	buf.Write(contents[:ihdrEnd])
+	buf.Write(pngChunk("eXIf", photoTIFF(order)))
+	buf.Write(pngChunk("iTXt", []byte(xmpKeyword+"\x00\x00\x00\x00\x00<x:xmpmeta/>")))
+	buf.Write(pngChunk("zTXt", []byte("Raw profile type iptc\x00\x008BIM")))
+	buf.Write(pngChunk("tEXt", []byte("Comment\x00taken by J. Doe")))
+	buf.Write(contents[ihdrEnd:])
+	return buf.Bytes()
+}
+
+func TestExtractMetadata(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		metadata := ExtractMetadata(photo(t, order))
+		if metadata == nil {
+			t.Fatalf("Expected metadata")
+		}
+		expected := time.Date(2017, 3, 8, 19, 6, 40, 0, time.UTC)
+		if !metadata.CaptureTime.Equal(expected) || !metadata.CaptureTimeOffset {
+			t.Errorf("Expected capture time %v with an offset but got %v, %v", expected, metadata.CaptureTime, metadata.CaptureTimeOffset)
+		}
+		gps := metadata.GPS
+		if gps == nil || math.Abs(gps.Latitude-40.446111) > 1e-5 || math.Abs(gps.Longitude+79.982222) > 1e-5 || gps.Altitude != 273.5 {
+			t.Errorf("Expected 40.446111, -79.982222 at 273.5m but got %+v", gps)
+		}
+	}
+	if metadata := ExtractMetadata(pngPhoto(t, binary.LittleEndian)); metadata == nil || metadata.GPS == nil || metadata.CaptureTime.IsZero() {
+		t.Errorf("Expected the capture time and position of a png but got %+v", metadata)
+	}
+	if metadata := ExtractMetadata(encodeTestImage(t, 8, 8, "jpeg")); metadata != nil {
+		t.Errorf("Expected no metadata but got %+v", metadata)
+	}
+}
+
+var stripCases = []struct {
+	segments         map[string]bool
+	tags             map[uint16]bool
+	expectedRemoved  []string
+	expectedRetained []string
+}{
+	{
+		tags:             map[uint16]bool{tagGPSIFD: true, 0x010f: true, 0x0110: true, 0xa431: true},
+		expectedRemoved:  []string{"Acme", "Phone 12", "SN123456789"},
+		expectedRetained: []string{"2017:03:08 13:06:40", "<x:xmpmeta/>", "8BIM", "J. Doe"},
+	},
+	{
+		segments:         map[string]bool{segmentXMP: true, segmentIPTC: true, segmentComment: true},
+		expectedRemoved:  []string{"<x:xmpmeta/>", "8BIM", "J. Doe"},
+		expectedRetained: []string{"Acme", "SN123456789"},
+	},
+	{
+		segments:        map[string]bool{segmentExif: true},
+		expectedRemoved: []string{"Acme", "SN123456789", "Exif"},
+	},
+}
+
+func TestStrip(t *testing.T) {
+	for i, c := range stripCases {
+		for _, contents := range [][]byte{photo(t, binary.BigEndian), pngPhoto(t, binary.BigEndian)} {
+			stripped, err := strip(contents, c.segments, c.tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, removed := range c.expectedRemoved {
+				if bytes.Contains(stripped, []byte(removed)) {
+					t.Errorf("%d: Expected %q to be removed", i, removed)
+				}
+			}
+			for _, retained := range c.expectedRetained {
+				if !bytes.Contains(stripped, []byte(retained)) {
+					t.Errorf("%d: Expected %q to be retained", i, retained)
+				}
+			}
+			if c.tags[tagGPSIFD] {
+				if metadata := ExtractMetadata(stripped); metadata == nil || metadata.GPS != nil || metadata.CaptureTime.IsZero() {
+					t.Errorf("%d: Expected the capture time without a position but got %+v", i, metadata)
+				}
+				if orientation := Orientation(stripped); orientation != 1 {
+					t.Errorf("%d: Expected the orientation to be kept but got %d", i, orientation)
+				}
+			}
+			if _, _, err := decode(stripped, 0); err != nil {
+				t.Errorf("%d: Expected the stripped image to decode but got %v", i, err)
+			}
+		}
+	}
+}
+
+var stripOrientationCases = []struct {
+	remove              []string
+	expectedOrientation uint16
+}{
+	{remove: []string{"exif"}, expectedOrientation: 6},
+	{remove: []string{"all"}, expectedOrientation: 6},
+	{remove: []string{"all", "exif:0x0112"}, expectedOrientation: 0},
+}
+
+func TestStripKeepsOrientation(t *testing.T) {
+	tiff := photoTIFF(binary.BigEndian)
+	binary.BigEndian.PutUint16(tiff[8+2+2*12+8:], 6)
+	jpeg := withSegments(encodeTestImage(t, 8, 8, "jpeg"), [][]byte{appSegment(markerAPP1, append(append([]byte(nil), exifHeader...), tiff...))})
+	png := encodeTestImage(t, 8, 8, "png")
+	ihdrEnd := len(pngSignature) + 12 + 13
+	png = append(append(append([]byte(nil), png[:ihdrEnd]...), pngChunk("eXIf", tiff)...), png[ihdrEnd:]...)
+	for _, c := range stripOrientationCases {
+		for _, contents := range [][]byte{jpeg, png} {
+			stripped, err := (&MetadataRule{Remove: c.remove}).Strip(contents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(stripped, []byte("Acme")) || ExtractMetadata(stripped) != nil {
+				t.Errorf("%v: Expected all other Exif data to be removed", c.remove)
+			}
+			var orientation uint16
+			if t, ok := exifTIFF(stripped); ok {
+				if entry, ok := t.orientationEntry(); ok {
+					orientation = t.order.Uint16(t.data[entry.offset+8:])
+				}
+			}
+			if orientation != c.expectedOrientation {
+				t.Errorf("%v: Expected orientation %d but got %d", c.remove, c.expectedOrientation, orientation)
+			}
+			if _, _, err := decode(stripped, 0); err != nil {
+				t.Errorf("%v: Expected the stripped image to decode but got %v", c.remove, err)
+			}
+		}
+	}
+}
+
+func TestStripRemovesTrailer(t *testing.T) {
+	// A motion photo or MPF appends data with its own Exif after the image
+	image := encodeTestImage(t, 8, 8, "jpeg")
+	contents := append(append([]byte(nil), image...), photo(t, binary.BigEndian)...)
+	stripped, err := strip(contents, map[string]bool{segmentComment: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stripped, image) {
+		t.Errorf("Expected only the first image of %d bytes to be kept but got %d bytes", len(image), len(stripped))
+	}
+}
+
+func TestStripLeavesOtherContents(t *testing.T) {
+	contents := encodeTestImage(t, 8, 8, "png")
+	stripped, err := strip(contents, map[string]bool{segmentExif: true}, nil)
+	if err != nil || !bytes.Equal(stripped, contents) {
+		t.Errorf("Expected a png without metadata to be unchanged but got %v", err)
+	}
+	pdf := []byte("%PDF-1.4")
+	if stripped, err := strip(pdf, map[string]bool{segmentExif: true}, nil); err != nil || !bytes.Equal(stripped, pdf) {
+		t.Errorf("Expected a pdf to be unchanged but got %v", err)
+	}
+	if _, err := strip([]byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff}, map[string]bool{segmentExif: true}, nil); err == nil {
+		t.Errorf("Expected a corrupt JPEG to be rejected")
+	}
+	jpeg := encodeTestImage(t, 8, 8, "jpeg")
+	if _, err := strip(jpeg[:len(jpeg)-2], map[string]bool{segmentExif: true}, nil); err == nil {
+		t.Errorf("Expected a JPEG without an end of image to be rejected")
+	}
+	if _, err := strip(contents[:len(contents)-4], map[string]bool{segmentExif: true}, nil); err == nil {
+		t.Errorf("Expected a truncated PNG to be rejected")
+	}
+	for _, image := range []string{"GIF89a", "RIFF\x00\x00\x00\x00WEBPVP8 ", "II*\x00\x08\x00\x00\x00"} {
+		if _, err := strip([]byte(image), map[string]bool{segmentExif: true}, nil); err != ErrUnsupported {
+			t.Errorf("Expected %q to be rejected as unsupported but got %v", image, err)
+		}
+		if _, err := strip([]byte(image), nil, nil); err != nil {
+			t.Errorf("Expected %q to be accepted when nothing is removed but got %v", image, err)
+		}
+	}
+}
+
+// locatedXMP describes where and with what a photo was taken
+const locatedXMP = `<x:xmpmeta><rdf:RDF><rdf:Description exif:GPSLatitude="40,26.7667N" exif:GPSLongitude="79,58.9333W" tiff:Model="Phone 12"/></rdf:RDF></x:xmpmeta>`
+
+// datedIPTC returns the IPTC segment payload of a photo created on 8 March 2017
+func datedIPTC() []byte {
+	iim := append([]byte{0x1c, 2, 5, 0, 5}, "Title"...)
+	iim = append(append(iim, 0x1c, 2, 55, 0, 8), "20170308"...)
+	resource := append([]byte("8BIM\x04\x04\x00\x00"), 0, 0, 0, byte(len(iim)))
+	return append(append(append([]byte(nil), iptcHeader...), resource...), iim...)
+}
+
+var relatedMetadataCases = []struct {
+	remove       []string
+	expectedXMP  bool
+	expectedIPTC bool
+}{
+	{remove: []string{"gps"}, expectedXMP: false, expectedIPTC: true},
+	{remove: []string{"device"}, expectedXMP: false, expectedIPTC: true},
+	{remove: []string{"datetime"}, expectedXMP: true, expectedIPTC: false},
+	{remove: []string{"exif:0x010f"}, expectedXMP: true, expectedIPTC: true},
+	{remove: []string{"exif", "gps"}, expectedXMP: false, expectedIPTC: true},
+}
+
+func TestStripRelatedXMPAndIPTC(t *testing.T) {
+	contents := withSegments(encodeTestImage(t, 8, 8, "jpeg"), [][]byte{
+		appSegment(markerAPP1, append(append([]byte(nil), xmpHeader...), locatedXMP...)),
+		appSegment(markerAPP13, datedIPTC()),
+	})
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write([]byte(locatedXMP))
+	w.Close()
+	png := encodeTestImage(t, 8, 8, "png")
+	ihdrEnd := len(pngSignature) + 12 + 13
+	png = append(append(append([]byte(nil), png[:ihdrEnd]...), pngChunk("iTXt", append([]byte(xmpKeyword+"\x00\x01\x00\x00\x00"), compressed.Bytes()...))...), png[ihdrEnd:]...)
+	for _, c := range relatedMetadataCases {
+		rule := &MetadataRule{Remove: c.remove}
+		stripped, err := rule.Strip(contents)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xmp := bytes.Contains(stripped, []byte("GPSLatitude")); xmp != c.expectedXMP {
+			t.Errorf("%v: Expected XMP kept %v but got %v", c.remove, c.expectedXMP, xmp)
+		}
+		if iptc := bytes.Contains(stripped, []byte("20170308")); iptc != c.expectedIPTC {
+			t.Errorf("%v: Expected IPTC kept %v but got %v", c.remove, c.expectedIPTC, iptc)
+		}
+		stripped, err = rule.Strip(png)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xmp := bytes.Contains(stripped, []byte(xmpKeyword)); xmp != c.expectedXMP {
+			t.Errorf("%v: Expected compressed XMP kept %v but got %v", c.remove, c.expectedXMP, xmp)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package imaging
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// pngSignature starts every PNG
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Keywords of PNG text chunks holding XMP, and the prefix of those holding
+// metadata as ImageMagick writes it, followed by its kind
+const (
+	xmpKeyword        = "XML:com.adobe.xmp"
+	rawProfileKeyword = "raw profile type "
+)
+
+var errInvalidPNG = errors.New("Invalid PNG")
+
+// chunk is a PNG chunk, contents[start:end], whose data follows its four
+// byte length and four byte type and precedes its four byte CRC
+type chunk struct {
+	chunkType  string
+	start, end int
+}
+
+func (c chunk) data(contents []byte) []byte {
+	return contents[c.start+8 : c.end-4]
+}
+
+// pngChunk returns a chunk of chunkType holding data
+func pngChunk(chunkType string, data []byte) []byte {
+	c := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(c, uint32(len(data)))
+	c = append(append(c, chunkType...), data...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(c[4:]))
+	return append(c, crc...)
+}
+
+// isPNG reports whether contents starts like a PNG
+func isPNG(contents []byte) bool {
+	return bytes.HasPrefix(contents, pngSignature)
+}
+
+// pngChunks returns the chunks of a PNG up to and including IEND
+func pngChunks(contents []byte) ([]chunk, error) {
+	if !isPNG(contents) {
+		return nil, errInvalidPNG
+	}
+	var chunks []chunk
+	offset := len(pngSignature)
+	for {
+		if offset+12 > len(contents) {
+			return nil, errInvalidPNG
+		}
+		length := binary.BigEndian.Uint32(contents[offset:])
+		if uint64(length) > uint64(len(contents)-offset-12) {
+			return nil, errInvalidPNG
+		}
+		c := chunk{chunkType: string(contents[offset+4 : offset+8]), start: offset, end: offset + 12 + int(length)}
+		chunks = append(chunks, c)
+		if c.chunkType == "IEND" {
+			return chunks, nil
+		}
+		offset = c.end
+	}
+}
+
+// kind returns which kind of metadata c holds, if any. Text chunks hold
+// XMP or raw profiles by keyword, and are otherwise comments.
+func (c chunk) kind(contents []byte) string {
+	switch c.chunkType {
+	case "eXIf":
+		return segmentExif
+	case "tEXt", "zTXt", "iTXt":
+	default:
+		return ""
+	}
+	keyword := c.data(contents)
+	if i := bytes.IndexByte(keyword, 0); i >= 0 {
+		keyword = keyword[:i]
+	}
+	if string(keyword) == xmpKeyword {
+		return segmentXMP
+	}
+	switch strings.ToLower(string(keyword)) {
+	case rawProfileKeyword + "xmp":
+		return segmentXMP
+	case rawProfileKeyword + "iptc", rawProfileKeyword + "8bim":
+		return segmentIPTC
+	case rawProfileKeyword + "exif", rawProfileKeyword + "app1":
+		return segmentExif
+	}
+	return segmentComment
+}
+
+// maxXMPBytes bounds the XMP decompressed from a chunk
+const maxXMPBytes = 1 << 24
+
+// xmpHolds reports whether c, a chunk holding XMP, has any of the
+// properties holding the same data as tags. Raw profiles, which are hex
+// encoded, and chunks which cannot be read are taken to have them.
+func (c chunk) xmpHolds(contents []byte, tags map[uint16]bool) bool {
+	if len(tags) == 0 {
+		return false
+	}
+	data := c.data(contents)
+	if c.chunkType != "iTXt" || !bytes.HasPrefix(data, []byte(xmpKeyword+"\x00")) {
+		return true
+	}
+	// The keyword is followed by the compression flag and method, and the
+	// language tag and translated keyword
+	text := data[len(xmpKeyword)+1:]
+	if len(text) < 2 {
+		return true
+	}
+	compressed := text[0] == 1
+	text = text[2:]
+	for i := 0; i < 2; i++ {
+		end := bytes.IndexByte(text, 0)
+		if end < 0 {
+			return true
+		}
+		text = text[end+1:]
+	}
+	if compressed {
+		r, err := zlib.NewReader(bytes.NewReader(text))
+		if err != nil {
+			return true
+		}
+		text, err = ioutil.ReadAll(io.LimitReader(r, maxXMPBytes+1))
+		if err != nil || len(text) > maxXMPBytes {
+			return true
+		}
+	}
+	return xmpHolds(text, tags)
+}
+
+// pngExifTIFF returns the Exif data of a PNG, if it has an eXIf chunk
+func pngExifTIFF(contents []byte) (*tiff, bool) {
+	chunks, err := pngChunks(contents)
+	if err != nil {
+		return nil, false
+	}
+	for _, c := range chunks {
+		if c.chunkType == "eXIf" {
+			return parseTIFF(c.data(contents))
+		}
+	}
+	return nil, false
+}
+
+// stripPNG returns a copy of a PNG without the kinds of chunks named in
+// segments, without tags in its eXIf chunk, and without XMP or IPTC holding
+// the same data as tags. The orientation is kept when all Exif data is
+// removed, unless tags names it. Exif held in text chunks
+// cannot be edited, so it is removed whole when any of its tags are, as is
+// anything following IEND.
+func stripPNG(contents []byte, segments map[string]bool, tags map[uint16]bool) ([]byte, error) {
+	chunks, err := pngChunks(contents)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(contents))
+	buf.Write(pngSignature)
+	oriented := false
+	for _, c := range chunks {
+		kind := c.kind(contents)
+		if segments[kind] && c.chunkType == "eXIf" && !tags[tagOrientation] && !oriented {
+			if t, ok := parseTIFF(c.data(contents)); ok {
+				if orientation := orientationTIFF(t); orientation != nil {
+					buf.Write(pngChunk("eXIf", orientation))
+					oriented = true
+				}
+			}
+		}
+		switch {
+		case segments[kind],
+			kind == segmentExif && len(tags) > 0 && c.chunkType != "eXIf",
+			kind == segmentXMP && c.xmpHolds(contents, tags),
+			// IPTC is only held in raw profiles, which are hex encoded
+			kind == segmentIPTC && len(iptcDatasetsOf(tags)) > 0:
+			continue
+		}
+		if kind != segmentExif || len(tags) == 0 {
+			buf.Write(contents[c.start:c.end])
+			continue
+		}
+		exif := append([]byte(nil), contents[c.start:c.end]...)
+		t, ok := parseTIFF(exif[8 : len(exif)-4])
+		if !ok || !t.removeTags(tags) {
+			return nil, errInvalidExif
+		}
+		binary.BigEndian.PutUint32(exif[len(exif)-4:], crc32.ChecksumIEEE(exif[4:len(exif)-4]))
+		buf.Write(exif)
+	}
+	return buf.Bytes(), nil
+}
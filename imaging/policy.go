@@ -0,0 +1,226 @@
+package imaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// metadataSegmentNames names the metadata removed a segment at a time, and
+// metadataTagGroups the Exif tags in each named group. Removing a tag also
+// removes XMP and IPTC holding the same data.
+var (
+	metadataSegmentNames = map[string][]string{
+		segmentExif:    {segmentExif},
+		segmentXMP:     {segmentXMP},
+		segmentIPTC:    {segmentIPTC},
+		segmentComment: {segmentComment},
+		"all":          {segmentExif, segmentXMP, segmentIPTC, segmentComment},
+	}
+	metadataTagGroups = map[string][]uint16{
+		// The GPS directory, removed with everything in it
+		"gps": {tagGPSIFD},
+		// Make, Model, HostComputer, MakerNote, ImageUniqueID,
+		// CameraOwnerName, BodySerialNumber, LensMake, LensModel and
+		// LensSerialNumber
+		"device": {0x010f, 0x0110, 0x013c, 0x927c, 0xa420, 0xa430, 0xa431, 0xa433, 0xa434, 0xa435},
+		// DateTime, DateTimeOriginal, DateTimeDigitized, their offsets
+		// and their sub-second digits
+		"datetime": {tagDateTime, tagDateTimeOriginal, 0x9004, 0x9010, tagOffsetTimeOriginal, 0x9012, 0x9290, 0x9291, 0x9292},
+	}
+)
+
+// MetadataRule chooses the metadata removed from photos uploaded for the
+// departments it lists. Remove and Retain name metadata as:
+//
+//   - exif, xmp, iptc or comment for all metadata of that kind
+//   - all for every kind
+//   - gps, device or datetime for those groups of Exif tags
+//   - exif:0x010f for a single Exif tag by number
+//
+// Metadata named by Retain is kept even if Remove names it, so remove
+// [device] with retain [exif:0x010f] keeps the camera make. XMP and IPTC
+// holding the same data as the Exif tags removed, such as exif:GPSLatitude
+// or tiff:Model, are removed whole, as they cannot be edited. Removing
+// exif or all keeps the orientation, so that photos are not stored
+// sideways, unless Remove also names exif:0x0112. Metadata is
+// removed from JPEGs and PNGs, whose text chunks other than XMP and raw
+// profiles are comments. Other images are rejected if the rule removes
+// anything.
+type MetadataRule struct {
+	Deptcodes []string `json:"deptcodes" yaml:"deptcodes"`
+	Remove    []string `json:"remove" yaml:"remove"`
+	Retain    []string `json:"retain" yaml:"retain"`
+	// Extract returns the capture time and GPS position of photos,
+	// read before any metadata is removed
+	Extract bool `json:"extract" yaml:"extract"`
+}
+
+// expand returns the segment kinds and Exif tags named by names
+func expand(names []string) (map[string]bool, map[uint16]bool, error) {
+	segments, tags := make(map[string]bool), make(map[uint16]bool)
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if kinds, ok := metadataSegmentNames[name]; ok {
+			for _, kind := range kinds {
+				segments[kind] = true
+			}
+			continue
+		}
+		if group, ok := metadataTagGroups[name]; ok {
+			for _, tag := range group {
+				tags[tag] = true
+			}
+			continue
+		}
+		if strings.HasPrefix(name, "exif:") {
+			tag, err := strconv.ParseUint(strings.TrimPrefix(name, "exif:"), 0, 16)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%q is not an Exif tag number", name)
+			}
+			tags[uint16(tag)] = true
+			continue
+		}
+		return nil, nil, fmt.Errorf("Unknown metadata %q", name)
+	}
+	return segments, tags, nil
+}
+
+// compile returns the segment kinds and Exif tags the rule removes
+func (r *MetadataRule) compile() (map[string]bool, map[uint16]bool, error) {
+	segments, tags, err := expand(r.Remove)
+	if err != nil {
+		return nil, nil, err
+	}
+	retainedSegments, retainedTags, err := expand(r.Retain)
+	if err != nil {
+		return nil, nil, err
+	}
+	for kind := range retainedSegments {
+		delete(segments, kind)
+	}
+	for tag := range retainedTags {
+		delete(tags, tag)
+	}
+	if segments[segmentExif] && len(retainedTags) > 0 {
+		return nil, nil, fmt.Errorf("Exif tags cannot be retained when all Exif data is removed")
+	}
+	// The tags are kept when all Exif data is removed, to remove the XMP and
+	// IPTC holding the same data
+	return segments, tags, nil
+}
+
+// Strip returns contents without the metadata the rule removes
+func (r *MetadataRule) Strip(contents []byte) ([]byte, error) {
+	segments, tags, err := r.compile()
+	if err != nil {
+		return nil, err
+	}
+	return strip(contents, segments, tags)
+}
+
+// MetadataPolicy is the contents of a metadata policy file. The first rule
+// listing a department applies to it, and Default to the rest.
+type MetadataPolicy struct {
+	Default     MetadataRule   `json:"default" yaml:"default"`
+	Departments []MetadataRule `json:"departments" yaml:"departments"`
+}
+
+// Validate checks that every rule names known metadata and that department
+// rules list their departments
+func (p *MetadataPolicy) Validate() error {
+	if _, _, err := p.Default.compile(); err != nil {
+		return fmt.Errorf("Default: %s", err)
+	}
+	for i, rule := range p.Departments {
+		if len(rule.Deptcodes) == 0 {
+			return fmt.Errorf("Department rule %d: deptcodes are required", i)
+		}
+		if _, _, err := rule.compile(); err != nil {
+			return fmt.Errorf("Department rule %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// Rule returns the rule applying to deptcode
+func (p *MetadataPolicy) Rule(deptcode string) *MetadataRule {
+	for i, rule := range p.Departments {
+		for _, d := range rule.Deptcodes {
+			if d == deptcode {
+				return &p.Departments[i]
+			}
+		}
+	}
+	return &p.Default
+}
+
+// Sanitize returns contents without the metadata deptcode may not store
+// and, if its rule extracts them, the capture time and GPS position read
+// from contents beforehand
+func (p *MetadataPolicy) Sanitize(contents []byte, deptcode string) ([]byte, *Metadata, error) {
+	rule := p.Rule(deptcode)
+	var metadata *Metadata
+	if rule.Extract {
+		metadata = ExtractMetadata(contents)
+	}
+	stripped, err := rule.Strip(contents)
+	return stripped, metadata, err
+}
+
+// ParseMetadataPolicy decodes a metadata policy from YAML if filename has a
+// .yaml or .yml extension and from JSON otherwise
+func ParseMetadataPolicy(filename string, contents []byte) (*MetadataPolicy, error) {
+	p := &MetadataPolicy{}
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(contents, p)
+	default:
+		decoder := json.NewDecoder(strings.NewReader(string(contents)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing metadata policy file %s: %s", filename, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid metadata policy file %s: %s", filename, err)
+	}
+	return p, nil
+}
+
+// LoadMetadataPolicy reads the metadata policy in filename
+func LoadMetadataPolicy(filename string) (*MetadataPolicy, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMetadataPolicy(filename, contents)
+}
+
+// AtomicMetadataPolicy is a MetadataPolicy that can be replaced while
+// uploads are sanitized
+type AtomicMetadataPolicy struct {
+	current atomic.Value
+}
+
+// Set replaces the policy in use. A nil policy removes no metadata.
+func (a *AtomicMetadataPolicy) Set(p *MetadataPolicy) {
+	a.current.Store(p)
+}
+
+// Sanitize sanitizes contents with the current policy
+func (a *AtomicMetadataPolicy) Sanitize(contents []byte, deptcode string) ([]byte, *Metadata, error) {
+	p, _ := a.current.Load().(*MetadataPolicy)
+	if p == nil {
+		return contents, nil, nil
+	}
+	return p.Sanitize(contents, deptcode)
+}
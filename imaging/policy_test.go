@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+var parseMetadataPolicyCases = []struct {
+	filename string
+	contents string
+	valid    bool
+}{
+	{"metadata.yaml", "default:\n  remove: [gps]\n  extract: true\ndepartments:\n  - deptcodes: [\"07\"]\n    remove: [all]\n", true},
+	{"metadata.json", `{"default": {"remove": ["device", "exif:0xa431"], "retain": ["exif:0x010f"]}}`, true},
+	{"metadata.yaml", "default:\n  remove: [location]\n", false},
+	{"metadata.yaml", "default:\n  remove: [exif:camera]\n", false},
+	{"metadata.yaml", "departments:\n  - remove: [gps]\n", false},
+	{"metadata.yaml", "default:\n  remove: [exif]\n  retain: [exif:0x010f]\n", false},
+	{"metadata.json", `{"default": {"strip": ["gps"]}}`, false},
+}
+
+func TestParseMetadataPolicy(t *testing.T) {
+	for _, c := range parseMetadataPolicyCases {
+		_, err := ParseMetadataPolicy(c.filename, []byte(c.contents))
+		if (err == nil) != c.valid {
+			t.Errorf("Expected %q to be valid %v but got %v", c.contents, c.valid, err)
+		}
+	}
+}
+
+func TestMetadataPolicySanitize(t *testing.T) {
+	p, err := ParseMetadataPolicy("metadata.yaml", []byte("default:\n  remove: [gps, device]\n  retain: [exif:0x010f]\n  extract: true\ndepartments:\n  - deptcodes: [\"07\", \"09\"]\n    remove: [all]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := photo(t, binary.LittleEndian)
+
+	sanitized, metadata, err := p.Sanitize(contents, "01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata == nil || metadata.GPS == nil || metadata.CaptureTime.IsZero() {
+		t.Errorf("Expected the capture time and position to be extracted but got %+v", metadata)
+	}
+	if !bytes.Contains(sanitized, []byte("Acme")) || bytes.Contains(sanitized, []byte("Phone 12")) || ExtractMetadata(sanitized).GPS != nil {
+		t.Errorf("Expected the position and model to be removed and the make retained")
+	}
+
+	sanitized, metadata, err = p.Sanitize(contents, "09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata != nil {
+		t.Errorf("Expected nothing to be extracted but got %+v", metadata)
+	}
+	if segments, _, _ := jpegSegments(sanitized); len(segments) != len(mustSegments(t, encodeTestImage(t, 8, 8, "jpeg"))) {
+		t.Errorf("Expected every metadata segment to be removed but got %d segments", len(segments))
+	}
+}
+
+func mustSegments(t *testing.T, contents []byte) []segment {
+	segments, _, err := jpegSegments(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segments
+}
+
+func TestAtomicMetadataPolicy(t *testing.T) {
+	contents := photo(t, binary.BigEndian)
+	a := &AtomicMetadataPolicy{}
+	sanitized, metadata, err := a.Sanitize(contents, "01")
+	if err != nil || metadata != nil || !bytes.Equal(sanitized, contents) {
+		t.Errorf("Expected no policy to leave contents unchanged but got %v", err)
+	}
+	a.Set(&MetadataPolicy{Default: MetadataRule{Remove: []string{"exif"}}})
+	sanitized, _, err = a.Sanitize(contents, "01")
+	if err != nil || ExtractMetadata(sanitized) != nil {
+		t.Errorf("Expected the Exif data to be removed but got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// orientation describes how to turn an image upright: mirror it
+// horizontally if flip is set, then rotate it clockwise by degrees
+type orientation struct {
+	flip    bool
+	degrees int
+}
+
+// orientations turn an image with each Exif orientation upright
+var orientations = map[int]orientation{
+	1: {},
+	2: {flip: true},
+	3: {degrees: 180},
+	4: {flip: true, degrees: 180},
+	5: {flip: true, degrees: 270},
+	6: {degrees: 90},
+	7: {flip: true, degrees: 90},
+	8: {degrees: 270},
+}
+
+// transform returns src mirrored and rotated as o describes
+func transform(src *image.RGBA, o orientation) *image.RGBA {
+	if o == (orientation{}) {
+		return src
+	}
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if o.degrees == 90 || o.degrees == 270 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			// The pixel of the mirrored image rotated to x, y
+			var sx, sy int
+			switch o.degrees {
+			case 90:
+				sx, sy = y, height-1-x
+			case 180:
+				sx, sy = width-1-x, height-1-y
+			case 270:
+				sx, sy = width-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			if o.flip {
+				sx = width - 1 - sx
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+	return dst
+}
+
+// Rotator turns JPEG and PNG images upright and rotates them, re-encoding
+// them in their own format. The metadata of a JPEG is kept, with its
+// orientation reset to upright.
+type Rotator struct {
+	// Quality is the JPEG quality from 1 to 100, DefaultQuality if 0
+	Quality int
+	// MaxPixels bounds the images decoded, DefaultMaxPixels if 0
+	MaxPixels int
+}
+
+// Orient returns contents turned upright according to its Exif orientation,
+// and the degrees it was rotated clockwise by. Images which are already
+// upright are returned unchanged.
+func (r *Rotator) Orient(contents []byte) ([]byte, int, error) {
+	o := orientations[Orientation(contents)]
+	if o == (orientation{}) {
+		return contents, 0, nil
+	}
+	oriented, err := r.apply(contents, o)
+	return oriented, o.degrees, err
+}
+
+// Rotate returns contents turned upright according to its Exif orientation
+// and then rotated clockwise by degrees, which must be 90, 180 or 270, and
+// the degrees it was rotated by in all
+func (r *Rotator) Rotate(contents []byte, degrees int) ([]byte, int, error) {
+	if degrees != 90 && degrees != 180 && degrees != 270 {
+		return nil, 0, fmt.Errorf("Rotation must be 90, 180 or 270 degrees, got %d", degrees)
+	}
+	o := orientations[Orientation(contents)]
+	o.degrees = (o.degrees + degrees) % 360
+	rotated, err := r.apply(contents, o)
+	return rotated, o.degrees, err
+}
+
+func (r *Rotator) apply(contents []byte, o orientation) ([]byte, error) {
+	src, format, err := decode(contents, r.MaxPixels)
+	if err != nil {
+		return nil, err
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, ErrUnsupported
+	}
+	encoded, _, err := encode(transform(src, o), format, r.Quality)
+	if err != nil {
+		return nil, err
+	}
+	if format == "jpeg" {
+		encoded = withSegments(encoded, metadataSegments(contents))
+	}
+	return encoded, nil
+}
@@ -0,0 +1,122 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// cornerImage returns a 3x2 image which is black but for a white top left
+func cornerImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	img.Set(0, 0, color.White)
+	return img
+}
+
+var transformCases = []struct {
+	orientation                   int
+	expectedWidth, expectedHeight int
+	// expectedX, expectedY is where the top left pixel ends up
+	expectedX, expectedY int
+}{
+	{orientation: 1, expectedWidth: 3, expectedHeight: 2, expectedX: 0, expectedY: 0},
+	{orientation: 2, expectedWidth: 3, expectedHeight: 2, expectedX: 2, expectedY: 0},
+	{orientation: 3, expectedWidth: 3, expectedHeight: 2, expectedX: 2, expectedY: 1},
+	{orientation: 4, expectedWidth: 3, expectedHeight: 2, expectedX: 0, expectedY: 1},
+	{orientation: 5, expectedWidth: 2, expectedHeight: 3, expectedX: 0, expectedY: 0},
+	{orientation: 6, expectedWidth: 2, expectedHeight: 3, expectedX: 1, expectedY: 0},
+	{orientation: 7, expectedWidth: 2, expectedHeight: 3, expectedX: 1, expectedY: 2},
+	{orientation: 8, expectedWidth: 2, expectedHeight: 3, expectedX: 0, expectedY: 2},
+}
+
+func TestTransform(t *testing.T) {
+	for _, c := range transformCases {
+		dst := transform(cornerImage(), orientations[c.orientation])
+		if dst.Bounds().Dx() != c.expectedWidth || dst.Bounds().Dy() != c.expectedHeight {
+			t.Errorf("Orientation %d: Expected %dx%d but got %v", c.orientation, c.expectedWidth, c.expectedHeight, dst.Bounds())
+			continue
+		}
+		if r, _, _, _ := dst.At(c.expectedX, c.expectedY).RGBA(); r != 0xffff {
+			t.Errorf("Orientation %d: Expected the white pixel at %d,%d", c.orientation, c.expectedX, c.expectedY)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	rotator := &Rotator{}
+	contents := withOrientation(encodeTestImage(t, 40, 20, "jpeg"), binary.LittleEndian, 6)
+	oriented, degrees, err := rotator.Orient(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if degrees != 90 {
+		t.Errorf("Expected 90 degrees but got %d", degrees)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(oriented))
+	if err != nil || config.Width != 20 || config.Height != 40 {
+		t.Errorf("Expected a 20x40 image but got %+v, %v", config, err)
+	}
+	if orientation := Orientation(oriented); orientation != 1 {
+		t.Errorf("Expected the oriented image to be marked upright but got %d", orientation)
+	}
+
+	upright := encodeTestImage(t, 40, 20, "jpeg")
+	if unchanged, degrees, err := rotator.Orient(upright); !bytes.Equal(unchanged, upright) || degrees != 0 || err != nil {
+		t.Errorf("Expected an upright image to be unchanged but got %d degrees, %v", degrees, err)
+	}
+}
+
+var rotateCases = []struct {
+	orientation     uint16
+	degrees         int
+	expectedDegrees int
+	expectedWidth   int
+	err             bool
+}{
+	{orientation: 1, degrees: 90, expectedDegrees: 90, expectedWidth: 20},
+	{orientation: 1, degrees: 180, expectedDegrees: 180, expectedWidth: 40},
+	{orientation: 6, degrees: 270, expectedDegrees: 0, expectedWidth: 40},
+	{orientation: 8, degrees: 180, expectedDegrees: 90, expectedWidth: 20},
+	{orientation: 1, degrees: 45, err: true},
+}
+
+func TestRotate(t *testing.T) {
+	rotator := &Rotator{}
+	for _, c := range rotateCases {
+		contents := withOrientation(encodeTestImage(t, 40, 20, "jpeg"), binary.BigEndian, c.orientation)
+		rotated, degrees, err := rotator.Rotate(contents, c.degrees)
+		if (err != nil) != c.err {
+			t.Errorf("Expected error %v rotating by %d but got %v", c.err, c.degrees, err)
+			continue
+		}
+		if c.err {
+			continue
+		}
+		config, _, _ := image.DecodeConfig(bytes.NewReader(rotated))
+		if degrees != c.expectedDegrees || config.Width != c.expectedWidth {
+			t.Errorf("Expected orientation %d rotated by %d to turn %d degrees to width %d but got %d degrees to width %d", c.orientation, c.degrees, c.expectedDegrees, c.expectedWidth, degrees, config.Width)
+		}
+	}
+}
+
+func TestRotatePNG(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, cornerImage())
+	rotated, _, err := (&Rotator{}).Rotate(buf.Bytes(), 90)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(rotated))
+	if err != nil || format != "png" {
+		t.Fatalf("Expected a png but got %s, %v", format, err)
+	}
+	if r, _, _, _ := img.At(1, 0).RGBA(); r != 0xffff {
+		t.Errorf("Expected the white pixel at the top right")
+	}
+}
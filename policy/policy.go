@@ -0,0 +1,225 @@
+// Package policy decides which contractors and departments an authenticated
+// caller may upload content for.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+)
+
+// Rule grants the callers it matches access to a set of contractors and
+// departments. A rule matches a caller by subject or by role. An empty
+// Contractorids or Deptcodes list places no restriction on that field.
+type Rule struct {
+	Subjects      []string `json:"subjects" yaml:"subjects"`
+	Roles         []string `json:"roles" yaml:"roles"`
+	Contractorids []int64  `json:"contractorids" yaml:"contractorids"`
+	Deptcodes     []string `json:"deptcodes" yaml:"deptcodes"`
+}
+
+// Policy is the contents of a policy file
+type Policy struct {
+	// AdminRoles bypass all rules
+	AdminRoles []string `json:"admin_roles" yaml:"admin_roles"`
+	Rules      []Rule   `json:"rules" yaml:"rules"`
+}
+
+// Validate checks that every rule applies to at least one caller
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Subjects) == 0 && len(rule.Roles) == 0 {
+			return fmt.Errorf("Rule %d: subjects or roles are required", i)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesCaller(identity *auth.Identity) bool {
+	if containsString(r.Subjects, identity.Subject) {
+		return true
+	}
+	for _, role := range r.Roles {
+		if identity.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize returns nil if identity may upload for contractorID and
+// deptcode, or an error giving the reason it may not
+func (p *Policy) Authorize(identity *auth.Identity, contractorID int64, deptcode string) error {
+	if identity == nil {
+		return errors.New("caller is not authenticated")
+	}
+	for _, role := range p.AdminRoles {
+		if identity.HasRole(role) {
+			return nil
+		}
+	}
+	matched := false
+	contractorAllowed := false
+	for _, rule := range p.Rules {
+		if !rule.matchesCaller(identity) {
+			continue
+		}
+		matched = true
+		if len(rule.Contractorids) > 0 && !containsInt64(rule.Contractorids, contractorID) {
+			continue
+		}
+		contractorAllowed = true
+		if len(rule.Deptcodes) > 0 && !containsString(rule.Deptcodes, deptcode) {
+			continue
+		}
+		return nil
+	}
+	switch {
+	case !matched:
+		return fmt.Errorf("no policy rule applies to caller %q", identity.Subject)
+	case !contractorAllowed:
+		return fmt.Errorf("caller %q may not upload for contractor %d", identity.Subject, contractorID)
+	default:
+		return fmt.Errorf("caller %q may not upload for contractor %d in department %q", identity.Subject, contractorID, deptcode)
+	}
+}
+
+// Parse decodes a policy from YAML if filename has a .yaml or .yml
+// extension and from JSON otherwise
+func Parse(filename string, contents []byte) (*Policy, error) {
+	p := &Policy{}
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(contents, p)
+	default:
+		decoder := json.NewDecoder(strings.NewReader(string(contents)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing policy file %s: %s", filename, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid policy file %s: %s", filename, err)
+	}
+	return p, nil
+}
+
+// Logger receives the messages of Watch
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// stdLogger writes messages through the standard log package
+type stdLogger struct{}
+
+func (stdLogger) Infof(format string, args ...interface{})  { log.Printf(format, args...) }
+func (stdLogger) Errorf(format string, args ...interface{}) { log.Printf(format, args...) }
+
+// Engine authorizes callers against a policy file, reloading it when it changes
+type Engine struct {
+	// Logger receives the messages of Watch, which go to the standard log
+	// package by default. It must be set before Watch is called.
+	Logger Logger
+
+	filename string
+	current  atomic.Value
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewEngine loads the policy in filename
+func NewEngine(filename string) (*Engine, error) {
+	e := &Engine{Logger: stdLogger{}, filename: filename}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Policy returns the policy currently in force
+func (e *Engine) Policy() *Policy {
+	return e.current.Load().(*Policy)
+}
+
+// Authorize checks identity against the policy currently in force
+func (e *Engine) Authorize(identity *auth.Identity, contractorID int64, deptcode string) error {
+	return e.Policy().Authorize(identity, contractorID, deptcode)
+}
+
+// Reload reads the policy file if it has changed since it was last loaded.
+// An invalid file leaves the current policy in force.
+func (e *Engine) Reload() (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	info, err := os.Stat(e.filename)
+	if err != nil {
+		return false, err
+	}
+	if !e.modTime.IsZero() && info.ModTime().Equal(e.modTime) {
+		return false, nil
+	}
+	contents, err := ioutil.ReadFile(e.filename)
+	if err != nil {
+		return false, err
+	}
+	p, err := Parse(e.filename, contents)
+	if err != nil {
+		return false, err
+	}
+	e.current.Store(p)
+	e.modTime = info.ModTime()
+	return true, nil
+}
+
+// Watch checks the policy file for changes every interval until stop is closed
+func (e *Engine) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := e.Reload()
+			if err != nil {
+				e.Logger.Errorf("Failed to reload policy file %s, keeping current policy: %v", e.filename, err)
+			} else if reloaded {
+				e.Logger.Infof("Reloaded policy file %s", e.filename)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+)
+
+const testPolicyYAML = `
+admin_roles: [admin]
+rules:
+  - subjects: [portal]
+    contractorids: [72494, 72495]
+    deptcodes: ["01"]
+  - roles: [inspector]
+    deptcodes: ["02"]
+`
+
+var authorizeCases = []struct {
+	identity     *auth.Identity
+	contractorID int64
+	deptcode     string
+	allowed      bool
+}{
+	{identity: &auth.Identity{Subject: "portal"}, contractorID: 72494, deptcode: "01", allowed: true},
+	{identity: &auth.Identity{Subject: "portal"}, contractorID: 72495, deptcode: "01", allowed: true},
+	{identity: &auth.Identity{Subject: "portal"}, contractorID: 10000, deptcode: "01", allowed: false},
+	{identity: &auth.Identity{Subject: "portal"}, contractorID: 72494, deptcode: "02", allowed: false},
+	{identity: &auth.Identity{Subject: "field", Roles: []string{"inspector"}}, contractorID: 10000, deptcode: "02", allowed: true},
+	{identity: &auth.Identity{Subject: "field", Roles: []string{"inspector"}}, contractorID: 10000, deptcode: "01", allowed: false},
+	{identity: &auth.Identity{Subject: "ops", Roles: []string{"admin"}}, contractorID: 10000, deptcode: "09", allowed: true},
+	{identity: &auth.Identity{Subject: "stranger"}, contractorID: 72494, deptcode: "01", allowed: false},
+	{identity: nil, contractorID: 72494, deptcode: "01", allowed: false},
+}
+
+func TestAuthorize(t *testing.T) {
+	p, err := Parse("policy.yaml", []byte(testPolicyYAML))
+	if err != nil {
+		t.Fatalf("Error parsing policy: %v", err)
+	}
+	for _, c := range authorizeCases {
+		err := p.Authorize(c.identity, c.contractorID, c.deptcode)
+		if (err == nil) != c.allowed {
+			t.Errorf("%+v %d %s: expected allowed %v but got %v", c.identity, c.contractorID, c.deptcode, c.allowed, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse("policy.json", []byte(`{"admin_roles":["admin"],"rules":[{"subjects":["portal"],"contractorids":[72494]}]}`))
+	if err != nil {
+		t.Fatalf("Error parsing policy: %v", err)
+	}
+	if len(p.Rules) != 1 || p.Rules[0].Contractorids[0] != 72494 {
+		t.Errorf("Unexpected policy %+v", p)
+	}
+	if _, err := Parse("policy.json", []byte(`{"rules":[{"contractorids":[72494]}]}`)); err == nil {
+		t.Errorf("Expected error for rule without subjects or roles")
+	}
+	if _, err := Parse("policy.yaml", []byte("rules:\n  - subject: [portal]\n")); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(filename, []byte(testPolicyYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(filename)
+	if err != nil {
+		t.Fatalf("Error loading policy: %v", err)
+	}
+	portal := &auth.Identity{Subject: "portal"}
+	if err := engine.Authorize(portal, 10000, "01"); err == nil {
+		t.Errorf("Expected contractor 10000 to be denied")
+	}
+
+	updated := "rules:\n  - subjects: [portal]\n    contractorids: [10000]\n"
+	if err := ioutil.WriteFile(filename, []byte(updated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(filename, time.Now(), time.Now().Add(time.Second))
+	if reloaded, err := engine.Reload(); !reloaded || err != nil {
+		t.Fatalf("Expected reload but got %v, %v", reloaded, err)
+	}
+	if err := engine.Authorize(portal, 10000, "01"); err != nil {
+		t.Errorf("Expected contractor 10000 to be allowed but got %v", err)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("rules: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(filename, time.Now(), time.Now().Add(2*time.Second))
+	if _, err := engine.Reload(); err == nil {
+		t.Errorf("Expected error reloading invalid policy")
+	}
+	if err := engine.Authorize(portal, 10000, "01"); err != nil {
+		t.Errorf("Expected previous policy to remain in force but got %v", err)
+	}
+}
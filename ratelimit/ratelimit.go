@@ -0,0 +1,290 @@
+// Package ratelimit limits the rate and daily volume of uploads per
+// contractor, and optionally per caller within a contractor.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Limits bounds the uploads of one contractor, or one caller for a
+// contractor. A zero rate or quota places no limit.
+type Limits struct {
+	// RequestsPerSecond refills a bucket of RequestBurst requests
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second"`
+	RequestBurst      int64   `json:"request_burst" yaml:"request_burst"`
+	// BytesPerSecond refills a bucket of ByteBurst bytes of file contents
+	BytesPerSecond float64 `json:"bytes_per_second" yaml:"bytes_per_second"`
+	ByteBurst      int64   `json:"byte_burst" yaml:"byte_burst"`
+	// DailyRequests and DailyBytes reset at midnight UTC
+	DailyRequests int64 `json:"daily_requests" yaml:"daily_requests"`
+	DailyBytes    int64 `json:"daily_bytes" yaml:"daily_bytes"`
+}
+
+// Override replaces the default limits for the contractors it lists
+type Override struct {
+	Contractorids []int64 `json:"contractorids" yaml:"contractorids"`
+	Limits        `yaml:",inline"`
+}
+
+// Config is the contents of a rate limit file
+type Config struct {
+	// ByCaller keeps separate limits for each authenticated caller
+	// uploading for a contractor
+	ByCaller  bool       `json:"by_caller" yaml:"by_caller"`
+	Default   Limits     `json:"default" yaml:"default"`
+	Overrides []Override `json:"overrides" yaml:"overrides"`
+}
+
+func (l *Limits) validate() error {
+	if l.RequestsPerSecond < 0 || l.BytesPerSecond < 0 || l.DailyRequests < 0 || l.DailyBytes < 0 {
+		return fmt.Errorf("rates and quotas must not be negative")
+	}
+	if l.RequestsPerSecond > 0 && l.RequestBurst < 1 {
+		return fmt.Errorf("request_burst must be at least 1 when requests_per_second is set")
+	}
+	if l.BytesPerSecond > 0 && l.ByteBurst < 1 {
+		return fmt.Errorf("byte_burst must be at least 1 when bytes_per_second is set")
+	}
+	return nil
+}
+
+// Validate checks every set of limits and that each override names a contractor
+func (c *Config) Validate() error {
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("Default: %s", err)
+	}
+	for i, override := range c.Overrides {
+		if len(override.Contractorids) == 0 {
+			return fmt.Errorf("Override %d: contractorids are required", i)
+		}
+		if err := override.validate(); err != nil {
+			return fmt.Errorf("Override %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// limits returns the limits applying to contractorID
+func (c *Config) limits(contractorID int64) Limits {
+	for _, override := range c.Overrides {
+		for _, id := range override.Contractorids {
+			if id == contractorID {
+				return override.Limits
+			}
+		}
+	}
+	return c.Default
+}
+
+// Parse decodes a rate limit config from YAML if filename has a .yaml or
+// .yml extension and from JSON otherwise
+func Parse(filename string, contents []byte) (*Config, error) {
+	c := &Config{}
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(contents, c)
+	default:
+		decoder := json.NewDecoder(strings.NewReader(string(contents)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing rate limit file %s: %s", filename, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid rate limit file %s: %s", filename, err)
+	}
+	return c, nil
+}
+
+// Load reads the rate limit config in filename
+func Load(filename string) (*Config, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filename, contents)
+}
+
+// ExceededError reports which limit rejected an upload and when it may be
+// retried. A zero RetryAfter means retrying will not help.
+type ExceededError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	if e.RetryAfter == 0 {
+		return fmt.Sprintf("%s exceeded", e.Limit)
+	}
+	return fmt.Sprintf("%s exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+// bucket is a token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the bucket was last used
+func (b *bucket) refill(now time.Time, rate float64, burst int64) {
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+}
+
+// wait is how long until the bucket holds n tokens
+func (b *bucket) wait(n float64, rate float64) time.Duration {
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration(math.Ceil((n - b.tokens) / rate * float64(time.Second)))
+}
+
+// full reports whether the bucket has refilled to burst by now
+func (b *bucket) full(now time.Time, rate float64, burst int64) bool {
+	return b.last.IsZero() || rate == 0 || b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst)
+}
+
+type key struct {
+	contractorID int64
+	caller       string
+}
+
+// usage is the state of one contractor's or caller's limits
+type usage struct {
+	requests bucket
+	bytes    bucket
+	day      time.Time
+	daily    struct{ requests, bytes int64 }
+}
+
+// idle reports whether u is no different from the usage of a contractor or
+// caller which has not uploaded, so it can be forgotten
+func (u *usage) idle(limits Limits, now time.Time) bool {
+	if !u.requests.full(now, limits.RequestsPerSecond, limits.RequestBurst) || !u.bytes.full(now, limits.BytesPerSecond, limits.ByteBurst) {
+		return false
+	}
+	daily := limits.DailyRequests > 0 || limits.DailyBytes > 0
+	return !daily || !u.day.Equal(now.UTC().Truncate(24*time.Hour))
+}
+
+// sweepInterval is how often the limiter forgets idle usage, which would
+// otherwise accumulate for every contractor and caller ever seen
+const sweepInterval = time.Minute
+
+// Limiter enforces a Config
+type Limiter struct {
+	mu        sync.Mutex
+	config    *Config
+	usage     map[key]*usage
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewLimiter creates a limiter enforcing config
+func NewLimiter(config *Config) *Limiter {
+	return &Limiter{config: config, usage: make(map[key]*usage), now: time.Now}
+}
+
+// SetConfig replaces the limits enforced, keeping the usage recorded so far
+func (l *Limiter) SetConfig(config *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+}
+
+// Allow records an upload of size bytes by caller for contractorID,
+// returning an *ExceededError without recording it if it exceeds a limit
+func (l *Limiter) Allow(contractorID int64, caller string, size int64) error {
+	return l.allow(contractorID, caller, 1, size)
+}
+
+// AllowBytes records size bytes uploaded by a request already allowed,
+// returning an *ExceededError without recording them if they exceed a limit
+func (l *Limiter) AllowBytes(contractorID int64, caller string, size int64) error {
+	return l.allow(contractorID, caller, 0, size)
+}
+
+func (l *Limiter) allow(contractorID int64, caller string, requests, size int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+	k := key{contractorID: contractorID}
+	if l.config.ByCaller {
+		k.caller = caller
+	}
+	u, ok := l.usage[k]
+	if !ok {
+		u = &usage{}
+		l.usage[k] = u
+	}
+	limits := l.config.limits(contractorID)
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !u.day.Equal(day) {
+		u.day = day
+		u.daily.requests, u.daily.bytes = 0, 0
+	}
+	untilTomorrow := day.Add(24 * time.Hour).Sub(now)
+
+	if requests > 0 && limits.DailyRequests > 0 && u.daily.requests+requests > limits.DailyRequests {
+		return &ExceededError{Limit: "daily request quota", RetryAfter: untilTomorrow}
+	}
+	if limits.DailyBytes > 0 && u.daily.bytes+size > limits.DailyBytes {
+		if size > limits.DailyBytes {
+			return &ExceededError{Limit: "daily byte quota"}
+		}
+		return &ExceededError{Limit: "daily byte quota", RetryAfter: untilTomorrow}
+	}
+	if requests > 0 && limits.RequestsPerSecond > 0 {
+		u.requests.refill(now, limits.RequestsPerSecond, limits.RequestBurst)
+		if wait := u.requests.wait(float64(requests), limits.RequestsPerSecond); wait > 0 {
+			return &ExceededError{Limit: "request rate", RetryAfter: wait}
+		}
+	}
+	if limits.BytesPerSecond > 0 {
+		if size > limits.ByteBurst {
+			return &ExceededError{Limit: "byte rate"}
+		}
+		u.bytes.refill(now, limits.BytesPerSecond, limits.ByteBurst)
+		if wait := u.bytes.wait(float64(size), limits.BytesPerSecond); wait > 0 {
+			return &ExceededError{Limit: "byte rate", RetryAfter: wait}
+		}
+	}
+
+	if requests > 0 && limits.RequestsPerSecond > 0 {
+		u.requests.tokens -= float64(requests)
+	}
+	if limits.BytesPerSecond > 0 {
+		u.bytes.tokens -= float64(size)
+	}
+	u.daily.requests += requests
+	u.daily.bytes += size
+	return nil
+}
+
+// sweep forgets the usage which has become idle
+func (l *Limiter) sweep(now time.Time) {
+	l.lastSweep = now
+	for k, u := range l.usage {
+		if u.idle(l.config.limits(k.contractorID), now) {
+			delete(l.usage, k)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+const testConfigYAML = `
+by_caller: true
+default:
+  requests_per_second: 1
+  request_burst: 2
+  bytes_per_second: 100
+  byte_burst: 200
+overrides:
+  - contractorids: [72494]
+    daily_requests: 3
+    daily_bytes: 1000
+`
+
+func newTestLimiter(t *testing.T) (*Limiter, *time.Time) {
+	config, err := Parse("ratelimit.yaml", []byte(testConfigYAML))
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	now := time.Date(2017, 3, 9, 23, 59, 0, 0, time.UTC)
+	limiter := NewLimiter(config)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func expectExceeded(t *testing.T, err error, limit string, retryAfter time.Duration) {
+	exceeded, ok := err.(*ExceededError)
+	if !ok {
+		t.Errorf("Expected %s to be exceeded but got %v", limit, err)
+		return
+	}
+	if exceeded.Limit != limit || exceeded.RetryAfter != retryAfter {
+		t.Errorf("Expected %s retry after %s but got %s retry after %s", limit, retryAfter, exceeded.Limit, exceeded.RetryAfter)
+	}
+}
+
+func TestRequestRate(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	for i := 0; i < 2; i++ {
+		if err := limiter.Allow(10000, "portal", 0); err != nil {
+			t.Fatalf("Expected request %d within burst to be allowed but got %v", i, err)
+		}
+	}
+	expectExceeded(t, limiter.Allow(10000, "portal", 0), "request rate", time.Second)
+	if err := limiter.Allow(10000, "field", 0); err != nil {
+		t.Errorf("Expected another caller to have its own limit but got %v", err)
+	}
+	*now = now.Add(time.Second)
+	if err := limiter.Allow(10000, "portal", 0); err != nil {
+		t.Errorf("Expected request to be allowed after refill but got %v", err)
+	}
+}
+
+func TestByteRate(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	if err := limiter.Allow(10000, "portal", 150); err != nil {
+		t.Fatalf("Expected upload within burst to be allowed but got %v", err)
+	}
+	expectExceeded(t, limiter.Allow(10000, "portal", 100), "byte rate", 500*time.Millisecond)
+	expectExceeded(t, limiter.Allow(10000, "portal", 300), "byte rate", 0)
+	*now = now.Add(500 * time.Millisecond)
+	if err := limiter.Allow(10000, "portal", 100); err != nil {
+		t.Errorf("Expected upload to be allowed after refill but got %v", err)
+	}
+}
+
+func TestDailyQuota(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	for i := 0; i < 3; i++ {
+		if err := limiter.Allow(72494, "portal", 100); err != nil {
+			t.Fatalf("Expected request %d within quota to be allowed but got %v", i, err)
+		}
+	}
+	expectExceeded(t, limiter.Allow(72494, "portal", 100), "daily request quota", time.Minute)
+	expectExceeded(t, limiter.Allow(72494, "field", 2000), "daily byte quota", 0)
+	*now = now.Add(time.Minute)
+	if err := limiter.Allow(72494, "portal", 100); err != nil {
+		t.Errorf("Expected quota to reset at midnight but got %v", err)
+	}
+}
+
+func TestAllowBytes(t *testing.T) {
+	limiter, _ := newTestLimiter(t)
+	if err := limiter.Allow(10000, "portal", 0); err != nil {
+		t.Fatalf("Expected request within burst to be allowed but got %v", err)
+	}
+	if err := limiter.AllowBytes(10000, "portal", 150); err != nil {
+		t.Fatalf("Expected bytes within burst to be allowed but got %v", err)
+	}
+	if err := limiter.Allow(10000, "portal", 0); err != nil {
+		t.Errorf("Expected bytes not to count as another request but got %v", err)
+	}
+	expectExceeded(t, limiter.AllowBytes(10000, "portal", 100), "byte rate", 500*time.Millisecond)
+}
+
+func TestSweep(t *testing.T) {
+	limiter, now := newTestLimiter(t)
+	limiter.Allow(10000, "portal", 200)
+	limiter.Allow(72494, "portal", 100)
+	limiter.sweep(now.Add(time.Second))
+	if len(limiter.usage) != 2 {
+		t.Errorf("Expected usage with a draining bucket or today's quota to be kept but got %d entries", len(limiter.usage))
+	}
+	limiter.sweep(now.Add(30 * time.Second))
+	if _, ok := limiter.usage[key{10000, "portal"}]; ok || len(limiter.usage) != 1 {
+		t.Errorf("Expected refilled usage without a quota to be forgotten but got %d entries", len(limiter.usage))
+	}
+	// The next Allow a sweep interval after the last sweep sweeps again
+	*now = now.Add(30*time.Second + sweepInterval)
+	limiter.Allow(10000, "field", 0)
+	if _, ok := limiter.usage[key{72494, "portal"}]; ok || len(limiter.usage) != 1 {
+		t.Errorf("Expected yesterday's quota to be forgotten but got %d entries", len(limiter.usage))
+	}
+}
+
+func TestParse(t *testing.T) {
+	config, err := Parse("ratelimit.json", []byte(`{"default":{"daily_bytes":1000},"overrides":[{"contractorids":[72494],"daily_bytes":5000}]}`))
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.limits(72494).DailyBytes != 5000 || config.limits(1).DailyBytes != 1000 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if _, err := Parse("ratelimit.json", []byte(`{"default":{"requests_per_second":1}}`)); err == nil {
+		t.Errorf("Expected error for rate without burst")
+	}
+	if _, err := Parse("ratelimit.yaml", []byte("overrides:\n  - daily_bytes: 10\n")); err == nil {
+		t.Errorf("Expected error for override without contractorids")
+	}
+	if _, err := Parse("ratelimit.yaml", []byte("default:\n  daily_byte: 10\n")); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+}
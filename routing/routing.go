@@ -0,0 +1,191 @@
+// Package routing chooses the ServiceBus method, endpoint and parameter shape
+// a Put is sent with, based on the attributes of the request. Calls reading
+// and replacing stored content follow the route of a Put of that content.
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Parameter shapes of a JSON-RPC request
+const (
+	// ParamsObject sends the PutParams as the params object
+	ParamsObject = "object"
+	// ParamsArray sends the PutParams as the only positional param
+	ParamsArray = "array"
+)
+
+// Attributes are the properties of a Put that routes match on
+type Attributes struct {
+	Imagetype    int32
+	Deptcode     string
+	Contractorid int64
+	// Destination is the lower case destination system, such as "inspi"
+	// or "vendorweb", or empty for v1 requests which do not name one
+	Destination string
+}
+
+// Route sends the Puts it matches to Method at Endpoint. An empty
+// Imagetypes, Deptcodes, Contractorids or Destinations list places no
+// restriction on that attribute.
+type Route struct {
+	Imagetypes    []int32  `json:"imagetypes" yaml:"imagetypes"`
+	Deptcodes     []string `json:"deptcodes" yaml:"deptcodes"`
+	Contractorids []int64  `json:"contractorids" yaml:"contractorids"`
+	Destinations  []string `json:"destinations" yaml:"destinations"`
+
+	// Method is the JSON-RPC method name
+	Method string `json:"method" yaml:"method"`
+	// GetMethod and UpdateMethod are the JSON-RPC methods reading and
+	// replacing the content the route stores, if not the server's defaults
+	GetMethod    string `json:"get_method" yaml:"get_method"`
+	UpdateMethod string `json:"update_method" yaml:"update_method"`
+	// Endpoint overrides the ServiceBus endpoint URL if set
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// Params is ParamsObject or ParamsArray, defaulting to ParamsObject
+	Params string `json:"params" yaml:"params"`
+}
+
+// Table is the contents of a routing file. The first matching route is used.
+type Table struct {
+	Routes []Route `json:"routes" yaml:"routes"`
+}
+
+// Validate checks that every route names a method and a known parameter shape
+func (t *Table) Validate() error {
+	for i, route := range t.Routes {
+		if route.Method == "" {
+			return fmt.Errorf("Route %d: method is required", i)
+		}
+		switch route.Params {
+		case "", ParamsObject, ParamsArray:
+		default:
+			return fmt.Errorf("Route %d: params must be %q or %q, got %q", i, ParamsObject, ParamsArray, route.Params)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt32(values []int32, value int32) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the route applies to a Put with attributes
+func (r *Route) Matches(attributes Attributes) bool {
+	switch {
+	case len(r.Imagetypes) > 0 && !containsInt32(r.Imagetypes, attributes.Imagetype):
+		return false
+	case len(r.Deptcodes) > 0 && !containsString(r.Deptcodes, attributes.Deptcode):
+		return false
+	case len(r.Contractorids) > 0 && !containsInt64(r.Contractorids, attributes.Contractorid):
+		return false
+	case len(r.Destinations) > 0 && !containsString(r.Destinations, attributes.Destination):
+		return false
+	}
+	return true
+}
+
+// Match returns the first route applying to a Put with attributes
+func (t *Table) Match(attributes Attributes) (Route, bool) {
+	for _, route := range t.Routes {
+		if route.Matches(attributes) {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// Parse decodes a routing table from YAML if filename has a .yaml or .yml
+// extension and from JSON otherwise
+func Parse(filename string, contents []byte) (*Table, error) {
+	t := &Table{}
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(contents, t)
+	default:
+		decoder := json.NewDecoder(strings.NewReader(string(contents)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing routing file %s: %s", filename, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid routing file %s: %s", filename, err)
+	}
+	return t, nil
+}
+
+// Load reads the routing table in filename
+func Load(filename string) (*Table, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(filename, contents)
+}
+
+type routeKey struct{}
+
+// NewContext returns a context carrying the route a request is sent with
+func NewContext(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// FromContext returns the route stored in ctx, if any
+func FromContext(ctx context.Context) (Route, bool) {
+	route, ok := ctx.Value(routeKey{}).(Route)
+	return route, ok
+}
+
+// AtomicTable is a Table that can be replaced while requests are routed
+type AtomicTable struct {
+	current atomic.Value
+}
+
+// Set replaces the table in use. A nil table matches no requests.
+func (a *AtomicTable) Set(t *Table) {
+	a.current.Store(t)
+}
+
+// Match returns the first route applying to a Put with attributes in the
+// current table
+func (a *AtomicTable) Match(attributes Attributes) (Route, bool) {
+	t, _ := a.current.Load().(*Table)
+	if t == nil {
+		return Route{}, false
+	}
+	return t.Match(attributes)
+}
@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"context"
+	"testing"
+)
+
+const testRoutesYAML = `
+routes:
+  - imagetypes: [2]
+    method: CONTENTSERVICE.DOCUMENTPUT
+    endpoint: http://documents.qa01.local/Execute.svc/Execute
+  - deptcodes: ["02"]
+    destinations: [inspi]
+    method: CONTENTSERVICE.DEPT02PUT
+    update_method: CONTENTSERVICE.DEPT02UPDATE
+    params: array
+  - contractorids: [72494]
+    method: CONTENTSERVICE.CONTRACTORPUT
+`
+
+var matchCases = []struct {
+	attributes     Attributes
+	expectedMethod string
+	matched        bool
+}{
+	{attributes: Attributes{Imagetype: 2, Deptcode: "02", Destination: "inspi"}, expectedMethod: "CONTENTSERVICE.DOCUMENTPUT", matched: true},
+	{attributes: Attributes{Imagetype: 1, Deptcode: "02", Destination: "inspi"}, expectedMethod: "CONTENTSERVICE.DEPT02PUT", matched: true},
+	{attributes: Attributes{Imagetype: 1, Deptcode: "02", Contractorid: 72494}, expectedMethod: "CONTENTSERVICE.CONTRACTORPUT", matched: true},
+	{attributes: Attributes{Imagetype: 1, Deptcode: "02", Destination: "vendorweb"}, matched: false},
+	{attributes: Attributes{Imagetype: 1, Deptcode: "01", Contractorid: 10000}, matched: false},
+}
+
+func TestMatch(t *testing.T) {
+	table, err := Parse("routes.yaml", []byte(testRoutesYAML))
+	if err != nil {
+		t.Fatalf("Error parsing routes: %v", err)
+	}
+	for _, c := range matchCases {
+		route, ok := table.Match(c.attributes)
+		if ok != c.matched || route.Method != c.expectedMethod {
+			t.Errorf("%+v: expected %q, %v but got %q, %v", c.attributes, c.expectedMethod, c.matched, route.Method, ok)
+		}
+	}
+	if route, _ := table.Match(Attributes{Deptcode: "02", Destination: "inspi"}); route.UpdateMethod != "CONTENTSERVICE.DEPT02UPDATE" || route.GetMethod != "" {
+		t.Errorf("Expected only an update method override but got %+v", route)
+	}
+}
+
+func TestParse(t *testing.T) {
+	table, err := Parse("routes.json", []byte(`{"routes":[{"imagetypes":[2],"method":"CONTENTSERVICE.DOCUMENTPUT","params":"object"}]}`))
+	if err != nil {
+		t.Fatalf("Error parsing routes: %v", err)
+	}
+	if len(table.Routes) != 1 || table.Routes[0].Imagetypes[0] != 2 {
+		t.Errorf("Unexpected routes %+v", table)
+	}
+	if _, err := Parse("routes.json", []byte(`{"routes":[{"imagetypes":[2]}]}`)); err == nil {
+		t.Errorf("Expected error for route without method")
+	}
+	if _, err := Parse("routes.yaml", []byte("routes:\n  - method: PUT\n    params: named\n")); err == nil {
+		t.Errorf("Expected error for unknown params shape")
+	}
+	if _, err := Parse("routes.yaml", []byte("routes:\n  - method: PUT\n    imagetype: [2]\n")); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Errorf("Expected no route in empty context")
+	}
+	route, ok := FromContext(NewContext(context.Background(), Route{Method: "PUT"}))
+	if !ok || route.Method != "PUT" {
+		t.Errorf("Expected route PUT but got %+v, %v", route, ok)
+	}
+}
+
+func TestAtomicTable(t *testing.T) {
+	table := &AtomicTable{}
+	if _, ok := table.Match(Attributes{Imagetype: 2}); ok {
+		t.Errorf("Expected no match before a table is set")
+	}
+	table.Set(&Table{Routes: []Route{{Imagetypes: []int32{2}, Method: "CONTENTSERVICE.DOCUMENTPUT"}}})
+	if route, ok := table.Match(Attributes{Imagetype: 2}); !ok || route.Method != "CONTENTSERVICE.DOCUMENTPUT" {
+		t.Errorf("Expected route CONTENTSERVICE.DOCUMENTPUT but got %+v, %v", route, ok)
+	}
+	table.Set(nil)
+	if _, ok := table.Match(Attributes{Imagetype: 2}); ok {
+		t.Errorf("Expected no match after the table is cleared")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pbadmin "github.com/divyag9/gothinnercontentservice/contentservice/admin"
+)
+
+// adminServer is the ContentServiceAdmin service of a running server
+type adminServer struct {
+	pbadmin.UnimplementedContentServiceAdminServer
+	config   *runtimeConfig
+	logger   *leveledLogger
+	circuit  *circuit
+	requests *requestTracker
+}
+
+// adminInterceptors lays out the interceptors of the admin server like
+// those of the main server, letting through only callers granted role
+func adminInterceptors(outer, inner []grpc.UnaryServerInterceptor, authenticator auth.Authenticator, role string) []grpc.UnaryServerInterceptor {
+	interceptors := append([]grpc.UnaryServerInterceptor(nil), outer...)
+	interceptors = append(interceptors, auth.UnaryServerInterceptor(authenticator), auth.RequireRole(role))
+	return append(interceptors, inner...)
+}
+
+func (s *adminServer) GetConfig(ctx context.Context, request *pbadmin.GetConfigRequest) (*pbadmin.GetConfigResponse, error) {
+	response := &pbadmin.GetConfigResponse{}
+	for _, setting := range s.config.settings() {
+		response.Settings = append(response.Settings, &pbadmin.Setting{
+			Name:       setting.name,
+			Value:      setting.value,
+			Source:     setting.source,
+			Reloadable: setting.reloadable,
+		})
+	}
+	return response, nil
+}
+
+func (s *adminServer) ReloadConfig(ctx context.Context, request *pbadmin.ReloadConfigRequest) (*pbadmin.ReloadConfigResponse, error) {
+	changes, err := s.config.Reload()
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	response := &pbadmin.ReloadConfigResponse{}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, &pbadmin.SettingChange{
+			Name:     change.Name,
+			OldValue: redact(change.Old),
+			NewValue: redact(change.New),
+		})
+	}
+	return response, nil
+}
+
+func (s *adminServer) SetLogLevel(ctx context.Context, request *pbadmin.SetLogLevelRequest) (*pbadmin.SetLogLevelResponse, error) {
+	level, err := parseLogLevel(request.Level)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	response := &pbadmin.SetLogLevelResponse{PreviousLevel: s.logger.levelName()}
+	s.logger.setLevel(level)
+	return response, nil
+}
+
+func (s *adminServer) SetCircuit(ctx context.Context, request *pbadmin.SetCircuitRequest) (*pbadmin.Circuit, error) {
+	if request.Open && request.Reason == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "A reason is required to open the circuit")
+	}
+	var changedBy string
+	if identity, ok := auth.FromContext(ctx); ok {
+		changedBy = identity.Subject
+	}
+	state := s.circuit.set(request.Open, request.Reason, changedBy)
+	return &pbadmin.Circuit{Open: state.open, Reason: state.reason, ChangedBy: state.changedBy, ChangeTime: timestamppb.New(state.changed)}, nil
+}
+
+func (s *adminServer) ListInFlightRequests(ctx context.Context, request *pbadmin.ListInFlightRequestsRequest) (*pbadmin.ListInFlightRequestsResponse, error) {
+	response := &pbadmin.ListInFlightRequestsResponse{}
+	for _, r := range s.requests.inFlightRequests() {
+		response.Requests = append(response.Requests, &pbadmin.InFlightRequest{
+			Method:    r.method,
+			Caller:    r.caller,
+			StartTime: timestamppb.New(r.start),
+			Duration:  durationpb.New(r.duration),
+		})
+	}
+	return response, nil
+}
+
+func (s *adminServer) ListRecentErrors(ctx context.Context, request *pbadmin.ListRecentErrorsRequest) (*pbadmin.ListRecentErrorsResponse, error) {
+	if request.Limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must not be negative, got %d", request.Limit)
+	}
+	response := &pbadmin.ListRecentErrorsResponse{}
+	for _, r := range s.requests.recentErrors(int(request.Limit)) {
+		response.Errors = append(response.Errors, &pbadmin.RecentError{
+			Method:   r.method,
+			Caller:   r.caller,
+			Time:     timestamppb.New(r.start.Add(r.duration)),
+			Duration: durationpb.New(r.duration),
+			Code:     r.code,
+			Message:  r.message,
+		})
+	}
+	return response, nil
+}
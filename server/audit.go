@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+// audit records the outcome of a mutation sending request at start, such as
+// a Put. The mutation has already happened, so a record that cannot be
+// written is logged rather than failing it.
+func (s *Server) audit(ctx context.Context, action string, request *pb.PutParams, destination string, start time.Time, response *pb.PutResponse, err error) {
+	if s.Auditor == nil {
+		return
+	}
+	sum := sha256.Sum256(request.GetFilecontents())
+	record := &audit.Record{
+		Time:          start,
+		Action:        action,
+		Contractorid:  request.GetContractorid(),
+		Ordernumber:   request.GetOrdernumber(),
+		Destination:   destination,
+		Filename:      request.GetFilename(),
+		ContentSHA256: hex.EncodeToString(sum[:]),
+		Size:          int64(len(request.GetFilecontents())),
+		ContentID:     int64(request.GetId()),
+		DurationMS:    float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if identity, ok := auth.FromContext(ctx); ok {
+		record.Caller = identity.Subject
+	}
+	switch {
+	case err != nil:
+		st := status.Convert(normalizeError(err))
+		record.Outcome = st.Code().String()
+		record.Error = st.Message()
+	case response.GetError() != nil:
+		record.Outcome = audit.OutcomeServiceBusError
+		record.Error = response.GetError().GetMessage()
+	default:
+		record.Outcome = audit.OutcomeOK
+		record.ContentID = int64(response.GetResult().GetId())
+		record.GUID = response.GetResult().GetGuid()
+	}
+	if err := s.Auditor.Append(record); err != nil {
+		s.auditFailures.Add(1)
+		logger.Errorf("Failed to audit %s of %s for contractor %d order %d: %v", record.Action, record.Filename, record.Contractorid, record.Ordernumber, err)
+	}
+}
+
+// publishAuditFailures exposes the count of records the Auditor failed to
+// write as the audit_failures expvar
+func (s *Server) publishAuditFailures() {
+	expvar.Publish("audit_failures", &s.auditFailures)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+type FakeAuditor struct {
+	Records []*audit.Record
+	Err     error
+}
+
+func (f *FakeAuditor) Append(record *audit.Record) error {
+	f.Records = append(f.Records, record)
+	return f.Err
+}
+
+var auditCases = []struct {
+	serviceBus        *FakeServer
+	authorizer        Authorizer
+	expectedOutcome   string
+	expectedContentID int64
+	expectedGUID      string
+}{
+	{
+		serviceBus:        &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1810448062, Guid: "da00563b-bb38-49b1-b3ef-29dbce63fbed"}}},
+		expectedOutcome:   audit.OutcomeOK,
+		expectedContentID: 1810448062,
+		expectedGUID:      "da00563b-bb38-49b1-b3ef-29dbce63fbed",
+	},
+	{
+		serviceBus:      &FakeServer{Response: &pb.JSONRPCResponse{Error: &pb.JSONRPCError{Code: -32000, Message: "Order not found"}}},
+		expectedOutcome: audit.OutcomeServiceBusError,
+	},
+	{
+		serviceBus:      &FakeServer{Response: &pb.JSONRPCResponse{}},
+		authorizer:      &FakeAuthorizer{Err: errors.New("no")},
+		expectedOutcome: "PermissionDenied",
+	},
+}
+
+func TestPutAudit(t *testing.T) {
+	for _, c := range auditCases {
+		auditor := &FakeAuditor{}
+		server := &Server{ServiceBusCaller: c.serviceBus, Authorizer: c.authorizer, Auditor: auditor}
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "portal"})
+		server.Put(ctx, &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png", Filecontents: []byte("abc")})
+		if len(auditor.Records) != 1 {
+			t.Fatalf("Expected 1 audit record but got %d", len(auditor.Records))
+		}
+		record := auditor.Records[0]
+		if record.Outcome != c.expectedOutcome || record.ContentID != c.expectedContentID || record.GUID != c.expectedGUID {
+			t.Errorf("Expected outcome %s with content %d %s but got %+v", c.expectedOutcome, c.expectedContentID, c.expectedGUID, record)
+		}
+		if record.Caller != "portal" || record.Contractorid != 72494 || record.Ordernumber != 600016555 || record.Filename != "test.png" || record.Size != 3 {
+			t.Errorf("Expected the put by portal to be recorded but got %+v", record)
+		}
+		if expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; record.ContentSHA256 != expected {
+			t.Errorf("Expected hash %s but got %s", expected, record.ContentSHA256)
+		}
+	}
+}
+
+func TestPutAuditFailure(t *testing.T) {
+	server := &Server{
+		ServiceBusCaller: &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1}}},
+		Auditor:          &FakeAuditor{Err: errors.New("disk full")},
+	}
+	if _, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png"}); err != nil {
+		t.Errorf("Expected the put to succeed but got %v", err)
+	}
+	if failures := server.auditFailures.Value(); failures != 1 {
+		t.Errorf("Expected 1 audit failure but got %d", failures)
+	}
+}
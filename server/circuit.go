@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+// circuitState is whether the ServiceBus circuit is open, and who last
+// changed it, when and why
+type circuitState struct {
+	open      bool
+	reason    string
+	changedBy string
+	changed   time.Time
+}
+
+// circuit fails calls to ServiceBus without making them while it is open.
+// Operators open it by hand, e.g. during a ServiceBus outage or maintenance.
+type circuit struct {
+	next ServiceBusCaller
+
+	mu    sync.RWMutex
+	state circuitState
+	now   func() time.Time
+
+	rejected expvar.Int
+}
+
+func newCircuit(next ServiceBusCaller) *circuit {
+	return &circuit{next: next, now: time.Now}
+}
+
+func (c *circuit) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	state := c.current()
+	if state.open {
+		c.rejected.Add(1)
+		return nil, status.Errorf(codes.Unavailable, "ServiceBus circuit is open: %s", state.reason)
+	}
+	return c.next.callServiceBus(ctx, request)
+}
+
+// current returns the state of the circuit
+func (c *circuit) current() circuitState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// set opens or closes the circuit on behalf of changedBy
+func (c *circuit) set(open bool, reason, changedBy string) circuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = circuitState{open: open, reason: reason, changedBy: changedBy, changed: c.now()}
+	if open {
+		logger.Warningf("ServiceBus circuit opened by %s: %s", changedBy, reason)
+	} else {
+		logger.Infof("ServiceBus circuit closed by %s", changedBy)
+	}
+	return c.state
+}
+
+// publish exposes the circuit state as the servicebus_circuit expvar
+func (c *circuit) publish() {
+	m := expvar.NewMap("servicebus_circuit")
+	m.Set("open", expvar.Func(func() interface{} {
+		return c.current().open
+	}))
+	m.Set("rejected", &c.rejected)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+// priority is the lane a ServiceBus call waits in when the limit is reached.
+// Interactive calls are always let through before batch calls.
+type priority int
+
+const (
+	priorityInteractive priority = iota
+	priorityBatch
+	priorities
+)
+
+// priorityHeader lets callers mark bulk imports as "batch"
+const priorityHeader = "priority"
+
+type priorityKey struct{}
+
+// withPriority returns a context whose ServiceBus calls wait in lane p
+func withPriority(ctx context.Context, p priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// priorityFromContext returns the lane set by withPriority, else the lane
+// named in the priority header, else the interactive lane
+func priorityFromContext(ctx context.Context) priority {
+	if p, ok := ctx.Value(priorityKey{}).(priority); ok {
+		return p
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(priorityHeader); len(values) > 0 && values[0] == "batch" {
+			return priorityBatch
+		}
+	}
+	return priorityInteractive
+}
+
+// concurrencyLimiter bounds the ServiceBus calls in flight, adapting the
+// bound by AIMD: it grows by one per limit's worth of calls completing
+// within latencyTarget and shrinks by a tenth when a call fails or is slower.
+// Calls over the limit wait in a bounded queue for up to queueTimeout.
+type concurrencyLimiter struct {
+	minLimit      float64
+	maxLimit      float64
+	latencyTarget time.Duration
+	maxQueue      int
+	queueTimeout  time.Duration
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	queued   int
+	lanes    [priorities]*list.List
+
+	rejected expvar.Int
+	timeouts expvar.Int
+}
+
+func newConcurrencyLimiter(initial, max int, latencyTarget time.Duration, maxQueue int, queueTimeout time.Duration) *concurrencyLimiter {
+	l := &concurrencyLimiter{
+		minLimit:      1,
+		maxLimit:      float64(max),
+		latencyTarget: latencyTarget,
+		maxQueue:      maxQueue,
+		queueTimeout:  queueTimeout,
+		limit:         math.Min(float64(initial), float64(max)),
+	}
+	for i := range l.lanes {
+		l.lanes[i] = list.New()
+	}
+	return l
+}
+
+// acquire waits for a slot to call ServiceBus in, returning ResourceExhausted
+// if the queue is full or Unavailable if no slot frees up in time
+func (l *concurrencyLimiter) acquire(ctx context.Context, p priority) error {
+	l.mu.Lock()
+	if l.queued == 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.queued >= l.maxQueue {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return status.Error(codes.ResourceExhausted, "ServiceBus is at its concurrency limit and the queue is full")
+	}
+	ready := make(chan struct{})
+	element := l.lanes[p].PushBack(ready)
+	l.queued++
+	queueTimeout := l.queueTimeout
+	l.mu.Unlock()
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+		err = status.Error(codes.Unavailable, "Timed out waiting for ServiceBus capacity")
+	case <-ctx.Done():
+		err = status.FromContextError(ctx.Err()).Err()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-ready:
+		// The slot was granted while giving up, so hand it back
+		l.inFlight--
+		l.dispatch()
+	default:
+		l.lanes[p].Remove(element)
+		l.queued--
+	}
+	l.timeouts.Add(1)
+	return err
+}
+
+// setLimits changes the bounds of the limiter, shrinking the current limit
+// if it is above max
+func (l *concurrencyLimiter) setLimits(max int, latencyTarget time.Duration, maxQueue int, queueTimeout time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxLimit = float64(max)
+	l.limit = math.Min(l.limit, l.maxLimit)
+	l.latencyTarget = latencyTarget
+	l.maxQueue = maxQueue
+	l.queueTimeout = queueTimeout
+	l.dispatch()
+}
+
+// release frees a slot and adapts the limit to the outcome of the call it held
+func (l *concurrencyLimiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	switch {
+	case err == context.Canceled:
+		// A call abandoned by its caller says nothing about ServiceBus
+	case err != nil || latency > l.latencyTarget:
+		l.limit = math.Max(l.minLimit, l.limit*0.9)
+	default:
+		l.limit = math.Min(l.maxLimit, l.limit+1/l.limit)
+	}
+	l.dispatch()
+}
+
+// dispatch grants free slots to queued calls, interactive first
+func (l *concurrencyLimiter) dispatch() {
+	for _, lane := range l.lanes {
+		for lane.Len() > 0 && l.inFlight < int(l.limit) {
+			ready := lane.Remove(lane.Front()).(chan struct{})
+			l.queued--
+			l.inFlight++
+			close(ready)
+		}
+	}
+}
+
+// publish exposes the limiter state as the servicebus_concurrency expvar
+func (l *concurrencyLimiter) publish() {
+	m := expvar.NewMap("servicebus_concurrency")
+	m.Set("limit", expvar.Func(func() interface{} {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return int(l.limit)
+	}))
+	m.Set("in_flight", expvar.Func(func() interface{} {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.inFlight
+	}))
+	m.Set("queued", expvar.Func(func() interface{} {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.queued
+	}))
+	m.Set("rejected", &l.rejected)
+	m.Set("queue_timeouts", &l.timeouts)
+}
+
+// limitedCaller calls next within the limiter's concurrency limit
+type limitedCaller struct {
+	next    ServiceBusCaller
+	limiter *concurrencyLimiter
+}
+
+func (c *limitedCaller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	if err := c.limiter.acquire(ctx, priorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	response, err := c.next.callServiceBus(ctx, request)
+	outcome := err
+	if ctx.Err() == context.Canceled {
+		outcome = context.Canceled
+	}
+	c.limiter.release(time.Since(start), outcome)
+	return response, err
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimiterPriority(t *testing.T) {
+	l := newConcurrencyLimiter(1, 1, time.Second, 10, time.Second)
+	if err := l.acquire(context.Background(), priorityInteractive); err != nil {
+		t.Fatalf("Expected first call to be let through but got %v", err)
+	}
+	order := make(chan priority, 2)
+	batchQueued := make(chan struct{})
+	go func() {
+		close(batchQueued)
+		if err := l.acquire(context.Background(), priorityBatch); err == nil {
+			order <- priorityBatch
+			l.release(time.Millisecond, nil)
+		}
+	}()
+	<-batchQueued
+	waitForQueued(t, l, 1)
+	go func() {
+		if err := l.acquire(context.Background(), priorityInteractive); err == nil {
+			order <- priorityInteractive
+			l.release(time.Millisecond, nil)
+		}
+	}()
+	waitForQueued(t, l, 2)
+	l.release(time.Millisecond, nil)
+	if first, second := <-order, <-order; first != priorityInteractive || second != priorityBatch {
+		t.Errorf("Expected the interactive call before the batch call but got %v then %v", first, second)
+	}
+}
+
+func waitForQueued(t *testing.T, l *concurrencyLimiter, n int) {
+	for i := 0; i < 100; i++ {
+		l.mu.Lock()
+		queued := l.queued
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d queued calls", n)
+}
+
+func TestConcurrencyLimiterQueue(t *testing.T) {
+	l := newConcurrencyLimiter(1, 1, time.Second, 1, 20*time.Millisecond)
+	l.acquire(context.Background(), priorityInteractive)
+	done := make(chan error)
+	go func() { done <- l.acquire(context.Background(), priorityInteractive) }()
+	waitForQueued(t, l, 1)
+	if err := l.acquire(context.Background(), priorityInteractive); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted with the queue full but got %v", err)
+	}
+	if err := <-done; status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable after the queue timeout but got %v", err)
+	}
+	if l.queued != 0 || l.inFlight != 1 {
+		t.Errorf("Expected the timed out call to leave the queue but got %d queued, %d in flight", l.queued, l.inFlight)
+	}
+}
+
+func TestConcurrencyLimiterAIMD(t *testing.T) {
+	l := newConcurrencyLimiter(10, 20, 100*time.Millisecond, 10, time.Second)
+	for i := 0; i < 10; i++ {
+		l.acquire(context.Background(), priorityInteractive)
+		l.release(time.Millisecond, nil)
+	}
+	if int(l.limit) != 10 && int(l.limit) != 11 {
+		t.Errorf("Expected the limit to grow by about one after a limit's worth of fast calls but got %v", l.limit)
+	}
+	before := l.limit
+	l.acquire(context.Background(), priorityInteractive)
+	l.release(time.Second, nil)
+	if l.limit >= before {
+		t.Errorf("Expected a slow call to shrink the limit from %v but got %v", before, l.limit)
+	}
+	before = l.limit
+	l.acquire(context.Background(), priorityInteractive)
+	l.release(time.Millisecond, errors.New("connection reset"))
+	if l.limit >= before {
+		t.Errorf("Expected a failed call to shrink the limit from %v but got %v", before, l.limit)
+	}
+	before = l.limit
+	l.acquire(context.Background(), priorityInteractive)
+	l.release(time.Second, context.Canceled)
+	if l.limit != before {
+		t.Errorf("Expected a cancelled call to leave the limit at %v but got %v", before, l.limit)
+	}
+}
+
+func TestPriorityFromContext(t *testing.T) {
+	batch := metadata.NewIncomingContext(context.Background(), metadata.Pairs(priorityHeader, "batch"))
+	if p := priorityFromContext(batch); p != priorityBatch {
+		t.Errorf("Expected batch priority from header but got %v", p)
+	}
+	if p := priorityFromContext(context.Background()); p != priorityInteractive {
+		t.Errorf("Expected interactive priority by default but got %v", p)
+	}
+	if p := priorityFromContext(withPriority(context.Background(), priorityBatch)); p != priorityBatch {
+		t.Errorf("Expected batch priority from context but got %v", p)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/divyag9/gothinnercontentservice/imaging"
+)
+
+// envPrefix starts the environment variable overriding each flag, so
+// servicebus_endpoint is overridden by CONTENTSERVICE_SERVICEBUS_ENDPOINT
+const envPrefix = "CONTENTSERVICE_"
+
+// Sources of a setting, from lowest to highest precedence
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceProfile = "profile"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// configFileFlags name the file and profile, so they are not read from it
+var configFileFlags = map[string]bool{"config_file": true, "profile": true, "print_config": true}
+
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(flagName)
+}
+
+// configFile is a parsed config file: settings keyed by flag name, and
+// named profiles of settings applied over them
+type configFile struct {
+	settings map[string]string
+	profiles map[string]map[string]string
+}
+
+// parseConfigFile decodes a config file from TOML if filename has a .toml
+// extension and from YAML otherwise
+func parseConfigFile(filename string, contents []byte) (*configFile, error) {
+	raw := make(map[string]interface{})
+	var err error
+	if strings.ToLower(filepath.Ext(filename)) == ".toml" {
+		_, err = toml.Decode(string(contents), &raw)
+	} else {
+		err = yaml.Unmarshal(contents, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing config file %s: %s", filename, err)
+	}
+	c := &configFile{profiles: make(map[string]map[string]string)}
+	if profiles, ok := raw["profiles"]; ok {
+		delete(raw, "profiles")
+		profileMap, ok := stringMap(profiles)
+		if !ok {
+			return nil, fmt.Errorf("Invalid config file %s: profiles must map names to settings", filename)
+		}
+		for name, settings := range profileMap {
+			settingsMap, ok := stringMap(settings)
+			if !ok {
+				return nil, fmt.Errorf("Invalid config file %s: profile %s must map names to settings", filename, name)
+			}
+			if c.profiles[name], err = settingValues(settingsMap); err != nil {
+				return nil, fmt.Errorf("Invalid config file %s: profile %s: %s", filename, name, err)
+			}
+		}
+	}
+	if c.settings, err = settingValues(raw); err != nil {
+		return nil, fmt.Errorf("Invalid config file %s: %s", filename, err)
+	}
+	return c, nil
+}
+
+// stringMap converts the maps produced by the YAML and TOML decoders
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			converted[fmt.Sprint(key)] = value
+		}
+		return converted, true
+	}
+	return nil, false
+}
+
+// settingValues formats each setting as a flag value. Lists are joined with
+// commas, as flags such as servicebus_endpoint expect.
+func settingValues(raw map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		case map[string]interface{}, map[interface{}]interface{}:
+			return nil, fmt.Errorf("setting %s must be a value or a list", name)
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+// loadConfig layers settings under the flags set on the command line: flag
+// defaults, then the config file, then the selected profile in it, then
+// CONTENTSERVICE_ environment variables. It returns the source of each
+// setting's effective value.
+func loadConfig(fs *flag.FlagSet, getenv func(string) string) (map[string]string, error) {
+	sources := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) { sources[f.Name] = sourceDefault })
+	fs.Visit(func(f *flag.Flag) { sources[f.Name] = sourceFlag })
+
+	values := make(map[string]string)
+	valueSources := make(map[string]string)
+	set := func(settings map[string]string, source, origin string) error {
+		for name, value := range settings {
+			if fs.Lookup(name) == nil || configFileFlags[name] {
+				return fmt.Errorf("Unknown setting %s in %s", name, origin)
+			}
+			values[name] = value
+			valueSources[name] = source
+		}
+		return nil
+	}
+	env := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if value := getenv(envName(f.Name)); value != "" {
+			env[f.Name] = value
+		}
+	})
+	// The config file and profile may themselves come from the environment
+	for name := range configFileFlags {
+		if value, ok := env[name]; ok && sources[name] != sourceFlag {
+			if err := fs.Set(name, value); err != nil {
+				return nil, fmt.Errorf("Invalid %s from %s: %s", name, envName(name), err)
+			}
+			sources[name] = sourceEnv
+		}
+	}
+
+	filename := fs.Lookup("config_file").Value.String()
+	profile := fs.Lookup("profile").Value.String()
+	if filename != "" {
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading config file: %s", err)
+		}
+		c, err := parseConfigFile(filename, contents)
+		if err != nil {
+			return nil, err
+		}
+		if err := set(c.settings, sourceFile, filename); err != nil {
+			return nil, err
+		}
+		if profile != "" {
+			settings, ok := c.profiles[profile]
+			if !ok {
+				return nil, fmt.Errorf("Unknown profile %q in %s", profile, filename)
+			}
+			if err := set(settings, sourceProfile, filename+" profile "+profile); err != nil {
+				return nil, err
+			}
+		}
+	} else if profile != "" {
+		return nil, fmt.Errorf("Profile %q requires a config_file", profile)
+	}
+	for name, value := range env {
+		if !configFileFlags[name] {
+			values[name] = value
+			valueSources[name] = sourceEnv
+		}
+	}
+
+	for name, value := range values {
+		if sources[name] == sourceFlag {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			origin := filename
+			if valueSources[name] == sourceEnv {
+				origin = envName(name)
+			}
+			return nil, fmt.Errorf("Invalid %s from %s: %s", name, origin, err)
+		}
+		sources[name] = valueSources[name]
+	}
+	return sources, nil
+}
+
+// validateConfig checks the effective settings, returning every problem found
+func validateConfig(fs *flag.FlagSet) error {
+	value := func(name string) string { return fs.Lookup(name).Value.String() }
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	for _, name := range []string{"port", "http_port", "debug_port", "admin_port"} {
+		port, err := strconv.Atoi(value(name))
+		check(err == nil && port >= 0 && port <= 65535, "%s must be between 0 and 65535, got %s", name, value(name))
+	}
+	if value("tls") == "true" {
+		check(value("cert_file") != "" && value("key_file") != "", "cert_file and key_file are required with tls")
+		_, ok := clientAuthTypes[value("client_auth")]
+		check(ok, "client_auth must be none, optional or required, got %q", value("client_auth"))
+	}
+	switch value("servicebus_strategy") {
+	case strategyRoundRobin, strategyLeastOutstanding, strategyFailover:
+	default:
+		check(false, "servicebus_strategy must be %s, %s or %s, got %q", strategyRoundRobin, strategyLeastOutstanding, strategyFailover, value("servicebus_strategy"))
+	}
+	for _, name := range []string{"servicebus_hedge_percentile", "shadow_sample_percent"} {
+		percent, _ := strconv.ParseFloat(value(name), 64)
+		check(percent >= 0 && percent <= 100, "%s must be between 0 and 100, got %s", name, value(name))
+	}
+	for _, endpoint := range strings.Split(value("servicebus_endpoint"), ",") {
+		u, err := url.Parse(endpoint)
+		check(err == nil && u.Host != "", "servicebus_endpoint %q is not a URL", endpoint)
+	}
+	_, err := time.LoadLocation(value("servicebus_timezone"))
+	check(err == nil, "servicebus_timezone %q is not a time zone", value("servicebus_timezone"))
+	if _, err := parseLogLevel(value("log_level")); err != nil {
+		check(false, "%s", err)
+	}
+	if _, err := imaging.ParseSizes(value("derivative_sizes")); err != nil {
+		check(false, "derivative_sizes: %s", err)
+	}
+	for _, name := range []string{"derivative_jpeg_quality", "rotate_jpeg_quality"} {
+		quality, _ := strconv.Atoi(value(name))
+		check(quality >= 1 && quality <= 100, "%s must be between 1 and 100, got %s", name, value(name))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// redact hides the passwords in the URLs of a setting's value. The only
+// secrets the settings hold are the credentials of servicebus endpoints:
+// tokens and keys are read from the files named by token_file, jwt_keys_file
+// and key_file, whose names are not secret.
+func redact(value string) string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		if u, err := url.Parse(part); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), "REDACTED")
+				parts[i] = u.String()
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// printConfig writes the effective settings as YAML, noting where each
+// value came from
+func printConfig(w io.Writer, fs *flag.FlagSet, sources map[string]string) {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if !configFileFlags[f.Name] {
+			names = append(names, f.Name)
+		}
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s # %s\n", name, strconv.Quote(redact(fs.Lookup(name).Value.String())), sources[name])
+	}
+}
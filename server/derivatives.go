@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"sync/atomic"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+	"github.com/divyag9/gothinnercontentservice/tracing"
+)
+
+// thumbnailDerivative names the derivative whose size is reported as the
+// thumbnailsize of a Put when ServiceBus does not report one
+const thumbnailDerivative = "thumbnail"
+
+// derive replaces the derivatives of request with those generated from its
+// contents. Contents which are not an image, such as PDF documents, have
+// none, and a failure to generate them does not fail the Put.
+func (s *Server) derive(ctx context.Context, request *pb.PutParams) {
+	request.Derivatives = nil
+	if s.Deriver == nil {
+		return
+	}
+	_, span := tracing.StartSpan(ctx, "derive")
+	defer span.End()
+	derivatives, err := s.Deriver.Derive(request.GetFilecontents())
+	if err == imaging.ErrUnsupported {
+		return
+	}
+	if err != nil {
+		span.SetError(err)
+		logger.Warningf("Failed to generate derivatives of %s for contractor %d order %d: %v", request.GetFilename(), request.GetContractorid(), request.GetOrdernumber(), err)
+		return
+	}
+	span.SetAttribute("derivatives", len(derivatives))
+	for _, derivative := range derivatives {
+		request.Derivatives = append(request.Derivatives, &pb.Derivative{
+			Name:     derivative.Name,
+			Width:    int32(derivative.Width),
+			Height:   int32(derivative.Height),
+			Mimetype: derivative.MimeType,
+			Contents: derivative.Contents,
+		})
+	}
+}
+
+// setThumbnailSize reports the size of the thumbnail sent with request if
+// ServiceBus did not report one
+func setThumbnailSize(response *pb.PutResponse, request *pb.PutParams) {
+	result := response.GetResult()
+	if result == nil || result.GetThumbnailsize() != 0 {
+		return
+	}
+	for _, derivative := range request.GetDerivatives() {
+		if derivative.GetName() == thumbnailDerivative {
+			result.Thumbnailsize = int32(len(derivative.GetContents()))
+		}
+	}
+}
+
+// reloadableDeriver derives with the sizes and quality most recently set,
+// sharing one cache
+type reloadableDeriver struct {
+	cache   *imaging.Cache
+	deriver atomic.Value // *imaging.Deriver
+}
+
+func newReloadableDeriver(cacheBytes int64) *reloadableDeriver {
+	r := &reloadableDeriver{cache: imaging.NewCache(cacheBytes)}
+	r.set(nil, 0, 0)
+	return r
+}
+
+// set changes the sizes derived, their JPEG quality and the largest image
+// resized
+func (r *reloadableDeriver) set(sizes []imaging.Size, quality, maxPixels int) {
+	r.deriver.Store(&imaging.Deriver{Sizes: sizes, Quality: quality, MaxPixels: maxPixels, Cache: r.cache})
+}
+
+func (r *reloadableDeriver) Derive(contents []byte) ([]imaging.Derivative, error) {
+	return r.deriver.Load().(*imaging.Deriver).Derive(contents)
+}
+
+// publish exposes the cache statistics as the derivative_cache expvar
+func (r *reloadableDeriver) publish() {
+	expvar.Publish("derivative_cache", expvar.Func(func() interface{} { return r.cache.Stats() }))
+}
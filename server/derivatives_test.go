@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+)
+
+type FakeDeriver struct {
+	Derivatives []imaging.Derivative
+	Err         error
+}
+
+func (f *FakeDeriver) Derive(contents []byte) ([]imaging.Derivative, error) {
+	return f.Derivatives, f.Err
+}
+
+type resultCaller struct {
+	result  *pb.JSONRPCResult
+	request *pb.JSONRPCRequest
+}
+
+func (r *resultCaller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	r.request = request
+	return &pb.JSONRPCResponse{Result: r.result}, nil
+}
+
+var deriveCases = []struct {
+	deriver               Deriver
+	response              *pb.JSONRPCResult
+	expectedDerivatives   int
+	expectedThumbnailSize int32
+}{
+	{
+		deriver:               &FakeDeriver{Derivatives: []imaging.Derivative{{Name: "thumbnail", Contents: []byte("thumb")}, {Name: "web", Contents: []byte("web")}}},
+		response:              &pb.JSONRPCResult{Id: 1},
+		expectedDerivatives:   2,
+		expectedThumbnailSize: 5,
+	},
+	{
+		deriver:               &FakeDeriver{Derivatives: []imaging.Derivative{{Name: "thumbnail", Contents: []byte("thumb")}}},
+		response:              &pb.JSONRPCResult{Id: 1, Thumbnailsize: 2048},
+		expectedDerivatives:   1,
+		expectedThumbnailSize: 2048,
+	},
+	{
+		deriver:  &FakeDeriver{Err: imaging.ErrUnsupported},
+		response: &pb.JSONRPCResult{Id: 1},
+	},
+	{
+		deriver:  &FakeDeriver{Err: errors.New("corrupt image")},
+		response: &pb.JSONRPCResult{Id: 1},
+	},
+	{
+		response: &pb.JSONRPCResult{Id: 1},
+	},
+}
+
+func TestPutDerivatives(t *testing.T) {
+	for _, c := range deriveCases {
+		caller := &resultCaller{result: c.response}
+		server := &Server{ServiceBusCaller: caller, Deriver: c.deriver}
+		request := &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png"}
+		response, err := server.Put(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Expected the put to succeed but got %v", err)
+		}
+		if derivatives := caller.request.GetParams().GetDerivatives(); len(derivatives) != c.expectedDerivatives {
+			t.Errorf("Expected %d derivatives sent to servicebus but got %v", c.expectedDerivatives, derivatives)
+		}
+		if size := response.GetResult().GetThumbnailsize(); size != c.expectedThumbnailSize {
+			t.Errorf("Expected thumbnail size %d but got %d", c.expectedThumbnailSize, size)
+		}
+	}
+}
+
+func TestReloadableDeriver(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	deriver := newReloadableDeriver(1 << 20)
+	if derivatives, err := deriver.Derive(buf.Bytes()); len(derivatives) != 0 || err != nil {
+		t.Errorf("Expected no derivatives before sizes are set but got %v, %v", derivatives, err)
+	}
+	deriver.set([]imaging.Size{{Name: "thumbnail", Width: 150, Height: 150}}, 85, 0)
+	derivatives, err := deriver.Derive(buf.Bytes())
+	if err != nil || len(derivatives) != 1 || derivatives[0].Width != 150 || derivatives[0].Height != 100 {
+		t.Errorf("Expected a 150x100 thumbnail but got %+v, %v", derivatives, err)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Strategies for choosing the ServiceBus endpoint a request is sent to
+const (
+	// strategyRoundRobin takes turns between the healthy endpoints
+	strategyRoundRobin = "round_robin"
+	// strategyLeastOutstanding picks the healthy endpoint with the fewest
+	// requests in flight
+	strategyLeastOutstanding = "least_outstanding"
+	// strategyFailover picks the first healthy endpoint in the order given,
+	// so the rest are only used while the primary is ejected
+	strategyFailover = "failover"
+)
+
+// endpoint is a ServiceBus node and its passive health
+type endpoint struct {
+	url         string
+	outstanding int64
+
+	mu           sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+}
+
+// endpointPool spreads ServiceBus requests over several endpoints, ejecting
+// an endpoint for ejectDuration after ejectFailures consecutive failures
+type endpointPool struct {
+	endpoints []*endpoint
+	strategy  string
+
+	mu            sync.RWMutex
+	ejectFailures int
+	ejectDuration time.Duration
+
+	next uint64
+	now  func() time.Time
+}
+
+// newEndpointPool creates a pool of the endpoint urls chosen between by
+// strategy. An ejectFailures of 0 never ejects an endpoint.
+func newEndpointPool(urls []string, strategy string, ejectFailures int, ejectDuration time.Duration) (*endpointPool, error) {
+	switch strategy {
+	case strategyRoundRobin, strategyLeastOutstanding, strategyFailover:
+	default:
+		return nil, fmt.Errorf("Unknown servicebus strategy %q", strategy)
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("At least one servicebus endpoint is required")
+	}
+	p := &endpointPool{strategy: strategy, ejectFailures: ejectFailures, ejectDuration: ejectDuration, now: time.Now}
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid servicebus endpoint %q: %s", u, err)
+		}
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("Invalid servicebus endpoint %q: host is required", u)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: u})
+	}
+	return p, nil
+}
+
+// setEjection changes when endpoints are ejected and for how long
+func (p *endpointPool) setEjection(failures int, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ejectFailures = failures
+	p.ejectDuration = duration
+}
+
+func (p *endpointPool) ejection() (int, time.Duration) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.ejectFailures, p.ejectDuration
+}
+
+func (e *endpoint) ejected(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.Before(e.ejectedUntil)
+}
+
+// pick chooses the endpoint for a request, skipping those already tried.
+// Ejected endpoints are only chosen when every untried endpoint is ejected.
+// It returns nil once every endpoint has been tried.
+func (p *endpointPool) pick(tried map[*endpoint]bool) *endpoint {
+	now := p.now()
+	var healthy, untried []*endpoint
+	for _, e := range p.endpoints {
+		if tried[e] {
+			continue
+		}
+		untried = append(untried, e)
+		if !e.ejected(now) {
+			healthy = append(healthy, e)
+		}
+	}
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = untried
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	switch p.strategy {
+	case strategyFailover:
+		return candidates[0]
+	case strategyLeastOutstanding:
+		// Start from the next endpoint in turn so ties are spread evenly
+		start := int(atomic.AddUint64(&p.next, 1) % uint64(len(candidates)))
+		best := candidates[start]
+		for i := 1; i < len(candidates); i++ {
+			e := candidates[(start+i)%len(candidates)]
+			if atomic.LoadInt64(&e.outstanding) < atomic.LoadInt64(&best.outstanding) {
+				best = e
+			}
+		}
+		return best
+	default:
+		return candidates[(atomic.AddUint64(&p.next, 1)-1)%uint64(len(candidates))]
+	}
+}
+
+// record updates the health of e with the outcome of a request to it
+func (p *endpointPool) record(e *endpoint, err error) {
+	ejectFailures, ejectDuration := p.ejection()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		if e.failures >= ejectFailures && ejectFailures > 0 {
+			logger.Infof("Servicebus endpoint %s is healthy again", e.url)
+		}
+		e.failures = 0
+		e.ejectedUntil = time.Time{}
+		return
+	}
+	e.failures++
+	if ejectFailures > 0 && e.failures >= ejectFailures {
+		now := p.now()
+		if !now.Before(e.ejectedUntil) {
+			logger.Warningf("Ejecting servicebus endpoint %s for %s after %d consecutive failures: %v", e.url, ejectDuration, e.failures, err)
+		}
+		e.ejectedUntil = now.Add(ejectDuration)
+	}
+}
+
+// isDialError reports whether err means the request never reached the
+// endpoint, so it is safe to send it to another
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Probe sends a GET to every endpoint, treating any response below 500 as
+// healthy, and records the outcome
+func (p *endpointPool) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			p.record(e, probe(ctx, e.url))
+		}(e)
+	}
+	wg.Wait()
+}
+
+func probe(ctx context.Context, endpointURL string) error {
+	req, err := http.NewRequest("GET", endpointURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("Probe returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Watch probes the endpoints every interval until stop is closed, giving
+// each round of probes up to interval to complete
+func (p *endpointPool) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			p.Probe(ctx)
+			cancel()
+		}
+	}
+}
+
+// endpointStatus is the published state of an endpoint
+type endpointStatus struct {
+	URL         string `json:"url"`
+	Outstanding int64  `json:"outstanding"`
+	Failures    int    `json:"consecutive_failures"`
+	Ejected     bool   `json:"ejected"`
+}
+
+func (p *endpointPool) status() []endpointStatus {
+	now := p.now()
+	statuses := make([]endpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		statuses = append(statuses, endpointStatus{
+			URL:         e.url,
+			Outstanding: atomic.LoadInt64(&e.outstanding),
+			Failures:    e.failures,
+			Ejected:     now.Before(e.ejectedUntil),
+		})
+		e.mu.Unlock()
+	}
+	return statuses
+}
+
+// publish exposes the state of each endpoint as the servicebus_endpoints expvar
+func (p *endpointPool) publish() {
+	expvar.Publish("servicebus_endpoints", expvar.Func(func() interface{} {
+		return p.status()
+	}))
+}
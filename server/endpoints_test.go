@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+var pickCases = []struct {
+	strategy    string
+	outstanding []int64
+	expected    []string
+}{
+	{strategy: strategyRoundRobin, outstanding: []int64{0, 0, 0}, expected: []string{"http://a", "http://b", "http://c", "http://a"}},
+	{strategy: strategyFailover, outstanding: []int64{5, 0, 0}, expected: []string{"http://a", "http://a"}},
+	{strategy: strategyLeastOutstanding, outstanding: []int64{5, 1, 3}, expected: []string{"http://b", "http://b"}},
+}
+
+func TestEndpointPoolPick(t *testing.T) {
+	for _, c := range pickCases {
+		pool, err := newEndpointPool([]string{"http://a", "http://b", "http://c"}, c.strategy, 0, 0)
+		if err != nil {
+			t.Fatalf("Error creating pool: %v", err)
+		}
+		for i, outstanding := range c.outstanding {
+			pool.endpoints[i].outstanding = outstanding
+		}
+		for _, expected := range c.expected {
+			if e := pool.pick(nil); e.url != expected {
+				t.Errorf("%s: expected %s but got %s", c.strategy, expected, e.url)
+			}
+		}
+	}
+}
+
+func TestEndpointPoolEjection(t *testing.T) {
+	pool, err := newEndpointPool([]string{"http://primary", "http://secondary"}, strategyFailover, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	primary := pool.endpoints[0]
+	pool.record(primary, errors.New("timeout"))
+	if e := pool.pick(nil); e != primary {
+		t.Errorf("Expected primary after one failure but got %s", e.url)
+	}
+	pool.record(primary, errors.New("timeout"))
+	if e := pool.pick(nil); e == primary {
+		t.Errorf("Expected primary to be ejected after two failures")
+	}
+	if e := pool.pick(map[*endpoint]bool{pool.endpoints[1]: true}); e != primary {
+		t.Errorf("Expected ejected primary when no other endpoint is left")
+	}
+	now = now.Add(2 * time.Minute)
+	if e := pool.pick(nil); e != primary {
+		t.Errorf("Expected primary once ejection expires but got %s", e.url)
+	}
+	pool.record(primary, errors.New("timeout"))
+	if e := pool.pick(nil); e == primary {
+		t.Errorf("Expected primary to be ejected again after failing on return")
+	}
+	pool.record(primary, nil)
+	if e := pool.pick(nil); e != primary {
+		t.Errorf("Expected primary after success but got %s", e.url)
+	}
+}
+
+func TestNewEndpointPoolErrors(t *testing.T) {
+	if _, err := newEndpointPool([]string{"http://a"}, "random", 0, 0); err == nil {
+		t.Errorf("Expected error for unknown strategy")
+	}
+	if _, err := newEndpointPool([]string{"http://a", ""}, strategyRoundRobin, 0, 0); err == nil {
+		t.Errorf("Expected error for empty endpoint")
+	}
+}
+
+// closedURL returns the URL of a port nothing is listening on
+func closedURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return "http://" + addr
+}
+
+func TestCallerFailover(t *testing.T) {
+	serviceBus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"id":1}}`))
+	}))
+	defer serviceBus.Close()
+	pool, err := newEndpointPool([]string{closedURL(t), serviceBus.URL}, strategyFailover, 1, time.Minute)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	caller := &Caller{endpoints: pool}
+	response, err := caller.callServiceBus(context.Background(), &pb.JSONRPCRequest{Method: putMethod})
+	if err != nil {
+		t.Fatalf("Expected failover to second endpoint but got %v", err)
+	}
+	if response.GetResult().GetId() != 1 {
+		t.Errorf("Unexpected response %v", response)
+	}
+	if status := pool.status(); !status[0].Ejected || status[1].Ejected {
+		t.Errorf("Expected only the unreachable endpoint to be ejected but got %+v", status)
+	}
+}
+
+func TestEndpointPoolProbe(t *testing.T) {
+	healthy := true
+	serviceBus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer serviceBus.Close()
+	pool, err := newEndpointPool([]string{serviceBus.URL}, strategyRoundRobin, 1, time.Minute)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	healthy = false
+	pool.Probe(context.Background())
+	if !pool.status()[0].Ejected {
+		t.Errorf("Expected endpoint returning 503 to be ejected")
+	}
+	healthy = true
+	pool.Probe(context.Background())
+	if pool.status()[0].Ejected {
+		t.Errorf("Expected endpoint to be reinstated by a successful probe")
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+	"github.com/divyag9/gothinnercontentservice/tracing"
+)
+
+// gatewayLimits bounds the size of HTTP request bodies
+type gatewayLimits struct {
+	// RequestBytes bounds a /v1/content, /v1/content:rotate or /v2/content
+	// request
+	RequestBytes int64
+	// UploadBytes bounds a whole /v1/uploads form
+	UploadBytes int64
+	// UploadFileBytes bounds each file within a /v1/uploads form
+	UploadFileBytes int64
+}
+
+// gateway serves the ContentService RPCs as HTTP/JSON, passing each call
+// through the same interceptors as the gRPC server
+type gateway struct {
+	*http.ServeMux
+	server      *Server
+	serverV2    *serverV2
+	interceptor grpc.UnaryServerInterceptor
+	// current holds the gatewayLimits in force
+	current atomic.Value
+}
+
+// newGateway creates the HTTP handler for server and its v2 service
+func newGateway(server *Server, v2 *serverV2, interceptors []grpc.UnaryServerInterceptor, limits gatewayLimits) *gateway {
+	g := &gateway{ServeMux: http.NewServeMux(), server: server, serverV2: v2, interceptor: chainUnaryInterceptors(interceptors)}
+	g.setLimits(limits)
+	g.HandleFunc("/v1/content", g.handlePut)
+	g.HandleFunc("/v1/content:rotate", g.handleRotate)
+	g.HandleFunc("/v1/uploads", g.handleUpload)
+	g.HandleFunc("/v2/content", g.handlePutV2)
+	return g
+}
+
+// setLimits changes the size bounds of the requests received from now on
+func (g *gateway) setLimits(limits gatewayLimits) {
+	g.current.Store(limits)
+}
+
+func (g *gateway) limits() gatewayLimits {
+	return g.current.Load().(gatewayLimits)
+}
+
+// chainUnaryInterceptors composes interceptors so that the first is outermost
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// incomingContext presents the HTTP request to interceptors as an incoming
+// gRPC call, carrying its authorization, trace, idempotency key and
+// priority headers as metadata and its TLS connection state as the peer
+func incomingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, header := range []string{"authorization", tracing.TraceparentHeader, idempotencyKeyHeader, priorityHeader} {
+		if value := r.Header.Get(header); value != "" {
+			md.Set(header, value)
+		}
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+	p := &peer.Peer{Addr: remoteAddr(r.RemoteAddr)}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+	return peer.NewContext(ctx, p)
+}
+
+// remoteAddr is the net.Addr of an HTTP client
+type remoteAddr string
+
+func (a remoteAddr) Network() string { return "tcp" }
+func (a remoteAddr) String() string  { return string(a) }
+
+func (g *gateway) invoke(r *http.Request, method string, req interface{}, handler grpc.UnaryHandler) (interface{}, error) {
+	info := &grpc.UnaryServerInfo{Server: g.server, FullMethod: method}
+	return g.interceptor(incomingContext(r), req, info, handler)
+}
+
+func (g *gateway) put(r *http.Request, request *pb.PutRequest) (interface{}, error) {
+	return g.invoke(r, "/contentservice.ContentService/Put", request, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.server.Put(ctx, req.(*pb.PutRequest))
+	})
+}
+
+func (g *gateway) handlePut(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeMethodNotAllowed(w, r, "POST")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, g.limits().RequestBytes)
+	request, err := g.decodePutRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response, err := g.put(r, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRotate rotates stored content as described by a JSON RotateRequest
+func (g *gateway) handleRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeMethodNotAllowed(w, r, "POST")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, g.limits().RequestBytes)
+	request := &pb.RotateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		writeError(w, bodyError(err, "Invalid JSON body: %s"))
+		return
+	}
+	response, err := g.invoke(r, "/contentservice.ContentService/Rotate", request, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.server.Rotate(ctx, req.(*pb.RotateRequest))
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handlePutV2 stores content as described by a v2 PutRequest in the proto
+// JSON mapping, in which enums are named and dates are objects
+func (g *gateway) handlePutV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeMethodNotAllowed(w, r, "POST")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, g.limits().RequestBytes)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, bodyError(err, "Error reading body: %s"))
+		return
+	}
+	request := &pbv2.PutRequest{}
+	if err := protojson.Unmarshal(body, request); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "Invalid JSON body: %s", err))
+		return
+	}
+	response, err := g.invoke(r, "/contentservice.v2.ContentService/Put", request, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.serverV2.Put(ctx, req.(*pbv2.PutRequest))
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeProtoJSON(w, http.StatusOK, response.(proto.Message))
+}
+
+// decodePutRequest reads a PutRequest from a multipart form with the file in
+// the "file" part, or from a JSON body with base64 encoded filecontents
+func (g *gateway) decodePutRequest(r *http.Request) (*pb.PutRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(g.limits().RequestBytes); err != nil {
+			return nil, bodyError(err, "Invalid multipart form: %s")
+		}
+		request, err := putRequestFromForm(r.FormValue)
+		if err != nil {
+			return nil, err
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Missing file part: %s", err)
+		}
+		defer file.Close()
+		request.Filecontents, err = ioutil.ReadAll(file)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Error reading file part: %s", err)
+		}
+		if request.Filename == "" {
+			request.Filename = header.Filename
+		}
+		return request, nil
+	case "application/json", "":
+		request := &pb.PutRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			return nil, bodyError(err, "Invalid JSON body: %s")
+		}
+		return request, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "Unsupported content type %q", mediaType)
+	}
+}
+
+// putRequestFromForm reads the PutRequest metadata fields from form values
+func putRequestFromForm(value func(string) string) (*pb.PutRequest, error) {
+	request := &pb.PutRequest{
+		Filename:    value("filename"),
+		Releasedate: value("releasedate"),
+		Deptcode:    value("deptcode"),
+	}
+	int64Fields := map[string]*int64{"contractorid": &request.Contractorid, "ordernumber": &request.Ordernumber}
+	for name, field := range int64Fields {
+		if v := value(name); v != "" {
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid %s: %s", name, err)
+			}
+			*field = parsed
+		}
+	}
+	int32Fields := map[string]*int32{"imagetype": &request.Imagetype, "imagewidth": &request.Imagewidth, "imageheight": &request.Imageheight}
+	for name, field := range int32Fields {
+		if v := value(name); v != "" {
+			parsed, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid %s: %s", name, err)
+			}
+			*field = int32(parsed)
+		}
+	}
+	return request, nil
+}
+
+// httpStatusCodes maps gRPC codes to HTTP statuses as the Google APIs do
+var httpStatusCodes = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// statusNames are the Google API names of the gRPC codes
+var statusNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// httpError is the Google API style JSON error body
+type httpError struct {
+	Error httpErrorDetail `json:"error"`
+}
+
+type httpErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// tooLargeError is a request body, or part of one, exceeding its limit. It is
+// invalid like any other bad body, but reported as 413 rather than 400.
+type tooLargeError struct {
+	limit int64
+}
+
+func (e *tooLargeError) Error() string {
+	return fmt.Sprintf("Request exceeds %d bytes", e.limit)
+}
+
+// bodyError returns the error for a request body which could not be read,
+// formatting err into an InvalidArgument unless the body was too large
+func bodyError(err error, format string) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &tooLargeError{limit: maxBytesErr.Limit}
+	}
+	return status.Errorf(codes.InvalidArgument, format, err)
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	if httpStatus, ok := httpStatusCodes[code]; ok {
+		return httpStatus
+	}
+	return http.StatusInternalServerError
+}
+
+func newHTTPErrorDetail(err error) *httpErrorDetail {
+	var tooLarge *tooLargeError
+	if errors.As(err, &tooLarge) {
+		return &httpErrorDetail{Code: http.StatusRequestEntityTooLarge, Message: err.Error(), Status: statusNames[codes.InvalidArgument]}
+	}
+	s := status.Convert(err)
+	name, ok := statusNames[s.Code()]
+	if !ok {
+		name = fmt.Sprintf("CODE_%d", s.Code())
+	}
+	return &httpErrorDetail{Code: httpStatusFromCode(s.Code()), Message: s.Message(), Status: name}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if delay, ok := retryDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(delay.Seconds())), 10))
+	}
+	detail := newHTTPErrorDetail(err)
+	writeJSON(w, detail.Code, &httpError{Error: *detail})
+}
+
+// writeMethodNotAllowed rejects a request with a method other than allowed.
+// There is no gRPC code for this, so it is reported like an unimplemented
+// method but with the HTTP status and Allow header clients expect.
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed string) {
+	w.Header().Set("Allow", allowed)
+	message := fmt.Sprintf("Method %s is not allowed for %s", r.Method, r.URL.Path)
+	writeJSON(w, http.StatusMethodNotAllowed, &httpError{Error: httpErrorDetail{Code: http.StatusMethodNotAllowed, Message: message, Status: statusNames[codes.Unimplemented]}})
+}
+
+// writeProtoJSON writes m in the proto JSON mapping, as the v2 messages
+// have no encoding/json form
+func writeProtoJSON(w http.ResponseWriter, httpStatus int, m proto.Message) {
+	body, err := protojson.Marshal(m)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "Error encoding response: %s", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(body)
+}
+
+func writeJSON(w http.ResponseWriter, httpStatus int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+)
+
+type recordingCaller struct {
+	request *pb.JSONRPCRequest
+}
+
+func (r *recordingCaller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	r.request = request
+	return &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1810448062}}, nil
+}
+
+func newTestGateway(t *testing.T) (http.Handler, *recordingCaller) {
+	tokens, err := auth.NewStaticTokens([]auth.StaticToken{{Token: "s3cret", Subject: "portal"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller := &recordingCaller{}
+	server := &Server{ServiceBusCaller: caller}
+	interceptors := []grpc.UnaryServerInterceptor{auth.UnaryServerInterceptor(tokens)}
+	return newGateway(server, newServerV2(server, time.UTC), interceptors, gatewayLimits{RequestBytes: 1 << 20, UploadBytes: 1 << 20, UploadFileBytes: 16}), caller
+}
+
+func multipartBody(t *testing.T, fields map[string]string, filename string, contents []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(contents)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestGatewayPutJSON(t *testing.T) {
+	handler, caller := newTestGateway(t)
+	body := `{"contractorid":72494,"ordernumber":600016555,"filename":"test.png","filecontents":"iVBORw0K"}`
+	req := httptest.NewRequest("POST", "/v1/content", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body)
+	}
+	response := &pb.PutResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil || response.GetResult().GetId() != 1810448062 {
+		t.Errorf("Unexpected response %s, %v", w.Body, err)
+	}
+	expected := &pb.PutParams{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png", Filecontents: []byte{0x89, 'P', 'N', 'G', '\r', '\n'}}
+	if !reflect.DeepEqual(caller.request.Params, expected) {
+		t.Errorf("Expected %v but got %v", expected, caller.request.Params)
+	}
+}
+
+func TestGatewayPutMultipart(t *testing.T) {
+	handler, caller := newTestGateway(t)
+	fields := map[string]string{"contractorid": "72494", "ordernumber": "600016555", "imagetype": "1", "deptcode": "01"}
+	body, contentType := multipartBody(t, fields, "photo.png", []byte("png"))
+	req := httptest.NewRequest("POST", "/v1/content", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body)
+	}
+	expected := &pb.PutParams{Contractorid: 72494, Ordernumber: 600016555, Imagetype: 1, Deptcode: "01", Filename: "photo.png", Filecontents: []byte("png")}
+	if !reflect.DeepEqual(caller.request.Params, expected) {
+		t.Errorf("Expected %v but got %v", expected, caller.request.Params)
+	}
+}
+
+func TestGatewayPutV2(t *testing.T) {
+	handler, caller := newTestGateway(t)
+	body := `{"contractorId":"72494","orderNumber":"600016555","imageType":"IMAGE_TYPE_DOCUMENT","filename":"test.pdf",` +
+		`"releaseDate":{"year":2015,"month":8,"day":6},"fileContents":"JVBERi0=","destination":"DESTINATION_VENDORWEB","vendorWeb":{"documentId":"7"}}`
+	req := httptest.NewRequest("POST", "/v2/content", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body)
+	}
+	response := &pbv2.PutResponse{}
+	if err := protojson.Unmarshal(w.Body.Bytes(), response); err != nil || response.GetResult().GetId() != 1810448062 {
+		t.Errorf("Unexpected response %s, %v", w.Body, err)
+	}
+	if caller.request.Method != "CONTENTSERVICE.VENDORWEBPUT" {
+		t.Errorf("Expected VendorWeb put method but got %s", caller.request.Method)
+	}
+	expected := &pb.PutParams{Contractorid: 72494, Ordernumber: 600016555, Imagetype: 2, Filename: "test.pdf", Releasedate: "2015-08-06",
+		Filecontents: []byte("%PDF-"), Vendorwebrequestdata: &pb.VendorWebPutRequest{Documentid: 7}}
+	if !proto.Equal(caller.request.Params, expected) {
+		t.Errorf("Expected %v but got %v", expected, caller.request.Params)
+	}
+
+	req = httptest.NewRequest("POST", "/v2/content", bytes.NewBufferString(`{"contractor_id":"72494","destination":"DESTINATION_MARS"}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown destination but got %d: %s", w.Code, w.Body)
+	}
+}
+
+var gatewayErrorCases = []struct {
+	method         string
+	contentType    string
+	authorization  string
+	body           string
+	expectedStatus int
+	expectedName   string
+}{
+	{method: "POST", contentType: "application/json", body: `{"contractorid":72494,"ordernumber":1,"filename":"a.png"}`, expectedStatus: http.StatusUnauthorized, expectedName: "UNAUTHENTICATED"},
+	{method: "POST", contentType: "application/json", authorization: "Bearer s3cret", body: `{`, expectedStatus: http.StatusBadRequest, expectedName: "INVALID_ARGUMENT"},
+	{method: "POST", contentType: "text/plain", authorization: "Bearer s3cret", body: `hello`, expectedStatus: http.StatusBadRequest, expectedName: "INVALID_ARGUMENT"},
+	{method: "POST", contentType: "application/json", authorization: "Bearer s3cret", body: `{"filename":"` + strings.Repeat("a", 1<<20) + `"}`, expectedStatus: http.StatusRequestEntityTooLarge, expectedName: "INVALID_ARGUMENT"},
+	{method: "GET", authorization: "Bearer s3cret", expectedStatus: http.StatusMethodNotAllowed, expectedName: "UNIMPLEMENTED"},
+}
+
+func TestGatewayErrors(t *testing.T) {
+	handler, _ := newTestGateway(t)
+	for _, c := range gatewayErrorCases {
+		req := httptest.NewRequest(c.method, "/v1/content", bytes.NewBufferString(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		errorBody := &httpError{}
+		if err := json.Unmarshal(w.Body.Bytes(), errorBody); err != nil {
+			t.Errorf("Expected JSON error but got %s", w.Body)
+			continue
+		}
+		if w.Code != c.expectedStatus || errorBody.Error.Code != c.expectedStatus || errorBody.Error.Status != c.expectedName {
+			t.Errorf("Expected %d %s but got %d %+v", c.expectedStatus, c.expectedName, w.Code, errorBody)
+		}
+		if allow := w.Header().Get("Allow"); c.expectedStatus == http.StatusMethodNotAllowed && allow != "POST" {
+			t.Errorf("Expected Allow: POST but got %q", allow)
+		}
+	}
+}
+
+func TestGatewayRotate(t *testing.T) {
+	tokens, err := auth.NewStaticTokens([]auth.StaticToken{{Token: "s3cret", Subject: "portal"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceBus := &storedContent{stored: &pb.JSONRPCResult{Id: 1810448062, Contractorid: 72494, Ordernumber: 600016555, Deptcode: "01"}}
+	server := &Server{ServiceBusCaller: serviceBus, Rotator: &FakeRotator{}}
+	handler := newGateway(server, nil, []grpc.UnaryServerInterceptor{auth.UnaryServerInterceptor(tokens)}, gatewayLimits{RequestBytes: 1 << 20})
+	body := `{"contractorid":72494,"ordernumber":600016555,"id":1810448062,"deptcode":"01","degrees":90}`
+	req := httptest.NewRequest("POST", "/v1/content:rotate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body)
+	}
+	response := &pb.PutResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil || response.GetResult().GetId() != 1810448062 || response.GetResult().GetImagerotated() != 90 {
+		t.Errorf("Unexpected response %s, %v", w.Body, err)
+	}
+	if string(serviceBus.update.GetFilecontents()) != "rotated" {
+		t.Errorf("Expected the rotated content to be stored but got %+v", serviceBus.update)
+	}
+
+	req = httptest.NewRequest("GET", "/v1/content:rotate", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("Expected 405 allowing POST but got %d allowing %q", w.Code, w.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// idempotencyKeyHeader carries the key making a Put safe to send twice. It
+// is forwarded to ServiceBus so it can discard the duplicate.
+const idempotencyKeyHeader = "idempotency-key"
+
+// idempotencyKey returns the idempotency key the caller sent, if any
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// readKey marks a context as that of a call which only reads from ServiceBus
+type readKey struct{}
+
+// withRead marks ctx as that of a read, which is safe to send twice without
+// an idempotency key
+func withRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readKey{}, true)
+}
+
+// isRead reports whether ctx was marked by withRead
+func isRead(ctx context.Context) bool {
+	read, _ := ctx.Value(readKey{}).(bool)
+	return read
+}
+
+// latencyWindow holds the most recent ServiceBus latencies
+type latencyWindow struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{latencies: make([]time.Duration, 0, size)}
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.latencies) < cap(w.latencies) {
+		w.latencies = append(w.latencies, latency)
+		return
+	}
+	w.latencies[w.next] = latency
+	w.next = (w.next + 1) % len(w.latencies)
+}
+
+// percentile returns the latency below which p percent of the window falls,
+// or 0 if the window is empty
+func (w *latencyWindow) percentile(p float64) time.Duration {
+	w.mu.Lock()
+	sorted := append([]time.Duration(nil), w.latencies...)
+	w.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// hedging sends a second attempt of a read, or of a request with an
+// idempotency key, to another endpoint when the first has not responded
+// within the given percentile of recent latencies, and never sooner than
+// minDelay. A zero percentile disables hedging.
+type hedging struct {
+	mu         sync.RWMutex
+	percentile float64
+	minDelay   time.Duration
+	latencies  *latencyWindow
+
+	requests expvar.Int
+	hedges   expvar.Int
+	wins     expvar.Int
+}
+
+func newHedging(percentile float64, minDelay time.Duration) *hedging {
+	return &hedging{percentile: percentile, minDelay: minDelay, latencies: newLatencyWindow(1000)}
+}
+
+// set changes the percentile and minimum delay after which requests are hedged
+func (h *hedging) set(percentile float64, minDelay time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.percentile = percentile
+	h.minDelay = minDelay
+}
+
+// enabled reports whether requests are hedged
+func (h *hedging) enabled() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.percentile > 0
+}
+
+// delay is how long to wait for the first attempt before hedging
+func (h *hedging) delay() time.Duration {
+	h.mu.RLock()
+	percentile, minDelay := h.percentile, h.minDelay
+	h.mu.RUnlock()
+	if d := h.latencies.percentile(percentile); d > minDelay {
+		return d
+	}
+	return minDelay
+}
+
+// publish exposes the hedged request counts as the servicebus_hedging expvar
+func (h *hedging) publish() {
+	m := expvar.NewMap("servicebus_hedging")
+	m.Set("requests", &h.requests)
+	m.Set("hedges", &h.hedges)
+	m.Set("hedge_wins", &h.wins)
+	m.Set("hedge_rate", expvar.Func(func() interface{} {
+		return ratio(h.hedges.Value(), h.requests.Value())
+	}))
+	m.Set("hedge_win_rate", expvar.Func(func() interface{} {
+		return ratio(h.wins.Value(), h.hedges.Value())
+	}))
+}
+
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+type attemptResult struct {
+	body       []byte
+	statusCode int
+	err        error
+	hedge      bool
+}
+
+// failed reports whether the attempt failed, by not reaching ServiceBus or
+// by ServiceBus returning a server error
+func (r attemptResult) failed() bool {
+	return r.err != nil || r.statusCode >= 500
+}
+
+// sendHedged posts requestBytes to an endpoint and, if it is slow to
+// respond, to a second one, returning the first success and cancelling the
+// other attempt. An endpoint that cannot be reached is replaced straight away.
+// If every attempt fails, the first failure is returned as send would.
+func (c *Caller) sendHedged(ctx context.Context, method string, requestBytes []byte) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.hedging.requests.Add(1)
+	results := make(chan attemptResult, len(c.endpoints.endpoints))
+	tried := make(map[*endpoint]bool)
+	launch := func(hedge bool) bool {
+		e := c.endpoints.pick(tried)
+		if e == nil {
+			return false
+		}
+		tried[e] = true
+		go func() {
+			body, statusCode, err := c.attempt(ctx, e, method, requestBytes)
+			results <- attemptResult{body: body, statusCode: statusCode, err: err, hedge: hedge}
+		}()
+		return true
+	}
+
+	launch(false)
+	pending := 1
+	hedged := false
+	timer := time.NewTimer(c.hedging.delay())
+	defer timer.Stop()
+	var firstFailure *attemptResult
+	for {
+		select {
+		case <-timer.C:
+			if !hedged && launch(true) {
+				hedged = true
+				pending++
+				c.hedging.hedges.Add(1)
+			}
+		case result := <-results:
+			pending--
+			if !result.failed() {
+				if result.hedge {
+					c.hedging.wins.Add(1)
+				}
+				return result.body, nil
+			}
+			if firstFailure == nil {
+				firstFailure = &result
+			}
+			if isDialError(result.err) && launch(result.hedge) {
+				pending++
+				continue
+			}
+			if pending == 0 {
+				return firstFailure.body, firstFailure.err
+			}
+		}
+	}
+}
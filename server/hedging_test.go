@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+func TestLatencyWindowPercentile(t *testing.T) {
+	w := newLatencyWindow(10)
+	if p := w.percentile(95); p != 0 {
+		t.Errorf("Expected 0 for empty window but got %s", p)
+	}
+	for i := 1; i <= 20; i++ {
+		w.add(time.Duration(i) * time.Millisecond)
+	}
+	if p := w.percentile(50); p != 15*time.Millisecond {
+		t.Errorf("Expected p50 of the last 10 latencies to be 15ms but got %s", p)
+	}
+	if p := w.percentile(100); p != 20*time.Millisecond {
+		t.Errorf("Expected p100 to be 20ms but got %s", p)
+	}
+}
+
+// newHedgingServiceBus returns a ServiceBus answering after delay and
+// counting the requests that were cancelled before it answered
+func newHedgingServiceBus(delay time.Duration, id string, cancelled *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The connection is only watched for the client going away once
+		// the body has been read
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-time.After(delay):
+			w.Write([]byte(`{"jsonrpc":"2.0","result":{"id":` + id + `}}`))
+		case <-r.Context().Done():
+			atomic.AddInt32(cancelled, 1)
+		}
+	}))
+}
+
+func TestCallerHedging(t *testing.T) {
+	var cancelled int32
+	slow := newHedgingServiceBus(5*time.Second, "1", &cancelled)
+	defer slow.Close()
+	fast := newHedgingServiceBus(0, "2", &cancelled)
+	defer fast.Close()
+	pool, err := newEndpointPool([]string{slow.URL, fast.URL}, strategyFailover, 0, 0)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	caller := &Caller{endpoints: pool, hedging: newHedging(95, 20*time.Millisecond)}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotencyKeyHeader, "order-1-a.png"))
+	start := time.Now()
+	response, err := caller.callServiceBus(ctx, &pb.JSONRPCRequest{Method: putMethod})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if response.GetResult().GetId() != 2 || time.Since(start) > time.Second {
+		t.Errorf("Expected the hedge to the fast endpoint to win but got %v after %s", response, time.Since(start))
+	}
+	if caller.hedging.hedges.Value() != 1 || caller.hedging.wins.Value() != 1 {
+		t.Errorf("Expected 1 hedge and 1 win but got %d and %d", caller.hedging.hedges.Value(), caller.hedging.wins.Value())
+	}
+	for i := 0; i < 100 && atomic.LoadInt32(&cancelled) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Errorf("Expected the losing attempt to be cancelled")
+	}
+}
+
+func TestCallerHedgingRequiresIdempotencyKey(t *testing.T) {
+	var cancelled int32
+	slow := newHedgingServiceBus(100*time.Millisecond, "1", &cancelled)
+	defer slow.Close()
+	fast := newHedgingServiceBus(0, "2", &cancelled)
+	defer fast.Close()
+	pool, err := newEndpointPool([]string{slow.URL, fast.URL}, strategyFailover, 0, 0)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	caller := &Caller{endpoints: pool, hedging: newHedging(95, 10*time.Millisecond)}
+	response, err := caller.callServiceBus(context.Background(), &pb.JSONRPCRequest{Method: putMethod})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if response.GetResult().GetId() != 1 || caller.hedging.hedges.Value() != 0 {
+		t.Errorf("Expected a put without an idempotency key not to be hedged but got %v", response)
+	}
+}
+
+func TestCallerHedgingIgnoresServerErrors(t *testing.T) {
+	var cancelled int32
+	slow := newHedgingServiceBus(100*time.Millisecond, "1", &cancelled)
+	defer slow.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Unavailable"}}`))
+	}))
+	defer failing.Close()
+	pool, err := newEndpointPool([]string{slow.URL, failing.URL}, strategyFailover, 0, 0)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	caller := &Caller{endpoints: pool, hedging: newHedging(95, 10*time.Millisecond)}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotencyKeyHeader, "order-1-a.png"))
+	response, err := caller.callServiceBus(ctx, &pb.JSONRPCRequest{Method: putMethod})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if response.GetResult().GetId() != 1 || caller.hedging.hedges.Value() != 1 || caller.hedging.wins.Value() != 0 {
+		t.Errorf("Expected the first attempt to win over a hedge failing with a server error but got %v", response)
+	}
+}
+
+func TestCallerHedgesReads(t *testing.T) {
+	var cancelled int32
+	slow := newHedgingServiceBus(5*time.Second, "1", &cancelled)
+	defer slow.Close()
+	fast := newHedgingServiceBus(0, "2", &cancelled)
+	defer fast.Close()
+	pool, err := newEndpointPool([]string{slow.URL, fast.URL}, strategyFailover, 0, 0)
+	if err != nil {
+		t.Fatalf("Error creating pool: %v", err)
+	}
+	caller := &Caller{endpoints: pool, hedging: newHedging(95, 10*time.Millisecond)}
+	response, err := caller.callServiceBus(withRead(context.Background()), &pb.JSONRPCRequest{Method: getMethod})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if response.GetResult().GetId() != 2 || caller.hedging.hedges.Value() != 1 {
+		t.Errorf("Expected a read without an idempotency key to be hedged but got %v", response)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// callObserver is told the outcome of every call, e.g. to export metrics
+type callObserver interface {
+	observeCall(method string, code codes.Code, duration time.Duration)
+}
+
+// serverOptions chooses the interceptors every call is served through
+type serverOptions struct {
+	recoverPanics   bool
+	normalizeErrors bool
+	observer        callObserver
+}
+
+// serverOption configures the interceptors made by newInterceptors
+type serverOption func(*serverOptions)
+
+// withPanicRecovery turns panicking calls into Internal errors, logging the
+// stack trace, rather than letting them crash the process
+func withPanicRecovery(enabled bool) serverOption {
+	return func(o *serverOptions) { o.recoverPanics = enabled }
+}
+
+// withErrorNormalization maps errors without a gRPC status to the code
+// that best describes them
+func withErrorNormalization(enabled bool) serverOption {
+	return func(o *serverOptions) { o.normalizeErrors = enabled }
+}
+
+// withCallObserver reports the code and duration of every call to observer
+func withCallObserver(observer callObserver) serverOption {
+	return func(o *serverOptions) { o.observer = observer }
+}
+
+// newInterceptors returns the interceptors chosen by opts: outer ones to
+// chain before all others, so they see the final outcome of a call, and
+// inner ones to chain after all others, right around the handler. Panic
+// recovery and error normalization are on unless turned off. Panics are
+// recovered both around the handler, so that the interceptors in between
+// see an Internal error, and around those interceptors themselves.
+func newInterceptors(opts ...serverOption) (outer, inner []grpc.UnaryServerInterceptor) {
+	o := &serverOptions{recoverPanics: true, normalizeErrors: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.observer != nil {
+		outer = append(outer, observeInterceptor(o.observer))
+	}
+	if o.recoverPanics {
+		outer = append(outer, recoverInterceptor)
+		inner = append(inner, recoverInterceptor)
+	}
+	if o.normalizeErrors {
+		inner = append(inner, normalizeInterceptor)
+	}
+	return outer, inner
+}
+
+// recoverInterceptor converts a panic in the handler, or the interceptors
+// chained after it, into an Internal error
+func recoverInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Panic serving %s: %v\n%s", info.FullMethod, r, debug.Stack())
+			resp, err = nil, status.Error(codes.Internal, "Internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// normalizeInterceptor gives every error returned by the handler a gRPC code
+func normalizeInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, normalizeError(err)
+}
+
+// normalizeError returns err unchanged if it carries a gRPC status, and
+// otherwise a status whose code describes it, such as Unavailable for a
+// ServiceBus endpoint that cannot be reached
+func normalizeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.As(err, &netErr):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return status.Errorf(codes.Internal, "Invalid response from ServiceBus: %s", err)
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// observeInterceptor reports the outcome of each call to observer
+func observeInterceptor(observer callObserver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observer.observeCall(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// callMetrics counts calls by method and code, and sums their durations by
+// method, as the grpc_calls and grpc_call_duration_ms expvars
+type callMetrics struct {
+	calls     *expvar.Map
+	durations *expvar.Map
+}
+
+func newCallMetrics() *callMetrics {
+	return &callMetrics{calls: new(expvar.Map).Init(), durations: new(expvar.Map).Init()}
+}
+
+func (m *callMetrics) observeCall(method string, code codes.Code, duration time.Duration) {
+	m.calls.Add(method+" "+code.String(), 1)
+	m.durations.AddFloat(method, float64(duration)/float64(time.Millisecond))
+}
+
+// publish exposes the call counts and durations
+func (m *callMetrics) publish() {
+	expvar.Publish("grpc_calls", m.calls)
+	expvar.Publish("grpc_call_duration_ms", m.durations)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+type FakeObserver struct {
+	Method string
+	Code   codes.Code
+}
+
+func (f *FakeObserver) observeCall(method string, code codes.Code, duration time.Duration) {
+	f.Method, f.Code = method, code
+}
+
+type PanickingServer struct{}
+
+func (p *PanickingServer) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	panic("nil map")
+}
+
+var normalizeCases = []struct {
+	err      error
+	expected codes.Code
+}{
+	{err: nil, expected: codes.OK},
+	{err: status.Error(codes.NotFound, "missing"), expected: codes.NotFound},
+	{err: context.DeadlineExceeded, expected: codes.DeadlineExceeded},
+	{err: fmt.Errorf("Calling servicebus: %w", context.Canceled), expected: codes.Canceled},
+	{err: &url.Error{Op: "Post", URL: "http://servicebus", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, expected: codes.Unavailable},
+	{err: &net.DNSError{Err: "timeout", IsTimeout: true}, expected: codes.DeadlineExceeded},
+	{err: json.Unmarshal([]byte("<html>"), &struct{}{}), expected: codes.Internal},
+	{err: errors.New("something else"), expected: codes.Unknown},
+}
+
+func TestNormalizeError(t *testing.T) {
+	for _, c := range normalizeCases {
+		if code := status.Code(normalizeError(c.err)); code != c.expected {
+			t.Errorf("Expected %v for %v but got %v", c.expected, c.err, code)
+		}
+	}
+}
+
+var interceptorCases = []struct {
+	opts     []serverOption
+	handler  grpc.UnaryHandler
+	expected codes.Code
+	panics   bool
+}{
+	{
+		handler:  func(ctx context.Context, req interface{}) (interface{}, error) { panic("nil map") },
+		expected: codes.Internal,
+	},
+	{
+		handler:  func(ctx context.Context, req interface{}) (interface{}, error) { return nil, context.DeadlineExceeded },
+		expected: codes.DeadlineExceeded,
+	},
+	{
+		opts:     []serverOption{withErrorNormalization(false)},
+		handler:  func(ctx context.Context, req interface{}) (interface{}, error) { return nil, context.DeadlineExceeded },
+		expected: codes.Unknown,
+	},
+	{
+		opts:    []serverOption{withPanicRecovery(false)},
+		handler: func(ctx context.Context, req interface{}) (interface{}, error) { panic("nil map") },
+		panics:  true,
+	},
+}
+
+func TestInterceptors(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/contentservice.ContentService/Put"}
+	for _, c := range interceptorCases {
+		observer := &FakeObserver{}
+		outer, inner := newInterceptors(append(c.opts, withCallObserver(observer))...)
+		interceptor := chainUnaryInterceptors(append(outer, inner...))
+		var err error
+		panicked := func() (panicked bool) {
+			defer func() { panicked = recover() != nil }()
+			_, err = interceptor(context.Background(), nil, info, c.handler)
+			return false
+		}()
+		if panicked != c.panics {
+			t.Errorf("Expected panic %v but got %v", c.panics, panicked)
+			continue
+		}
+		if c.panics {
+			continue
+		}
+		if code := status.Code(err); code != c.expected {
+			t.Errorf("Expected %v but got %v", c.expected, code)
+		}
+		if observer.Method != info.FullMethod || observer.Code != c.expected {
+			t.Errorf("Expected %s observed with %v but got %s with %v", info.FullMethod, c.expected, observer.Method, observer.Code)
+		}
+	}
+}
+
+func TestPutRecoversFromPanic(t *testing.T) {
+	_, inner := newInterceptors()
+	server := &Server{ServiceBusCaller: &PanickingServer{}}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return server.Put(ctx, req.(*pb.PutRequest))
+	}
+	_, err := chainUnaryInterceptors(inner)(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png"}, &grpc.UnaryServerInfo{FullMethod: "/contentservice.ContentService/Put"}, handler)
+	if code := status.Code(err); code != codes.Internal {
+		t.Errorf("Expected %v but got %v", codes.Internal, err)
+	}
+}
+
+func TestCallMetrics(t *testing.T) {
+	metrics := newCallMetrics()
+	metrics.observeCall("/contentservice.ContentService/Put", codes.OK, 2*time.Millisecond)
+	metrics.observeCall("/contentservice.ContentService/Put", codes.OK, 3*time.Millisecond)
+	if calls := metrics.calls.Get("/contentservice.ContentService/Put OK").String(); calls != "2" {
+		t.Errorf("Expected 2 calls but got %s", calls)
+	}
+	if duration := metrics.durations.Get("/contentservice.ContentService/Put").String(); duration != "5" {
+		t.Errorf("Expected 5ms but got %s", duration)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// Log levels, from most to least verbose
+const (
+	levelDebug int32 = iota
+	levelInfo
+	levelWarning
+	levelError
+)
+
+var logLevelNames = []string{"debug", "info", "warning", "error"}
+
+func parseLogLevel(name string) (int32, error) {
+	for level, levelName := range logLevelNames {
+		if levelName == name {
+			return int32(level), nil
+		}
+	}
+	return 0, fmt.Errorf("log_level must be debug, info, warning or error, got %q", name)
+}
+
+// leveledLogger is the grpclog.LoggerV2 of the server, dropping messages
+// below a level that can be changed while serving
+type leveledLogger struct {
+	level int32
+}
+
+// logger writes the server's own messages as well as gRPC's, at the level
+// set by log_level
+var logger = newLeveledLogger(levelInfo)
+
+func newLeveledLogger(level int32) *leveledLogger {
+	return &leveledLogger{level: level}
+}
+
+// setLevel changes the least severe level logged
+func (l *leveledLogger) setLevel(level int32) {
+	atomic.StoreInt32(&l.level, level)
+}
+
+// levelName is the name of the least severe level logged
+func (l *leveledLogger) levelName() string {
+	return logLevelNames[atomic.LoadInt32(&l.level)]
+}
+
+func (l *leveledLogger) output(level int32, prefix, message string) {
+	if level >= atomic.LoadInt32(&l.level) {
+		log.Output(3, prefix+message)
+	}
+}
+
+func (l *leveledLogger) Debugf(format string, args ...interface{}) {
+	l.output(levelDebug, "DEBUG: ", fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Info(args ...interface{}) {
+	l.output(levelInfo, "INFO: ", fmt.Sprint(args...))
+}
+
+func (l *leveledLogger) Infoln(args ...interface{}) {
+	l.output(levelInfo, "INFO: ", fmt.Sprintln(args...))
+}
+
+func (l *leveledLogger) Infof(format string, args ...interface{}) {
+	l.output(levelInfo, "INFO: ", fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Warning(args ...interface{}) {
+	l.output(levelWarning, "WARNING: ", fmt.Sprint(args...))
+}
+
+func (l *leveledLogger) Warningln(args ...interface{}) {
+	l.output(levelWarning, "WARNING: ", fmt.Sprintln(args...))
+}
+
+func (l *leveledLogger) Warningf(format string, args ...interface{}) {
+	l.output(levelWarning, "WARNING: ", fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Error(args ...interface{}) {
+	l.output(levelError, "ERROR: ", fmt.Sprint(args...))
+}
+
+func (l *leveledLogger) Errorln(args ...interface{}) {
+	l.output(levelError, "ERROR: ", fmt.Sprintln(args...))
+}
+
+func (l *leveledLogger) Errorf(format string, args ...interface{}) {
+	l.output(levelError, "ERROR: ", fmt.Sprintf(format, args...))
+}
+
+func (l *leveledLogger) Fatal(args ...interface{}) {
+	log.Output(2, "FATAL: "+fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (l *leveledLogger) Fatalln(args ...interface{}) {
+	log.Output(2, "FATAL: "+fmt.Sprintln(args...))
+	os.Exit(1)
+}
+
+func (l *leveledLogger) Fatalf(format string, args ...interface{}) {
+	log.Output(2, "FATAL: "+fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// V reports whether verbose messages are logged, which they are at debug level
+func (l *leveledLogger) V(verbosity int) bool {
+	return verbosity <= 0 || atomic.LoadInt32(&l.level) == levelDebug
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+var leveledLoggerCases = []struct {
+	level    int32
+	expected []string
+}{
+	{levelDebug, []string{"DEBUG: sending", "INFO: loaded", "WARNING: ejecting", "ERROR: panic"}},
+	{levelInfo, []string{"INFO: loaded", "WARNING: ejecting", "ERROR: panic"}},
+	{levelError, []string{"ERROR: panic"}},
+}
+
+func TestLeveledLogger(t *testing.T) {
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer log.SetFlags(flags)
+	defer log.SetOutput(os.Stderr)
+	for _, c := range leveledLoggerCases {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		l := newLeveledLogger(levelInfo)
+		l.setLevel(c.level)
+		l.Debugf("sending")
+		l.Infof("loaded")
+		l.Warningf("ejecting")
+		l.Errorf("panic")
+
+		actual := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if strings.Join(actual, "|") != strings.Join(c.expected, "|") {
+			t.Errorf("Expected %v but got %v", c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/ratelimit"
+	"github.com/divyag9/gothinnercontentservice/tracing"
+)
+
+// rateLimit returns a ResourceExhausted error if request exceeds a rate
+// limit, telling the caller when to retry in the retry-after trailer and as
+// RetryInfo in the status details
+func (s *Server) rateLimit(ctx context.Context, request *pb.PutParams) error {
+	if s.RateLimiter == nil {
+		return nil
+	}
+	return s.limit(ctx, request, s.RateLimiter.Allow)
+}
+
+// rateLimitBytes is rateLimit for the contents of a request whose call was
+// already allowed, such as a Rotate charged before it read the content
+func (s *Server) rateLimitBytes(ctx context.Context, request *pb.PutParams) error {
+	if s.RateLimiter == nil {
+		return nil
+	}
+	return s.limit(ctx, request, s.RateLimiter.AllowBytes)
+}
+
+func (s *Server) limit(ctx context.Context, request *pb.PutParams, allow func(contractorID int64, caller string, size int64) error) error {
+	_, span := tracing.StartSpan(ctx, "ratelimit")
+	defer span.End()
+	var caller string
+	if identity, ok := auth.FromContext(ctx); ok {
+		caller = identity.Subject
+	}
+	err := allow(request.GetContractorid(), caller, int64(len(request.GetFilecontents())))
+	if err == nil {
+		return nil
+	}
+	span.SetError(err)
+	logger.Debugf("Rate limited contractor %d for %q: %v", request.GetContractorid(), caller, err)
+	st := status.Newf(codes.ResourceExhausted, "Rate limit: %s", err)
+	if exceeded, ok := err.(*ratelimit.ExceededError); ok && exceeded.RetryAfter > 0 {
+		seconds := int64(math.Ceil(exceeded.RetryAfter.Seconds()))
+		grpc.SetTrailer(ctx, metadata.Pairs(retryAfterTrailer, strconv.FormatInt(seconds, 10)))
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(exceeded.RetryAfter)}); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// retryAfterTrailer carries the whole seconds after which a rate limited
+// Put may be retried
+const retryAfterTrailer = "retry-after"
+
+// retryDelay returns the RetryInfo delay in the details of err, if any
+func retryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/ratelimit"
+)
+
+type FakeRateLimiter struct {
+	Err error
+	// BytesErr is returned by AllowBytes
+	BytesErr error
+}
+
+func (f *FakeRateLimiter) Allow(contractorID int64, caller string, size int64) error {
+	return f.Err
+}
+
+func (f *FakeRateLimiter) AllowBytes(contractorID int64, caller string, size int64) error {
+	return f.BytesErr
+}
+
+func TestPutRateLimit(t *testing.T) {
+	server := &Server{
+		ServiceBusCaller: &FakeServer{Response: &pb.JSONRPCResponse{}},
+		RateLimiter:      &FakeRateLimiter{Err: &ratelimit.ExceededError{Limit: "request rate", RetryAfter: 1500 * time.Millisecond}},
+	}
+	_, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.png"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted but got %v", err)
+	}
+	if delay, ok := retryDelay(err); !ok || delay != 1500*time.Millisecond {
+		t.Errorf("Expected retry delay of 1.5s but got %s, %v", delay, ok)
+	}
+	w := httptest.NewRecorder()
+	writeError(w, err)
+	if w.Code != 429 || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected 429 with Retry-After 2 but got %d with %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	server.RateLimiter = &FakeRateLimiter{}
+	if _, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.png"}); err != nil {
+		t.Errorf("Expected put within limits to succeed but got %v", err)
+	}
+}
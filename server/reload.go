@@ -0,0 +1,259 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchedFiles name the settings whose files are watched for changes
+var watchedFiles = []string{"config_file", "ratelimit_file", "routes_file", "metadata_policy_file"}
+
+// reloadHandler checks the settings in fs, returning a function applying them
+// to a running component. It must not change anything itself, so that an
+// update rejected by another handler leaves the server as it was.
+type reloadHandler func(fs *flag.FlagSet) (apply func(), err error)
+
+// settingChange is a setting altered by a reload
+type settingChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// runtimeConfig re-reads the settings of a running server and applies the
+// ones that are safe to change without a restart
+type runtimeConfig struct {
+	template *flag.FlagSet
+	args     []string
+	getenv   func(string) string
+
+	mu         sync.Mutex
+	reloadable map[string]bool
+	handlers   []reloadHandler
+	current    map[string]string
+	sources    map[string]string
+	modTimes   map[string]time.Time
+}
+
+// newRuntimeConfig creates a runtimeConfig for fs, which was parsed from args
+// and loaded with loadConfig from sources
+func newRuntimeConfig(fs *flag.FlagSet, sources map[string]string, args []string, getenv func(string) string) *runtimeConfig {
+	c := &runtimeConfig{
+		template:   fs,
+		args:       args,
+		getenv:     getenv,
+		reloadable: make(map[string]bool),
+		current:    settingsOf(fs),
+		sources:    sources,
+	}
+	c.modTimes = c.fileModTimes()
+	return c
+}
+
+func settingsOf(fs *flag.FlagSet) map[string]string {
+	settings := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) { settings[f.Name] = f.Value.String() })
+	return settings
+}
+
+// handle registers handler to apply changes to the named settings, which
+// may then change without a restart. The handler is also run when a watched
+// file changes.
+func (c *runtimeConfig) handle(names []string, handler reloadHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		c.reloadable[name] = true
+	}
+	c.handlers = append(c.handlers, handler)
+}
+
+// configSetting is the effective value of a setting and where it came from
+type configSetting struct {
+	name       string
+	value      string
+	source     string
+	reloadable bool
+}
+
+// settings returns the effective settings sorted by name, with secrets redacted
+func (c *runtimeConfig) settings() []configSetting {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	settings := make([]configSetting, 0, len(c.current))
+	for name, value := range c.current {
+		if name == "print_config" {
+			continue
+		}
+		settings = append(settings, configSetting{
+			name:       name,
+			value:      redact(value),
+			source:     c.sources[name],
+			reloadable: c.reloadable[name],
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].name < settings[j].name })
+	return settings
+}
+
+func intSetting(fs *flag.FlagSet, name string) int {
+	return fs.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
+func int64Setting(fs *flag.FlagSet, name string) int64 {
+	return fs.Lookup(name).Value.(flag.Getter).Get().(int64)
+}
+
+func floatSetting(fs *flag.FlagSet, name string) float64 {
+	return fs.Lookup(name).Value.(flag.Getter).Get().(float64)
+}
+
+func durationSetting(fs *flag.FlagSet, name string) time.Duration {
+	return fs.Lookup(name).Value.(flag.Getter).Get().(time.Duration)
+}
+
+// cloneFlagSet defines the flags of fs in a new flag set, with their defaults
+func cloneFlagSet(fs *flag.FlagSet) *flag.FlagSet {
+	clone := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	clone.SetOutput(ioutil.Discard)
+	fs.VisitAll(func(f *flag.Flag) {
+		getter, _ := f.Value.(flag.Getter)
+		var value interface{}
+		if getter != nil {
+			value = getter.Get()
+		}
+		switch value.(type) {
+		case bool:
+			clone.Bool(f.Name, false, f.Usage)
+		case int:
+			clone.Int(f.Name, 0, f.Usage)
+		case int64:
+			clone.Int64(f.Name, 0, f.Usage)
+		case float64:
+			clone.Float64(f.Name, 0, f.Usage)
+		case time.Duration:
+			clone.Duration(f.Name, 0, f.Usage)
+		default:
+			clone.String(f.Name, "", f.Usage)
+		}
+		cloned := clone.Lookup(f.Name)
+		cloned.Value.Set(f.DefValue)
+		cloned.DefValue = f.DefValue
+	})
+	return clone
+}
+
+// Reload reads the settings again from the command line, config file and
+// environment. If every changed setting may change at runtime and every
+// handler accepts them, they are all applied and the changes returned.
+// Otherwise nothing is applied.
+func (c *runtimeConfig) Reload() ([]settingChange, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fs := cloneFlagSet(c.template)
+	if err := fs.Parse(c.args); err != nil {
+		return nil, err
+	}
+	sources, err := loadConfig(fs, c.getenv)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(fs); err != nil {
+		return nil, err
+	}
+
+	settings := settingsOf(fs)
+	var changes []settingChange
+	var problems []string
+	for name, value := range settings {
+		if old := c.current[name]; value != old {
+			changes = append(changes, settingChange{Name: name, Old: old, New: value})
+			if !c.reloadable[name] {
+				problems = append(problems, fmt.Sprintf("%s cannot change without a restart", name))
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	var applies []func()
+	for _, handler := range c.handlers {
+		apply, err := handler(fs)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		applies = append(applies, apply)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("Invalid configuration update:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+	c.current = settings
+	c.sources = sources
+	c.modTimes = c.fileModTimes()
+	if len(changes) > 0 {
+		diff := make([]string, len(changes))
+		for i, change := range changes {
+			diff[i] = fmt.Sprintf("%s: %q -> %q", change.Name, redact(change.Old), redact(change.New))
+		}
+		logger.Infof("Reloaded configuration:\n  %s", strings.Join(diff, "\n  "))
+	}
+	return changes, nil
+}
+
+// fileModTimes returns the modification time of each watched file in use
+func (c *runtimeConfig) fileModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, name := range watchedFiles {
+		filename := c.current[name]
+		if filename == "" {
+			continue
+		}
+		if info, err := os.Stat(filename); err == nil {
+			modTimes[filename] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+// changedFile returns a watched file modified since the last reload, if any
+func (c *runtimeConfig) changedFile() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for filename, modTime := range c.fileModTimes() {
+		if !modTime.Equal(c.modTimes[filename]) {
+			return filename
+		}
+	}
+	return ""
+}
+
+// Watch reloads the settings every interval in which the config file, rate
+// limit file or routes file has changed, until stop is closed
+func (c *runtimeConfig) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			filename := c.changedFile()
+			if filename == "" {
+				continue
+			}
+			if _, err := c.Reload(); err != nil {
+				logger.Errorf("Failed to reload configuration after %s changed, keeping current configuration: %v", filename, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRuntimeConfig loads the settings in a config file with contents and
+// makes shadow_sample_percent reloadable, recording the value applied
+func newTestRuntimeConfig(t *testing.T, contents string) (*runtimeConfig, string, *float64) {
+	filename := writeConfigFile(t, "config.yaml", contents)
+	fs := newConfigFlagSet()
+	args := []string{"-config_file", filename}
+	fs.Parse(args)
+	sources, err := loadConfig(fs, func(string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newRuntimeConfig(fs, sources, args, func(string) string { return "" })
+	percent := new(float64)
+	c.handle([]string{"shadow_sample_percent"}, func(fs *flag.FlagSet) (func(), error) {
+		value := floatSetting(fs, "shadow_sample_percent")
+		return func() { *percent = value }, nil
+	})
+	return c, filename, percent
+}
+
+func rewriteConfigFile(t *testing.T, filename, contents string) {
+	if err := ioutil.WriteFile(filename, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the change is seen even on filesystems with coarse mtimes
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filename, later, later)
+}
+
+func TestReloadAppliesChanges(t *testing.T) {
+	c, filename, percent := newTestRuntimeConfig(t, "shadow_sample_percent: 20\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+	if changed := c.changedFile(); changed != "" {
+		t.Errorf("Expected no changed file but got %s", changed)
+	}
+
+	rewriteConfigFile(t, filename, "shadow_sample_percent: 30\n")
+	if changed := c.changedFile(); changed != filename {
+		t.Errorf("Expected %s to have changed but got %q", filename, changed)
+	}
+	changes, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []settingChange{{Name: "shadow_sample_percent", Old: "20", New: "30"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v but got %v", expected, changes)
+	}
+	if *percent != 30 {
+		t.Errorf("Expected 30 to be applied but got %v", *percent)
+	}
+	if changed := c.changedFile(); changed != "" {
+		t.Errorf("Expected no changed file after reload but got %s", changed)
+	}
+}
+
+var reloadRejectCases = []struct {
+	contents string
+	expected string
+}{
+	{contents: "shadow_sample_percent: 30\nport: 11000\n", expected: "port cannot change without a restart"},
+	{contents: "shadow_sample_percent: 130\n", expected: "shadow_sample_percent must be between 0 and 100"},
+	{contents: "shadow_sample_percent: thirty\n", expected: "Invalid shadow_sample_percent"},
+	{contents: "shadow_sample_percnt: 30\n", expected: "Unknown setting shadow_sample_percnt"},
+	{contents: "shadow_sample_percent: 30\nlog_level: verbose\n", expected: "log_level must be"},
+}
+
+func TestReloadRejectsInvalidUpdates(t *testing.T) {
+	for _, rejected := range reloadRejectCases {
+		c, filename, percent := newTestRuntimeConfig(t, "shadow_sample_percent: 20\n")
+		defer os.RemoveAll(filepath.Dir(filename))
+		rewriteConfigFile(t, filename, rejected.contents)
+		_, err := c.Reload()
+		if err == nil || !strings.Contains(err.Error(), rejected.expected) {
+			t.Errorf("Expected error containing %q but got %v", rejected.expected, err)
+		}
+		if *percent != 0 {
+			t.Errorf("Expected nothing to be applied but got %v", *percent)
+		}
+
+		// A valid update is compared with the settings before the rejected one
+		rewriteConfigFile(t, filename, "shadow_sample_percent: 40\n")
+		changes, err := c.Reload()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []settingChange{{Name: "shadow_sample_percent", Old: "20", New: "40"}}
+		if !reflect.DeepEqual(changes, expected) {
+			t.Errorf("Expected %v but got %v", expected, changes)
+		}
+	}
+}
+
+func TestReloadIsAtomic(t *testing.T) {
+	c, filename, percent := newTestRuntimeConfig(t, "shadow_sample_percent: 20\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+	c.handle([]string{"servicebus_eject_duration"}, func(fs *flag.FlagSet) (func(), error) {
+		if durationSetting(fs, "servicebus_eject_duration") > time.Hour {
+			return nil, errors.New("servicebus_eject_duration is too long")
+		}
+		return func() {}, nil
+	})
+	rewriteConfigFile(t, filename, "shadow_sample_percent: 30\nservicebus_eject_duration: 2h\n")
+	if _, err := c.Reload(); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Errorf("Expected the eject duration to be rejected but got %v", err)
+	}
+	if *percent != 0 {
+		t.Errorf("Expected the percent not to be applied but got %v", *percent)
+	}
+}
+
+func TestCloneFlagSet(t *testing.T) {
+	fs := newConfigFlagSet()
+	fs.Parse([]string{"-port", "11000", "-tls"})
+	clone := cloneFlagSet(fs)
+	if err := clone.Parse([]string{"-tls", "-servicebus_eject_duration", "1m"}); err != nil {
+		t.Fatal(err)
+	}
+	if port := clone.Lookup("port").Value.String(); port != "10000" {
+		t.Errorf("Expected the default port but got %s", port)
+	}
+	if tls := clone.Lookup("tls").Value.String(); tls != "true" {
+		t.Errorf("Expected tls to be set but got %s", tls)
+	}
+	if duration := durationSetting(clone, "servicebus_eject_duration"); duration != time.Minute {
+		t.Errorf("Expected 1m but got %s", duration)
+	}
+}
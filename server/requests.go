@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
+)
+
+// trackedRequest is a call being served, or one that failed
+type trackedRequest struct {
+	method   string
+	caller   string
+	start    time.Time
+	duration time.Duration
+	// code and message of a failed call
+	code    string
+	message string
+}
+
+// requestTracker keeps the calls in flight and the most recent failures
+type requestTracker struct {
+	mu       sync.Mutex
+	next     uint64
+	inFlight map[uint64]*trackedRequest
+	// errors is a ring of the most recent failures, oldest at errorsNext
+	// once full
+	errors     []trackedRequest
+	errorsNext int
+	now        func() time.Time
+}
+
+func newRequestTracker(maxErrors int) *requestTracker {
+	return &requestTracker{
+		inFlight: make(map[uint64]*trackedRequest),
+		errors:   make([]trackedRequest, 0, maxErrors),
+		now:      time.Now,
+	}
+}
+
+// UnaryServerInterceptor tracks each call, and so should be chained after the
+// authentication interceptor to know its caller
+func (t *requestTracker) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		request := &trackedRequest{method: info.FullMethod, start: t.now()}
+		if identity, ok := auth.FromContext(ctx); ok {
+			request.caller = identity.Subject
+		}
+		id := t.begin(request)
+		resp, err := handler(ctx, req)
+		t.end(id, err)
+		return resp, err
+	}
+}
+
+func (t *requestTracker) begin(request *trackedRequest) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	t.inFlight[t.next] = request
+	return t.next
+}
+
+func (t *requestTracker) end(id uint64, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	request := t.inFlight[id]
+	delete(t.inFlight, id)
+	if err == nil || cap(t.errors) == 0 {
+		return
+	}
+	s := status.Convert(err)
+	failed := *request
+	failed.duration = t.now().Sub(request.start)
+	failed.code = s.Code().String()
+	failed.message = s.Message()
+	if len(t.errors) < cap(t.errors) {
+		t.errors = append(t.errors, failed)
+		return
+	}
+	t.errors[t.errorsNext] = failed
+	t.errorsNext = (t.errorsNext + 1) % len(t.errors)
+}
+
+// inFlightRequests returns the calls being served, longest running first
+func (t *requestTracker) inFlightRequests() []trackedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	requests := make([]trackedRequest, 0, len(t.inFlight))
+	for _, request := range t.inFlight {
+		r := *request
+		r.duration = now.Sub(r.start)
+		requests = append(requests, r)
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].start.Before(requests[j].start) })
+	return requests
+}
+
+// recentErrors returns up to limit of the most recent failures, newest
+// first, or all that are kept if limit is not positive
+func (t *requestTracker) recentErrors(limit int) []trackedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(t.errors)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	errors := make([]trackedRequest, limit)
+	for i := range errors {
+		// The newest failure is just before errorsNext
+		errors[i] = t.errors[(t.errorsNext-1-i+2*n)%n]
+	}
+	return errors
+}
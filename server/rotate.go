@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+	"github.com/divyag9/gothinnercontentservice/routing"
+	"github.com/divyag9/gothinnercontentservice/tracing"
+)
+
+// ServiceBus methods returning and replacing stored content
+const (
+	getMethod    = "CONTENTSERVICE.GET"
+	updateMethod = "CONTENTSERVICE.UPDATE"
+)
+
+// orient turns the image in request upright according to its Exif
+// orientation, recording the degrees it was rotated by. An image which
+// cannot be rotated is sent as it is.
+func (s *Server) orient(ctx context.Context, request *pb.PutParams) {
+	request.Imagerotated = 0
+	if s.Rotator == nil || !s.AutoOrient {
+		return
+	}
+	_, span := tracing.StartSpan(ctx, "orient")
+	defer span.End()
+	oriented, degrees, err := s.Rotator.Orient(request.GetFilecontents())
+	if err == imaging.ErrUnsupported {
+		return
+	}
+	if err != nil {
+		span.SetError(err)
+		logger.Warningf("Failed to orient %s for contractor %d order %d: %v", request.GetFilename(), request.GetContractorid(), request.GetOrdernumber(), err)
+		return
+	}
+	span.SetAttribute("degrees", degrees)
+	request.Filecontents = oriented
+	request.Imagerotated = int32(degrees)
+	if degrees == 90 || degrees == 270 {
+		request.Imagewidth, request.Imageheight = request.Imageheight, request.Imagewidth
+	}
+}
+
+// setImageRotated reports the rotation applied to request if ServiceBus
+// did not report one
+func setImageRotated(response *pb.PutResponse, request *pb.PutParams) {
+	if result := response.GetResult(); result != nil && result.GetImagerotated() == 0 {
+		result.Imagerotated = request.GetImagerotated()
+	}
+}
+
+// Rotate gets stored content from ServiceBus, rotates it and replaces it
+func (s *Server) Rotate(ctx context.Context, request *pb.RotateRequest) (*pb.PutResponse, error) {
+	start := time.Now()
+	update, response, err := s.rotate(ctx, request)
+	s.audit(ctx, audit.ActionRotate, update, "", start, response, err)
+	return response, err
+}
+
+// rotate returns the update it sent to ServiceBus, or the content it was
+// asked to rotate if it did not send one, and the response to the Rotate
+func (s *Server) rotate(ctx context.Context, request *pb.RotateRequest) (*pb.PutParams, *pb.PutResponse, error) {
+	get := &pb.PutParams{
+		Contractorid: request.GetContractorid(),
+		Ordernumber:  request.GetOrdernumber(),
+		Deptcode:     request.GetDeptcode(),
+		Id:           request.GetId(),
+	}
+	if err := validateRotateRequest(request); err != nil {
+		return get, nil, err
+	}
+	if s.Rotator == nil {
+		return get, nil, status.Error(codes.Unimplemented, "Rotation is not enabled")
+	}
+	if err := s.authorize(ctx, get); err != nil {
+		return get, nil, err
+	}
+	// The call is charged before reading the content, and its bytes once
+	// they are known
+	if err := s.rateLimit(ctx, get); err != nil {
+		return get, nil, err
+	}
+
+	// The image type is not known until the content is read
+	getRoute := s.contentRoute(get, getMethod, func(r routing.Route) string { return r.GetMethod })
+	getResponse, err := s.ServiceBusCaller.callServiceBus(withRead(routing.NewContext(ctx, getRoute)), createJSONRPCRequest(get, getRoute.Method))
+	if err != nil {
+		return get, nil, err
+	}
+	stored := getResponse.GetResult()
+	if stored == nil {
+		return get, createPutResponse(getResponse), nil
+	}
+	// The caller was authorized for the contractor they named, so content
+	// stored for another contractor or order is treated as not there
+	if int64(stored.GetContractorid()) != request.GetContractorid() || stored.GetOrdernumber() != request.GetOrdernumber() {
+		return get, nil, status.Errorf(codes.NotFound, "Content %d not found for contractor %d order %d", request.GetId(), request.GetContractorid(), request.GetOrdernumber())
+	}
+	// The caller was authorized for the department they named, which must
+	// also be allowed for the department the content is stored under
+	if stored.GetDeptcode() != get.GetDeptcode() {
+		get.Deptcode = stored.GetDeptcode()
+		if err := s.authorize(ctx, get); err != nil {
+			return get, nil, err
+		}
+	}
+
+	_, span := tracing.StartSpan(ctx, "rotate")
+	rotated, degrees, err := s.Rotator.Rotate(stored.GetFilecontents(), int(request.GetDegrees()))
+	span.SetError(err)
+	span.End()
+	if err == imaging.ErrUnsupported || err == imaging.ErrTooLarge {
+		return get, nil, status.Errorf(codes.FailedPrecondition, "Content %d cannot be rotated: %s", request.GetId(), err)
+	}
+	if err != nil {
+		return get, nil, status.Errorf(codes.Internal, "Error rotating content %d: %s", request.GetId(), err)
+	}
+	// The filename is left out so that ServiceBus keeps the one the content
+	// was put with, as it only returns where the content is stored
+	update := &pb.PutParams{
+		Contractorid: request.GetContractorid(),
+		Ordernumber:  request.GetOrdernumber(),
+		Imagetype:    stored.GetImagetype(),
+		Imagewidth:   stored.GetImagewidth(),
+		Imageheight:  stored.GetImageheight(),
+		Releasedate:  stored.GetReleasedate(),
+		Deptcode:     stored.GetDeptcode(),
+		Filecontents: rotated,
+		Imagerotated: (stored.GetImagerotated() + int32(degrees)) % 360,
+		Id:           request.GetId(),
+	}
+	if degrees == 90 || degrees == 270 {
+		update.Imagewidth, update.Imageheight = update.Imageheight, update.Imagewidth
+	}
+	if err := s.rateLimitBytes(ctx, update); err != nil {
+		return update, nil, err
+	}
+	metadata, err := s.sanitize(ctx, update)
+	if err != nil {
+		return update, nil, err
+	}
+	s.derive(ctx, update)
+
+	updateRoute := s.contentRoute(update, updateMethod, func(r routing.Route) string { return r.UpdateMethod })
+	updateResponse, err := s.ServiceBusCaller.callServiceBus(routing.NewContext(ctx, updateRoute), createJSONRPCRequest(update, updateRoute.Method))
+	if err != nil {
+		return update, nil, err
+	}
+	response := createPutResponse(updateResponse)
+	setThumbnailSize(response, update)
+	setImageRotated(response, update)
+	response.Capturemetadata = metadata
+	return update, response, nil
+}
+
+func validateRotateRequest(request *pb.RotateRequest) error {
+	if request.GetContractorid() <= 0 {
+		return status.Error(codes.InvalidArgument, "contractorid is required")
+	}
+	if request.GetOrdernumber() <= 0 {
+		return status.Error(codes.InvalidArgument, "ordernumber is required")
+	}
+	if request.GetId() <= 0 {
+		return status.Error(codes.InvalidArgument, "id is required")
+	}
+	switch request.GetDegrees() {
+	case 90, 180, 270:
+	default:
+		return status.Errorf(codes.InvalidArgument, "degrees must be 90, 180 or 270, got %d", request.GetDegrees())
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/divyag9/gothinnercontentservice/audit"
+	"github.com/divyag9/gothinnercontentservice/auth"
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+	"github.com/divyag9/gothinnercontentservice/ratelimit"
+	"github.com/divyag9/gothinnercontentservice/routing"
+)
+
+type FakeRotator struct {
+	Degrees int
+	Err     error
+}
+
+func (f *FakeRotator) Orient(contents []byte) ([]byte, int, error) {
+	return []byte("oriented"), f.Degrees, f.Err
+}
+
+func (f *FakeRotator) Rotate(contents []byte, degrees int) ([]byte, int, error) {
+	return []byte("rotated"), degrees, f.Err
+}
+
+// storedContent answers methods ending in GET with stored, and records the
+// update and the route of each call
+type storedContent struct {
+	stored *pb.JSONRPCResult
+	update *pb.PutParams
+	routes []routing.Route
+}
+
+func (s *storedContent) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	route, _ := routing.FromContext(ctx)
+	s.routes = append(s.routes, route)
+	switch {
+	case strings.HasSuffix(request.GetMethod(), "GET"):
+		if request.GetParams().GetId() != s.stored.GetId() {
+			return &pb.JSONRPCResponse{Error: &pb.JSONRPCError{Code: -32000, Message: "Content not found"}}, nil
+		}
+		return &pb.JSONRPCResponse{Result: s.stored}, nil
+	case strings.HasSuffix(request.GetMethod(), "UPDATE"):
+		s.update = request.GetParams()
+		return &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: request.GetParams().GetId()}}, nil
+	}
+	return nil, errors.New("unexpected method " + request.GetMethod())
+}
+
+// deptAuthorizer allows the departments it lists
+type deptAuthorizer map[string]bool
+
+func (d deptAuthorizer) Authorize(identity *auth.Identity, contractorID int64, deptcode string) error {
+	if !d[deptcode] {
+		return errors.New("department not allowed")
+	}
+	return nil
+}
+
+var orientCases = []struct {
+	autoOrient       bool
+	rotator          *FakeRotator
+	expectedContents string
+	expectedRotated  int32
+	expectedWidth    int32
+}{
+	{autoOrient: true, rotator: &FakeRotator{Degrees: 90}, expectedContents: "oriented", expectedRotated: 90, expectedWidth: 300},
+	{autoOrient: true, rotator: &FakeRotator{Degrees: 180}, expectedContents: "oriented", expectedRotated: 180, expectedWidth: 400},
+	{autoOrient: true, rotator: &FakeRotator{Err: imaging.ErrUnsupported}, expectedContents: "photo", expectedWidth: 400},
+	{autoOrient: true, rotator: &FakeRotator{Err: errors.New("corrupt")}, expectedContents: "photo", expectedWidth: 400},
+	{autoOrient: false, rotator: &FakeRotator{Degrees: 90}, expectedContents: "photo", expectedWidth: 400},
+}
+
+func TestPutOrients(t *testing.T) {
+	for _, c := range orientCases {
+		caller := &resultCaller{result: &pb.JSONRPCResult{Id: 1}}
+		server := &Server{ServiceBusCaller: caller, Rotator: c.rotator, AutoOrient: c.autoOrient}
+		request := &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "photo.jpg", Imagewidth: 400, Imageheight: 300, Filecontents: []byte("photo")}
+		response, err := server.Put(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent := caller.request.GetParams()
+		if string(sent.GetFilecontents()) != c.expectedContents || sent.GetImagerotated() != c.expectedRotated || sent.GetImagewidth() != c.expectedWidth {
+			t.Errorf("Expected %q rotated %d with width %d but got %q rotated %d with width %d", c.expectedContents, c.expectedRotated, c.expectedWidth, sent.GetFilecontents(), sent.GetImagerotated(), sent.GetImagewidth())
+		}
+		if rotated := response.GetResult().GetImagerotated(); rotated != c.expectedRotated {
+			t.Errorf("Expected the response to report %d degrees but got %d", c.expectedRotated, rotated)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+	serviceBus := &storedContent{stored: &pb.JSONRPCResult{
+		Id:            1810448062,
+		Contractorid:  72494,
+		Ordernumber:   600016555,
+		Imagetype:     1,
+		Imagefilename: "photo.png",
+		Imagewidth:    40,
+		Imageheight:   20,
+		Imagerotated:  90,
+		Deptcode:      "01",
+		Filecontents:  buf.Bytes(),
+	}}
+	auditor := &FakeAuditor{}
+	server := &Server{ServiceBusCaller: serviceBus, Rotator: &imaging.Rotator{}, Auditor: auditor}
+	response, err := server.Rotate(context.Background(), &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1810448062, Deptcode: "01", Degrees: 270})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := serviceBus.update
+	if update.GetId() != 1810448062 || update.GetFilename() != "" || update.GetDeptcode() != "01" || update.GetImagerotated() != 0 {
+		t.Errorf("Expected content 1810448062 to be replaced with a total rotation of 0 but got %+v", update)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(update.GetFilecontents()))
+	if err != nil || config.Width != 20 || config.Height != 40 || update.GetImagewidth() != 20 || update.GetImageheight() != 40 {
+		t.Errorf("Expected a 20x40 image but got %+v with %dx%d, %v", config, update.GetImagewidth(), update.GetImageheight(), err)
+	}
+	if response.GetResult().GetId() != 1810448062 {
+		t.Errorf("Expected the update response but got %v", response)
+	}
+	if len(auditor.Records) != 1 || auditor.Records[0].Action != audit.ActionRotate || auditor.Records[0].ContentID != 1810448062 || auditor.Records[0].Outcome != audit.OutcomeOK {
+		t.Errorf("Expected the rotate to be audited but got %+v", auditor.Records)
+	}
+}
+
+var rotateErrorCases = []struct {
+	request      *pb.RotateRequest
+	authorizer   Authorizer
+	rateLimiter  RateLimiter
+	rotator      Rotator
+	expectedCode codes.Code
+	serviceBus   bool
+	// getsContent is set when the content is read before the error
+	getsContent bool
+}{
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Degrees: 45}, rotator: &FakeRotator{}, expectedCode: codes.InvalidArgument},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Degrees: 90}, rotator: &FakeRotator{}, expectedCode: codes.InvalidArgument},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Degrees: 90}, expectedCode: codes.Unimplemented},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "02", Degrees: 90}, rotator: &FakeRotator{}, authorizer: deptAuthorizer{"01": true}, expectedCode: codes.PermissionDenied},
+	// Allowed for the department named, but not the one the content is stored under
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "02", Degrees: 90}, rotator: &FakeRotator{}, authorizer: deptAuthorizer{"02": true}, expectedCode: codes.PermissionDenied},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{Err: imaging.ErrUnsupported}, expectedCode: codes.FailedPrecondition},
+	// Content stored for another contractor or order
+	{request: &pb.RotateRequest{Contractorid: 10000, Ordernumber: 600016555, Id: 1, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{}, expectedCode: codes.NotFound},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016556, Id: 1, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{}, expectedCode: codes.NotFound},
+	// Over the request rate, or the byte rate once rotated
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{}, rateLimiter: &FakeRateLimiter{Err: &ratelimit.ExceededError{Limit: "request rate"}}, expectedCode: codes.ResourceExhausted},
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{}, rateLimiter: &FakeRateLimiter{BytesErr: &ratelimit.ExceededError{Limit: "byte rate"}}, expectedCode: codes.ResourceExhausted, getsContent: true},
+	// ServiceBus errors are returned in the response
+	{request: &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 2, Deptcode: "01", Degrees: 90}, rotator: &FakeRotator{}, expectedCode: codes.OK, serviceBus: true},
+}
+
+func TestRotateErrors(t *testing.T) {
+	for i, c := range rotateErrorCases {
+		serviceBus := &storedContent{stored: &pb.JSONRPCResult{Id: 1, Contractorid: 72494, Ordernumber: 600016555, Deptcode: "01"}}
+		server := &Server{ServiceBusCaller: serviceBus, Authorizer: c.authorizer, RateLimiter: c.rateLimiter, Rotator: c.rotator}
+		response, err := server.Rotate(context.Background(), c.request)
+		if code := status.Code(err); code != c.expectedCode {
+			t.Errorf("%d: Expected %v but got %v", i, c.expectedCode, err)
+		}
+		if c.serviceBus && response.GetError().GetMessage() != "Content not found" {
+			t.Errorf("%d: Expected the servicebus error but got %v", i, response)
+		}
+		if serviceBus.update != nil {
+			t.Errorf("%d: Expected no update but got %+v", i, serviceBus.update)
+		}
+		if c.rateLimiter != nil && (len(serviceBus.routes) > 0) != c.getsContent {
+			t.Errorf("%d: Expected the content read %v but got %d calls", i, c.getsContent, len(serviceBus.routes))
+		}
+	}
+}
+
+func TestRotateRoutes(t *testing.T) {
+	serviceBus := &storedContent{stored: &pb.JSONRPCResult{Id: 1, Contractorid: 72494, Ordernumber: 600016555, Imagetype: 2, Deptcode: "02"}}
+	server := &Server{ServiceBusCaller: serviceBus, Rotator: &FakeRotator{}}
+	server.Routes = &routing.Table{Routes: []routing.Route{
+		{Imagetypes: []int32{2}, Method: "CONTENTSERVICE.DOCUMENTPUT", Endpoint: "http://servicebus/documents"},
+		{Deptcodes: []string{"02"}, Method: "CONTENTSERVICE.DEPT02PUT", GetMethod: "CONTENTSERVICE.DEPT02GET", Params: routing.ParamsArray},
+	}}
+	if _, err := server.Rotate(context.Background(), &pb.RotateRequest{Contractorid: 72494, Ordernumber: 600016555, Id: 1, Deptcode: "02", Degrees: 90}); err != nil {
+		t.Fatal(err)
+	}
+	// The get cannot match on the image type, which the update can
+	expected := []routing.Route{
+		{Deptcodes: []string{"02"}, Method: "CONTENTSERVICE.DEPT02GET", GetMethod: "CONTENTSERVICE.DEPT02GET", Params: routing.ParamsArray},
+		{Imagetypes: []int32{2}, Method: updateMethod, Endpoint: "http://servicebus/documents"},
+	}
+	if !reflect.DeepEqual(serviceBus.routes, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, serviceBus.routes)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+	"github.com/divyag9/gothinnercontentservice/tracing"
+)
+
+// captureTimeLayout formats capture times recorded without an offset
+const captureTimeLayout = "2006-01-02T15:04:05"
+
+// sanitize removes the metadata the department of request may not store
+// from its contents, returning the capture time and position read from them
+// beforehand if the department's policy extracts them. Content whose
+// metadata cannot be removed is rejected rather than stored with it.
+func (s *Server) sanitize(ctx context.Context, request *pb.PutParams) (*pb.CaptureMetadata, error) {
+	if s.Sanitizer == nil {
+		return nil, nil
+	}
+	_, span := tracing.StartSpan(ctx, "sanitize")
+	defer span.End()
+	sanitized, metadata, err := s.Sanitizer.Sanitize(request.GetFilecontents(), request.GetDeptcode())
+	if err != nil {
+		span.SetError(err)
+		return nil, status.Errorf(codes.InvalidArgument, "Unable to remove metadata from %s: %s", request.GetFilename(), err)
+	}
+	span.SetAttribute("removed_bytes", len(request.GetFilecontents())-len(sanitized))
+	request.Filecontents = sanitized
+	return captureMetadata(metadata), nil
+}
+
+// captureMetadata converts metadata for a response
+func captureMetadata(metadata *imaging.Metadata) *pb.CaptureMetadata {
+	if metadata == nil {
+		return nil
+	}
+	captured := &pb.CaptureMetadata{}
+	switch {
+	case metadata.CaptureTime.IsZero():
+	case metadata.CaptureTimeOffset:
+		captured.Capturetime = metadata.CaptureTime.Format(time.RFC3339)
+	default:
+		captured.Capturetime = metadata.CaptureTime.Format(captureTimeLayout)
+	}
+	if gps := metadata.GPS; gps != nil {
+		captured.Gps = &pb.GPSPosition{Latitude: gps.Latitude, Longitude: gps.Longitude, Altitude: gps.Altitude}
+	}
+	return captured
+}
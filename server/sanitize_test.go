@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+)
+
+type FakeSanitizer struct {
+	Metadata *imaging.Metadata
+	Err      error
+	Deptcode string
+}
+
+func (f *FakeSanitizer) Sanitize(contents []byte, deptcode string) ([]byte, *imaging.Metadata, error) {
+	f.Deptcode = deptcode
+	return []byte("sanitized"), f.Metadata, f.Err
+}
+
+var captureMetadataCases = []struct {
+	metadata *imaging.Metadata
+	expected *pb.CaptureMetadata
+}{
+	{nil, nil},
+	{
+		&imaging.Metadata{CaptureTime: time.Date(2017, 3, 8, 13, 6, 40, 0, time.FixedZone("", -6*3600)), CaptureTimeOffset: true},
+		&pb.CaptureMetadata{Capturetime: "2017-03-08T13:06:40-06:00"},
+	},
+	{
+		&imaging.Metadata{CaptureTime: time.Date(2017, 3, 8, 13, 6, 40, 0, time.UTC), GPS: &imaging.GPSPosition{Latitude: 40.446111, Longitude: -79.982222, Altitude: 273.5}},
+		&pb.CaptureMetadata{Capturetime: "2017-03-08T13:06:40", Gps: &pb.GPSPosition{Latitude: 40.446111, Longitude: -79.982222, Altitude: 273.5}},
+	},
+	{
+		&imaging.Metadata{GPS: &imaging.GPSPosition{Latitude: -33.8568, Longitude: 151.2153}},
+		&pb.CaptureMetadata{Gps: &pb.GPSPosition{Latitude: -33.8568, Longitude: 151.2153}},
+	},
+}
+
+func TestCaptureMetadata(t *testing.T) {
+	for _, c := range captureMetadataCases {
+		actual := captureMetadata(c.metadata)
+		if actual.String() != c.expected.String() {
+			t.Errorf("Expected %v but got %v", c.expected, actual)
+		}
+	}
+}
+
+func TestPutSanitizes(t *testing.T) {
+	caller := &resultCaller{result: &pb.JSONRPCResult{Id: 1}}
+	sanitizer := &FakeSanitizer{Metadata: &imaging.Metadata{GPS: &imaging.GPSPosition{Latitude: 40.446111, Longitude: -79.982222}}}
+	server := &Server{ServiceBusCaller: caller, Sanitizer: sanitizer}
+	response, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "photo.jpg", Deptcode: "07", Filecontents: []byte("photo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sanitizer.Deptcode != "07" || string(caller.request.GetParams().GetFilecontents()) != "sanitized" {
+		t.Errorf("Expected the sanitized contents of department 07 to be sent but got %q for %q", caller.request.GetParams().GetFilecontents(), sanitizer.Deptcode)
+	}
+	if response.GetCapturemetadata().GetGps().GetLatitude() != 40.446111 {
+		t.Errorf("Expected the position to be returned but got %v", response.GetCapturemetadata())
+	}
+}
+
+func TestPutSanitizeError(t *testing.T) {
+	caller := &resultCaller{result: &pb.JSONRPCResult{Id: 1}}
+	server := &Server{ServiceBusCaller: caller, Sanitizer: &FakeSanitizer{Err: errors.New("Invalid Exif data")}}
+	_, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "photo.jpg", Filecontents: []byte("photo")})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected %v but got %v", codes.InvalidArgument, err)
+	}
+	if caller.request != nil {
+		t.Errorf("Expected contents with metadata not to be sent but got %v", caller.request)
+	}
+}
@@ -2,88 +2,382 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"expvar"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 
+	"github.com/divyag9/gothinnercontentservice/audit"
+	"github.com/divyag9/gothinnercontentservice/auth"
 	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	pbadmin "github.com/divyag9/gothinnercontentservice/contentservice/admin"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+	"github.com/divyag9/gothinnercontentservice/policy"
+	"github.com/divyag9/gothinnercontentservice/ratelimit"
+	"github.com/divyag9/gothinnercontentservice/routing"
+	"github.com/divyag9/gothinnercontentservice/tracing"
 )
 
 // ServiceBusCaller interface for calling ServiceBus
 type ServiceBusCaller interface {
-	callServiceBus(request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error)
+	callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error)
+}
+
+// Authorizer decides whether a caller may upload for a contractor and department
+type Authorizer interface {
+	Authorize(identity *auth.Identity, contractorID int64, deptcode string) error
+}
+
+// RateLimiter decides whether a caller may upload size bytes for a contractor now
+type RateLimiter interface {
+	Allow(contractorID int64, caller string, size int64) error
+	// AllowBytes decides on the size bytes of a request already allowed
+	AllowBytes(contractorID int64, caller string, size int64) error
+}
+
+// Auditor records each content mutation
+type Auditor interface {
+	Append(record *audit.Record) error
+}
+
+// Deriver generates resized copies of an uploaded image
+type Deriver interface {
+	Derive(contents []byte) ([]imaging.Derivative, error)
+}
+
+// Rotator turns uploaded images upright and rotates stored ones
+type Rotator interface {
+	Orient(contents []byte) ([]byte, int, error)
+	Rotate(contents []byte, degrees int) ([]byte, int, error)
+}
+
+// Sanitizer removes the metadata a department may not store from uploaded
+// content, returning any it extracted first
+type Sanitizer interface {
+	Sanitize(contents []byte, deptcode string) ([]byte, *imaging.Metadata, error)
+}
+
+// Router chooses the route of a Put
+type Router interface {
+	Match(attributes routing.Attributes) (routing.Route, bool)
 }
 
 // Server servicebus
 type Server struct {
+	pb.UnimplementedContentServiceServer
 	ServiceBusCaller
+	// Authorizer, if set, is consulted before each Put is sent to ServiceBus
+	Authorizer Authorizer
+	// RateLimiter, if set, is consulted after a Put is authorized
+	RateLimiter RateLimiter
+	// Routes, if set, chooses the ServiceBus method, endpoint and parameter
+	// shape of each Put
+	Routes Router
+	// Auditor, if set, records the caller, content and outcome of each Put
+	Auditor Auditor
+	// Deriver, if set, generates the derivatives sent to ServiceBus with
+	// each Put
+	Deriver Deriver
+	// Rotator, if set, rotates stored content for Rotate, and turns each
+	// Put upright according to its Exif orientation if AutoOrient is set
+	Rotator    Rotator
+	AutoOrient bool
+	// Sanitizer, if set, removes metadata from each Put after it is
+	// turned upright
+	Sanitizer Sanitizer
+
+	auditFailures expvar.Int
 }
 
 // Caller interface for servicebus
 type Caller struct {
-	serviceBusEndPoint string
+	endpoints *endpointPool
+	// hedging, if set, hedges reads and requests carrying an idempotency key
+	hedging *hedging
+	// timeout bounds each call in nanoseconds, if positive
+	timeout int64
+}
+
+// setTimeout changes how long each call may take, 0 for no limit
+func (c *Caller) setTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.timeout, int64(timeout))
 }
 
+// putMethod is the ServiceBus method storing content
+const putMethod = "CONTENTSERVICE.PUT"
+
 // Put performs the servicebus put
 func (s *Server) Put(ctx context.Context, request *pb.PutRequest) (*pb.PutResponse, error) {
-	jsonRPCRequest := createJSONRPCRequest(request)
-	jsonRPCResponse, err := s.ServiceBusCaller.callServiceBus(jsonRPCRequest)
+	return s.put(ctx, putParams(request), "", putMethod)
+}
+
+// put sends request to ServiceBus and audits the outcome
+func (s *Server) put(ctx context.Context, request *pb.PutParams, destination, method string) (*pb.PutResponse, error) {
+	start := time.Now()
+	response, err := s.sendPut(ctx, request, destination, method)
+	s.audit(ctx, audit.ActionPut, request, destination, start, response, err)
+	return response, err
+}
+
+// sendPut authorizes request and sends it to ServiceBus through its route,
+// falling back to method when no route matches
+func (s *Server) sendPut(ctx context.Context, request *pb.PutParams, destination, method string) (*pb.PutResponse, error) {
+	if span := tracing.FromContext(ctx); span != nil {
+		span.SetAttribute("contractorid", request.GetContractorid())
+		span.SetAttribute("ordernumber", request.GetOrdernumber())
+		span.SetAttribute("filesize", len(request.GetFilecontents()))
+		if identity, ok := auth.FromContext(ctx); ok {
+			span.SetAttribute("caller", identity.Subject)
+		}
+	}
+
+	if err := s.authorize(ctx, request); err != nil {
+		return nil, err
+	}
+	if err := s.rateLimit(ctx, request); err != nil {
+		return nil, err
+	}
+
+	s.orient(ctx, request)
+	metadata, err := s.sanitize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	s.derive(ctx, request)
+	route := s.route(request, destination, method)
+	logger.Debugf("Sending %s for contractor %d order %d to servicebus method %s", request.GetFilename(), request.GetContractorid(), request.GetOrdernumber(), route.Method)
+	jsonRPCRequest := createJSONRPCRequest(request, route.Method)
+	jsonRPCResponse, err := s.ServiceBusCaller.callServiceBus(routing.NewContext(ctx, route), jsonRPCRequest)
 	if err != nil {
 		return nil, err
 	}
 	putResponse := createPutResponse(jsonRPCResponse)
+	setThumbnailSize(putResponse, request)
+	setImageRotated(putResponse, request)
+	putResponse.Capturemetadata = metadata
 
 	return putResponse, nil
 }
 
-func createJSONRPCRequest(request *pb.PutRequest) *pb.JSONRPCRequest {
+func (s *Server) authorize(ctx context.Context, request *pb.PutParams) error {
+	if s.Authorizer == nil {
+		return nil
+	}
+	_, span := tracing.StartSpan(ctx, "authorize")
+	defer span.End()
+	identity, _ := auth.FromContext(ctx)
+	if err := s.Authorizer.Authorize(identity, request.GetContractorid(), request.GetDeptcode()); err != nil {
+		span.SetError(err)
+		return status.Errorf(codes.PermissionDenied, "Permission denied: %s", err)
+	}
+
+	return nil
+}
+
+// route returns the first route in s.Routes matching request, or a route
+// sending it to method at the default endpoint
+func (s *Server) route(request *pb.PutParams, destination, method string) routing.Route {
+	if s.Routes != nil {
+		attributes := routing.Attributes{
+			Imagetype:    request.GetImagetype(),
+			Deptcode:     request.GetDeptcode(),
+			Contractorid: request.GetContractorid(),
+			Destination:  destination,
+		}
+		if route, ok := s.Routes.Match(attributes); ok {
+			return route
+		}
+	}
+	return routing.Route{Method: method}
+}
+
+// contentRoute returns the route of a call reading or replacing content
+// described by request, which goes wherever a Put of the content would. The
+// call is made to the method the matching route names for it with
+// routeMethod, if any, or else to method.
+func (s *Server) contentRoute(request *pb.PutParams, method string, routeMethod func(routing.Route) string) routing.Route {
+	route := s.route(request, "", method)
+	route.Method = method
+	if override := routeMethod(route); override != "" {
+		route.Method = override
+	}
+	return route
+}
+
+// putParams returns the servicebus params of a client's request, to which
+// the server adds
+func putParams(request *pb.PutRequest) *pb.PutParams {
+	return &pb.PutParams{
+		Contractorid: request.GetContractorid(),
+		Ordernumber:  request.GetOrdernumber(),
+		Imagetype:    request.GetImagetype(),
+		Filename:     request.GetFilename(),
+		Imagewidth:   request.GetImagewidth(),
+		Imageheight:  request.GetImageheight(),
+		Releasedate:  request.GetReleasedate(),
+		Deptcode:     request.GetDeptcode(),
+		Filecontents: request.GetFilecontents(),
+	}
+}
+
+func createJSONRPCRequest(request *pb.PutParams, method string) *pb.JSONRPCRequest {
 	jsonRPCRequest := &pb.JSONRPCRequest{}
 	jsonRPCRequest.Jsonrpc = "2.0"
-	jsonRPCRequest.Method = "CONTENTSERVICE.PUT"
+	jsonRPCRequest.Method = method
 	jsonRPCRequest.Params = request
 
 	return jsonRPCRequest
 }
 
-func (c *Caller) callServiceBus(request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
-	start := time.Now()
-	requestBytes, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
+// positionalJSONRPCRequest is a JSON-RPC request passing the PutParams as
+// its only positional param
+type positionalJSONRPCRequest struct {
+	Jsonrpc string          `json:"jsonrpc,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  []*pb.PutParams `json:"params"`
+	Id      int32           `json:"id,omitempty"`
+}
+
+// marshalJSONRPCRequest encodes request with its params in the given shape
+func marshalJSONRPCRequest(request *pb.JSONRPCRequest, params string) ([]byte, error) {
+	if params != routing.ParamsArray {
+		return json.Marshal(request)
 	}
-	elapsed := time.Since(start)
-	fmt.Println("Elapsed Marshal: ", elapsed)
+	return json.Marshal(&positionalJSONRPCRequest{
+		Jsonrpc: request.GetJsonrpc(),
+		Method:  request.GetMethod(),
+		Params:  []*pb.PutParams{request.GetParams()},
+		Id:      request.GetId(),
+	})
+}
 
-	req, err := http.NewRequest("POST", c.serviceBusEndPoint, bytes.NewBuffer(requestBytes))
+func (c *Caller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	if timeout := time.Duration(atomic.LoadInt64(&c.timeout)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	route, _ := routing.FromContext(ctx)
+	_, span := tracing.StartSpan(ctx, "servicebus.marshal")
+	requestBytes, err := marshalJSONRPCRequest(request, route.Params)
+	span.SetAttribute("bytes", len(requestBytes))
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
-	client := http.Client{}
-	resp, err := client.Do(req)
+
+	var body []byte
+	if route.Endpoint != "" {
+		body, _, err = c.post(ctx, route.Endpoint, request.GetMethod(), requestBytes)
+	} else if c.hedging != nil && c.hedging.enabled() && (idempotencyKey(ctx) != "" || isRead(ctx)) {
+		body, err = c.sendHedged(ctx, request.GetMethod(), requestBytes)
+	} else {
+		body, err = c.send(ctx, request.GetMethod(), requestBytes)
+	}
 	if err != nil {
 		return nil, err
 	}
-	body, _ := ioutil.ReadAll(resp.Body)
-	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
 
+	_, span = tracing.StartSpan(ctx, "servicebus.unmarshal")
+	defer span.End()
 	jsonRPCResponse := &pb.JSONRPCResponse{}
 	err = json.Unmarshal(body, jsonRPCResponse)
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
 
 	return jsonRPCResponse, nil
 }
 
+// send posts requestBytes to an endpoint chosen from the pool, moving on to
+// another endpoint while they cannot be reached
+func (c *Caller) send(ctx context.Context, method string, requestBytes []byte) ([]byte, error) {
+	tried := make(map[*endpoint]bool)
+	for {
+		e := c.endpoints.pick(tried)
+		tried[e] = true
+		body, _, err := c.attempt(ctx, e, method, requestBytes)
+		if err != nil && isDialError(err) && len(tried) < len(c.endpoints.endpoints) {
+			logger.Warningf("Failed to reach servicebus endpoint %s, trying another: %v", e.url, err)
+			continue
+		}
+		return body, err
+	}
+}
+
+// attempt posts requestBytes to e, returning the response body and status,
+// and records the endpoint's health and, on success, the latency used to
+// decide when to hedge
+func (c *Caller) attempt(ctx context.Context, e *endpoint, method string, requestBytes []byte) ([]byte, int, error) {
+	atomic.AddInt64(&e.outstanding, 1)
+	start := time.Now()
+	body, statusCode, err := c.post(ctx, e.url, method, requestBytes)
+	atomic.AddInt64(&e.outstanding, -1)
+	if err == nil {
+		logger.Debugf("Servicebus endpoint %s returned status %d for %s in %s", e.url, statusCode, method, time.Since(start))
+	}
+	// A request abandoned by its caller, or cancelled after losing a hedge,
+	// says nothing about the endpoint
+	if ctx.Err() == nil {
+		health := err
+		if health == nil && statusCode >= 500 {
+			health = fmt.Errorf("Servicebus returned status %d", statusCode)
+		}
+		c.endpoints.record(e, health)
+		if health == nil && c.hedging != nil {
+			c.hedging.latencies.add(time.Since(start))
+		}
+	}
+	return body, statusCode, err
+}
+
+// post sends requestBytes to endpoint, returning the response body and status
+func (c *Caller) post(ctx context.Context, endpoint, method string, requestBytes []byte) ([]byte, int, error) {
+	httpCtx, span := tracing.StartSpan(ctx, "servicebus.http")
+	defer span.End()
+	span.SetAttribute("endpoint", endpoint)
+	span.SetAttribute("method", method)
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(requestBytes))
+	if err != nil {
+		span.SetError(err)
+		return nil, 0, err
+	}
+	tracing.InjectHTTP(httpCtx, req.Header)
+	if key := idempotencyKey(ctx); key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	client := http.Client{}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		span.SetError(err)
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	span.SetAttribute("status", resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+
+	return body, resp.StatusCode, nil
+}
+
 func createPutResponse(response *pb.JSONRPCResponse) *pb.PutResponse {
 	putResponse := &pb.PutResponse{}
 	putResponse.Result = response.GetResult()
@@ -94,33 +388,363 @@ func createPutResponse(response *pb.JSONRPCResponse) *pb.PutResponse {
 
 // NewServer creates new servicebus  server
 func NewServer(serviceBusEndPoint string) *Server {
-	return &Server{ServiceBusCaller: &Caller{serviceBusEndPoint: serviceBusEndPoint}}
+	endpoints, _ := newEndpointPool([]string{serviceBusEndPoint}, strategyRoundRobin, 0, 0)
+	return &Server{ServiceBusCaller: &Caller{endpoints: endpoints}}
 }
 
 func main() {
 	tls := flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
 	certFile := flag.String("cert_file", "testdata/server1.pem", "The TLS cert file")
 	keyFile := flag.String("key_file", "testdata/server1.key", "The TLS key file")
+	clientCAFile := flag.String("client_ca_file", "", "The CA bundle used to verify client certificates")
+	clientAuth := flag.String("client_auth", "none", "Client certificate mode when TLS is on: none, optional or required")
+	tlsReloadInterval := flag.Duration("tls_reload_interval", time.Minute, "How often the TLS certificate, key and client CA files are checked for changes")
+	debugPort := flag.Int("debug_port", 0, "The port serving /debug/vars metrics, 0 to disable")
+	adminPort := flag.Int("admin_port", 0, "The port serving the ContentServiceAdmin service to authenticated callers with admin_role, 0 to disable")
+	adminRole := flag.String("admin_role", "admin", "The role a caller must be granted to use the ContentServiceAdmin service")
+	adminRecentErrors := flag.Int("admin_recent_errors", 100, "How many of the most recent failed calls are kept for the ContentServiceAdmin service")
 	port := flag.Int("port", 10000, "The server port")
-	serviceBusEndPoint := flag.String("servicebus_endpoint", "http://servicebus.qa01.local/Execute.svc/Execute", "The servicebus execute endpoint")
+	enableReflection := flag.Bool("reflection", false, "Register the gRPC server reflection service")
+	httpPort := flag.Int("http_port", 0, "The port serving the HTTP/JSON gateway, 0 to disable")
+	httpMaxRequestBytes := flag.Int64("http_max_request_bytes", 4<<20, "The largest request body accepted by the HTTP/JSON gateway")
+	httpMaxUploadBytes := flag.Int64("http_max_upload_bytes", 64<<20, "The largest multipart form accepted by the HTTP upload endpoint")
+	httpMaxUploadFileBytes := flag.Int64("http_max_upload_file_bytes", 4<<20, "The largest file accepted within a multipart upload form")
+	serviceBusEndPoint := flag.String("servicebus_endpoint", "http://servicebus.qa01.local/Execute.svc/Execute", "Comma separated servicebus execute endpoints")
+	serviceBusStrategy := flag.String("servicebus_strategy", strategyRoundRobin, "How a servicebus endpoint is chosen: round_robin, least_outstanding or failover")
+	serviceBusEjectFailures := flag.Int("servicebus_eject_failures", 5, "Consecutive failures after which a servicebus endpoint is ejected, 0 to never eject")
+	serviceBusEjectDuration := flag.Duration("servicebus_eject_duration", 30*time.Second, "How long an ejected servicebus endpoint is avoided")
+	serviceBusHedgePercentile := flag.Float64("servicebus_hedge_percentile", 0, "Latency percentile after which a read, or a put with an idempotency key, is also sent to another servicebus endpoint, 0 to disable hedging")
+	serviceBusHedgeMinDelay := flag.Duration("servicebus_hedge_min_delay", 100*time.Millisecond, "The shortest wait before a call is hedged")
+	shadowEndPoint := flag.String("shadow_servicebus_endpoint", "", "Comma separated endpoints of a candidate servicebus environment to mirror puts to, empty to disable mirroring")
+	shadowPercent := flag.Float64("shadow_sample_percent", 10, "Percentage of puts mirrored to the shadow servicebus")
+	shadowTimeout := flag.Duration("shadow_timeout", 30*time.Second, "How long a mirrored put may take")
+	shadowReportFile := flag.String("shadow_report_file", "-", "File to append differences between primary and shadow responses to as JSON lines, - for stdout")
+	serviceBusConcurrencyMax := flag.Int("servicebus_concurrency_max", 0, "Most concurrent servicebus calls the adaptive limit may grow to, 0 to disable limiting")
+	serviceBusConcurrencyInitial := flag.Int("servicebus_concurrency_initial", 20, "Starting concurrent servicebus call limit")
+	serviceBusLatencyTarget := flag.Duration("servicebus_latency_target", time.Second, "Servicebus latency above which the concurrency limit is reduced")
+	serviceBusQueueSize := flag.Int("servicebus_queue_size", 100, "Most calls waiting for the concurrency limit before calls are rejected")
+	serviceBusQueueTimeout := flag.Duration("servicebus_queue_timeout", 5*time.Second, "How long a call waits for the concurrency limit")
+	serviceBusProbeInterval := flag.Duration("servicebus_probe_interval", 0, "How often every servicebus endpoint is probed, 0 to disable probing")
+	serviceBusTimeout := flag.Duration("servicebus_timeout", 0, "How long a servicebus call may take, 0 for no limit")
+	serviceBusTimezone := flag.String("servicebus_timezone", "UTC", "The time zone of dates returned by servicebus, used by the v2 API")
+	traceFile := flag.String("trace_file", "", "File to write trace spans to as JSON lines, - for stdout, empty to disable tracing")
+	tokenFile := flag.String("token_file", "", "JSON file of static bearer tokens accepted by the server")
+	jwtKeysFile := flag.String("jwt_keys_file", "", "JSON Web Key Set file of HMAC keys used to verify bearer JWTs")
+	jwtIssuer := flag.String("jwt_issuer", "", "Required iss claim of bearer JWTs")
+	jwtAudience := flag.String("jwt_audience", "", "Required aud claim of bearer JWTs")
+	policyFile := flag.String("policy_file", "", "YAML or JSON file of rules authorizing callers by contractor and department")
+	policyReloadInterval := flag.Duration("policy_reload_interval", 30*time.Second, "How often the policy file is checked for changes")
+	flag.String("ratelimit_file", "", "YAML or JSON file of per contractor request and byte rates and daily quotas")
+	flag.String("metadata_policy_file", "", "YAML or JSON file of rules choosing the EXIF, XMP and IPTC metadata removed from uploaded photos of each department, and whether their capture time and GPS position are returned")
+	flag.String("routes_file", "", "YAML or JSON file of routes choosing the servicebus method, endpoint and params shape of each put")
+	auditFile := flag.String("audit_file", "", "File to append an audit record of every put to as hash chained JSON lines, empty to disable auditing")
+	auditMaxBytes := flag.Int64("audit_max_bytes", 100<<20, "Size at which the audit file is rotated, 0 to never rotate")
+	flag.String("derivative_sizes", "", "Comma separated name:WIDTHxHEIGHT boxes, such as thumbnail:150x150,web:1024x1024, that resized copies of uploaded images are generated at and sent to servicebus with them")
+	flag.Int("derivative_jpeg_quality", imaging.DefaultQuality, "JPEG quality from 1 to 100 of derivatives of JPEG and GIF images")
+	flag.Int("derivative_max_pixels", imaging.DefaultMaxPixels, "Largest image in pixels that derivatives are generated from")
+	derivativeCacheBytes := flag.Int64("derivative_cache_bytes", 64<<20, "Size of the cache of recently generated derivatives")
+	autoOrient := flag.Bool("auto_orient", false, "Turn uploaded JPEGs upright according to their EXIF orientation before sending them to servicebus, recording the rotation in imagerotated")
+	rotateJPEGQuality := flag.Int("rotate_jpeg_quality", 92, "JPEG quality from 1 to 100 of images re-encoded when oriented or rotated")
+	recoverPanics := flag.Bool("recover_panics", true, "Convert a panic serving a call into an Internal error, logging its stack trace, instead of crashing")
+	normalizeErrors := flag.Bool("normalize_errors", true, "Map errors without a gRPC status, such as servicebus connection failures, to the gRPC code describing them")
+	callMetrics := flag.Bool("call_metrics", true, "Count calls by method and code and sum their durations as the grpc_calls and grpc_call_duration_ms expvars")
+	logLevel := flag.String("log_level", "info", "Least severe log messages written, by the server and gRPC: debug, info, warning or error")
+	configReloadInterval := flag.Duration("config_reload_interval", 30*time.Second, "How often the config, rate limit and routes files are checked for changes, 0 to disable reloading")
+
+	flag.String("config_file", "", "YAML or TOML file of settings named like these flags, overridden by CONTENTSERVICE_<FLAG> environment variables and then by flags")
+	flag.String("profile", "", "Profile in the config file, such as qa01, uat or prod, whose settings override the rest of the file")
+	printConfigOnly := flag.Bool("print_config", false, "Print the effective configuration with secrets redacted and exit")
 
 	flag.Parse()
+	sources, err := loadConfig(flag.CommandLine, os.Getenv)
+	if err != nil {
+		grpclog.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := validateConfig(flag.CommandLine); err != nil {
+		grpclog.Fatalf("%v", err)
+	}
+	if *printConfigOnly {
+		printConfig(os.Stdout, flag.CommandLine, sources)
+		return
+	}
+	runtime := newRuntimeConfig(flag.CommandLine, sources, os.Args[1:], os.Getenv)
+	level, _ := parseLogLevel(*logLevel)
+	logger.setLevel(level)
+	grpclog.SetLoggerV2(logger)
+	runtime.handle([]string{"log_level"}, func(fs *flag.FlagSet) (func(), error) {
+		level, err := parseLogLevel(fs.Lookup("log_level").Value.String())
+		return func() { logger.setLevel(level) }, err
+	})
+	serviceBusLocation, err := time.LoadLocation(*serviceBusTimezone)
+	if err != nil {
+		grpclog.Fatalf("Invalid servicebus_timezone: %v", err)
+	}
 	listen, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if err != nil {
 		grpclog.Fatalf("Failed to listen: %v", err)
 	}
 	var opts []grpc.ServerOption
+	var reloader *certReloader
 	if *tls {
-		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
+		reloader, err = newCertReloader(*certFile, *keyFile, *clientCAFile, *clientAuth)
 		if err != nil {
 			grpclog.Fatalf("Failed to generate credentials %v", err)
 		}
-		opts = []grpc.ServerOption{grpc.Creds(creds)}
+		reloader.publishExpiry()
+		go reloader.Watch(*tlsReloadInterval, nil)
+		opts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(reloader.tlsConfig("h2")))}
+	}
+	serverOpts := []serverOption{withPanicRecovery(*recoverPanics), withErrorNormalization(*normalizeErrors)}
+	if *callMetrics {
+		metrics := newCallMetrics()
+		metrics.publish()
+		serverOpts = append(serverOpts, withCallObserver(metrics))
+	}
+	outer, inner := newInterceptors(serverOpts...)
+	interceptors := outer
+	if *traceFile != "" {
+		exporter, err := tracing.NewFileExporter(*traceFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to open trace file %v", err)
+		}
+		tracer := tracing.NewTracer(exporter)
+		interceptors = append(interceptors, tracer.UnaryServerInterceptor())
+	}
+	var authenticators auth.Chain
+	if *tokenFile != "" {
+		tokens, err := auth.LoadStaticTokens(*tokenFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to load token file %v", err)
+		}
+		authenticators = append(authenticators, tokens)
+	}
+	if *jwtKeysFile != "" {
+		verifier, err := auth.LoadJWTVerifier(*jwtKeysFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to load JWT keys %v", err)
+		}
+		verifier.Issuer = *jwtIssuer
+		verifier.Audience = *jwtAudience
+		authenticators = append(authenticators, verifier)
+	}
+	var authenticator auth.Authenticator
+	if len(authenticators) > 0 {
+		authenticator = authenticators
+	}
+	authenticated := authenticator != nil || *tls && *clientAuth != "none"
+	if authenticated {
+		interceptors = append(interceptors, auth.UnaryServerInterceptor(authenticator))
+	}
+	requests := newRequestTracker(*adminRecentErrors)
+	interceptors = append(interceptors, requests.UnaryServerInterceptor())
+	interceptors = append(interceptors, inner...)
+	grpcServer := grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(interceptors...))...)
+	endpoints, err := newEndpointPool(strings.Split(*serviceBusEndPoint, ","), *serviceBusStrategy, *serviceBusEjectFailures, *serviceBusEjectDuration)
+	if err != nil {
+		grpclog.Fatalf("Invalid servicebus endpoints: %v", err)
+	}
+	endpoints.publish()
+	if *serviceBusProbeInterval > 0 {
+		go endpoints.Watch(*serviceBusProbeInterval, nil)
+	}
+	caller := &Caller{endpoints: endpoints, hedging: newHedging(*serviceBusHedgePercentile, *serviceBusHedgeMinDelay)}
+	caller.hedging.publish()
+	caller.setTimeout(*serviceBusTimeout)
+	runtime.handle([]string{"servicebus_timeout"}, func(fs *flag.FlagSet) (func(), error) {
+		timeout := durationSetting(fs, "servicebus_timeout")
+		if timeout < 0 {
+			return nil, fmt.Errorf("servicebus_timeout must not be negative, got %s", timeout)
+		}
+		return func() { caller.setTimeout(timeout) }, nil
+	})
+	runtime.handle([]string{"servicebus_hedge_percentile", "servicebus_hedge_min_delay"}, func(fs *flag.FlagSet) (func(), error) {
+		percentile, minDelay := floatSetting(fs, "servicebus_hedge_percentile"), durationSetting(fs, "servicebus_hedge_min_delay")
+		return func() { caller.hedging.set(percentile, minDelay) }, nil
+	})
+	var serviceBus ServiceBusCaller = caller
+	var limiter *concurrencyLimiter
+	if *serviceBusConcurrencyMax > 0 {
+		limiter = newConcurrencyLimiter(*serviceBusConcurrencyInitial, *serviceBusConcurrencyMax, *serviceBusLatencyTarget, *serviceBusQueueSize, *serviceBusQueueTimeout)
+		limiter.publish()
+		serviceBus = &limitedCaller{next: serviceBus, limiter: limiter}
+	}
+	runtime.handle([]string{"servicebus_concurrency_max", "servicebus_latency_target", "servicebus_queue_size", "servicebus_queue_timeout"}, func(fs *flag.FlagSet) (func(), error) {
+		max := intSetting(fs, "servicebus_concurrency_max")
+		if (max > 0) != (limiter != nil) {
+			return nil, fmt.Errorf("servicebus_concurrency_max cannot turn concurrency limiting on or off without a restart")
+		}
+		if limiter == nil {
+			return func() {}, nil
+		}
+		latencyTarget, queueSize, queueTimeout := durationSetting(fs, "servicebus_latency_target"), intSetting(fs, "servicebus_queue_size"), durationSetting(fs, "servicebus_queue_timeout")
+		return func() { limiter.setLimits(max, latencyTarget, queueSize, queueTimeout) }, nil
+	})
+	pools := []*endpointPool{endpoints}
+	var shadow *shadowCaller
+	if *shadowEndPoint != "" {
+		shadowEndpoints, err := newEndpointPool(strings.Split(*shadowEndPoint, ","), *serviceBusStrategy, *serviceBusEjectFailures, *serviceBusEjectDuration)
+		if err != nil {
+			grpclog.Fatalf("Invalid shadow servicebus endpoints: %v", err)
+		}
+		report, err := openShadowReport(*shadowReportFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to open shadow report file %v", err)
+		}
+		shadow = newShadowCaller(serviceBus, &Caller{endpoints: shadowEndpoints}, *shadowPercent, *shadowTimeout, report)
+		shadow.publish()
+		serviceBus = shadow
+		pools = append(pools, shadowEndpoints)
+	}
+	breaker := newCircuit(serviceBus)
+	breaker.publish()
+	serviceBus = breaker
+	runtime.handle([]string{"shadow_sample_percent"}, func(fs *flag.FlagSet) (func(), error) {
+		percent := floatSetting(fs, "shadow_sample_percent")
+		return func() {
+			if shadow != nil {
+				shadow.setPercent(percent)
+			}
+		}, nil
+	})
+	runtime.handle([]string{"servicebus_eject_failures", "servicebus_eject_duration"}, func(fs *flag.FlagSet) (func(), error) {
+		failures, duration := intSetting(fs, "servicebus_eject_failures"), durationSetting(fs, "servicebus_eject_duration")
+		return func() {
+			for _, pool := range pools {
+				pool.setEjection(failures, duration)
+			}
+		}, nil
+	})
+	routes := &routing.AtomicTable{}
+	rateLimiter := ratelimit.NewLimiter(&ratelimit.Config{})
+	metadataPolicy := &imaging.AtomicMetadataPolicy{}
+	deriver := newReloadableDeriver(*derivativeCacheBytes)
+	deriver.publish()
+	server := &Server{
+		ServiceBusCaller: serviceBus,
+		RateLimiter:      rateLimiter,
+		Routes:           routes,
+		Deriver:          deriver,
+		Rotator:          &imaging.Rotator{Quality: *rotateJPEGQuality},
+		AutoOrient:       *autoOrient,
+		Sanitizer:        metadataPolicy,
+	}
+	if *policyFile != "" {
+		engine, err := policy.NewEngine(*policyFile)
+		if err != nil {
+			grpclog.Fatalf("Failed to load policy %v", err)
+		}
+		engine.Logger = logger
+		go engine.Watch(*policyReloadInterval, nil)
+		server.Authorizer = engine
+	}
+	if *auditFile != "" {
+		auditLog, err := audit.Open(*auditFile, *auditMaxBytes)
+		if err != nil {
+			grpclog.Fatalf("Failed to open audit file %v", err)
+		}
+		server.Auditor = auditLog
+		server.publishAuditFailures()
+	}
+	runtime.handle([]string{"derivative_sizes", "derivative_jpeg_quality", "derivative_max_pixels"}, func(fs *flag.FlagSet) (func(), error) {
+		sizes, err := imaging.ParseSizes(fs.Lookup("derivative_sizes").Value.String())
+		quality, maxPixels := intSetting(fs, "derivative_jpeg_quality"), intSetting(fs, "derivative_max_pixels")
+		return func() { deriver.set(sizes, quality, maxPixels) }, err
+	})
+	runtime.handle([]string{"metadata_policy_file"}, func(fs *flag.FlagSet) (func(), error) {
+		var rules *imaging.MetadataPolicy
+		if filename := fs.Lookup("metadata_policy_file").Value.String(); filename != "" {
+			var err error
+			if rules, err = imaging.LoadMetadataPolicy(filename); err != nil {
+				return nil, err
+			}
+		}
+		return func() { metadataPolicy.Set(rules) }, nil
+	})
+	runtime.handle([]string{"ratelimit_file"}, func(fs *flag.FlagSet) (func(), error) {
+		config := &ratelimit.Config{}
+		if filename := fs.Lookup("ratelimit_file").Value.String(); filename != "" {
+			var err error
+			if config, err = ratelimit.Load(filename); err != nil {
+				return nil, err
+			}
+		}
+		return func() { rateLimiter.SetConfig(config) }, nil
+	})
+	runtime.handle([]string{"routes_file"}, func(fs *flag.FlagSet) (func(), error) {
+		var table *routing.Table
+		if filename := fs.Lookup("routes_file").Value.String(); filename != "" {
+			var err error
+			if table, err = routing.Load(filename); err != nil {
+				return nil, err
+			}
+		}
+		return func() { routes.Set(table) }, nil
+	})
+	if _, err := runtime.Reload(); err != nil {
+		grpclog.Fatalf("Failed to load rate limits and routes %v", err)
+	}
+	if *configReloadInterval > 0 {
+		go runtime.Watch(*configReloadInterval, nil)
 	}
-	grpcServer := grpc.NewServer(opts...)
-	server := NewServer(*serviceBusEndPoint)
 	pb.RegisterContentServiceServer(grpcServer, server)
+	v2 := newServerV2(server, serviceBusLocation)
+	pbv2.RegisterContentServiceServer(grpcServer, v2)
+	if *enableReflection {
+		reflection.Register(grpcServer)
+	}
+	gateway := newGateway(server, v2, interceptors, gatewayLimits{
+		RequestBytes:    *httpMaxRequestBytes,
+		UploadBytes:     *httpMaxUploadBytes,
+		UploadFileBytes: *httpMaxUploadFileBytes,
+	})
+	runtime.handle([]string{"http_max_request_bytes", "http_max_upload_bytes", "http_max_upload_file_bytes"}, func(fs *flag.FlagSet) (func(), error) {
+		limits := gatewayLimits{
+			RequestBytes:    int64Setting(fs, "http_max_request_bytes"),
+			UploadBytes:     int64Setting(fs, "http_max_upload_bytes"),
+			UploadFileBytes: int64Setting(fs, "http_max_upload_file_bytes"),
+		}
+		return func() { gateway.setLimits(limits) }, nil
+	})
+	if *httpPort != 0 {
+		httpServer := &http.Server{
+			Addr:    fmt.Sprintf(":%d", *httpPort),
+			Handler: gateway,
+		}
+		go func() {
+			var err error
+			if *tls {
+				httpServer.TLSConfig = reloader.tlsConfig("h2", "http/1.1")
+				err = httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			logger.Errorf("Failed to serve HTTP: %v", err)
+		}()
+	}
+	if *adminPort != 0 {
+		if !authenticated {
+			grpclog.Fatalf("admin_port requires token_file, jwt_keys_file or client certificates to authenticate admins")
+		}
+		adminListen, err := net.Listen("tcp", fmt.Sprintf(":%d", *adminPort))
+		if err != nil {
+			grpclog.Fatalf("Failed to listen for admin: %v", err)
+		}
+		adminGRPCServer := grpc.NewServer(append(opts, grpc.ChainUnaryInterceptor(adminInterceptors(outer, inner, authenticator, *adminRole)...))...)
+		pbadmin.RegisterContentServiceAdminServer(adminGRPCServer, &adminServer{config: runtime, logger: logger, circuit: breaker, requests: requests})
+		go func() {
+			if err := adminGRPCServer.Serve(adminListen); err != nil {
+				logger.Errorf("Failed to serve admin: %v", err)
+			}
+		}()
+	}
+	if *debugPort != 0 {
+		go func() {
+			if err := http.ListenAndServe(fmt.Sprintf(":%d", *debugPort), nil); err != nil {
+				logger.Errorf("Failed to serve debug: %v", err)
+			}
+		}()
+	}
 	if err := grpcServer.Serve(listen); err != nil {
-		fmt.Println("Failed to serve: ", err)
+		logger.Errorf("Failed to serve: %v", err)
 	}
 }
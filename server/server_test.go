@@ -2,11 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/divyag9/gothinnercontentservice/auth"
 	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/routing"
 )
 
 type FakeServer struct {
@@ -14,7 +22,7 @@ type FakeServer struct {
 	Err      error
 }
 
-func (f *FakeServer) callServiceBus(request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+func (f *FakeServer) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
 	if f.Err != nil {
 		return nil, f.Err
 	}
@@ -98,11 +106,11 @@ var cases = []struct {
 }
 
 var jsonRPCRequestCases = []struct {
-	putRequest          *pb.PutRequest
+	putParams           *pb.PutParams
 	expectedJSONRequest *pb.JSONRPCRequest
 }{
 	{
-		putRequest: &pb.PutRequest{Contractorid: 72494,
+		putParams: &pb.PutParams{Contractorid: 72494,
 			Ordernumber: 600016555,
 			Imagetype:   1,
 			Filename:    "test.png",
@@ -113,7 +121,7 @@ var jsonRPCRequestCases = []struct {
 		},
 		expectedJSONRequest: &pb.JSONRPCRequest{Jsonrpc: "2.0",
 			Method: "CONTENTSERVICE.PUT",
-			Params: &pb.PutRequest{Contractorid: 72494,
+			Params: &pb.PutParams{Contractorid: 72494,
 				Ordernumber: 600016555,
 				Imagetype:   1,
 				Filename:    "test.png",
@@ -190,7 +198,7 @@ func TestCallServiceBus(t *testing.T) {
 
 func TestCreateJSONRPCRequest(t *testing.T) {
 	for _, c := range jsonRPCRequestCases {
-		jsonRequest := createJSONRPCRequest(c.putRequest)
+		jsonRequest := createJSONRPCRequest(c.putParams, putMethod)
 		if !reflect.DeepEqual(jsonRequest, c.expectedJSONRequest) {
 			t.Errorf("Expected %q but got %q", c.expectedJSONRequest, jsonRequest)
 		}
@@ -205,3 +213,96 @@ func TestCreatePutResponse(t *testing.T) {
 		}
 	}
 }
+
+type FakeAuthorizer struct {
+	Err error
+}
+
+func (f *FakeAuthorizer) Authorize(identity *auth.Identity, contractorID int64, deptcode string) error {
+	if identity == nil {
+		return errors.New("caller is not authenticated")
+	}
+	return f.Err
+}
+
+var authorizeCases = []struct {
+	authorizer   *FakeAuthorizer
+	identity     *auth.Identity
+	expectedCode codes.Code
+}{
+	{authorizer: &FakeAuthorizer{}, identity: &auth.Identity{Subject: "portal"}, expectedCode: codes.OK},
+	{authorizer: &FakeAuthorizer{Err: errors.New("no")}, identity: &auth.Identity{Subject: "portal"}, expectedCode: codes.PermissionDenied},
+	{authorizer: &FakeAuthorizer{}, identity: nil, expectedCode: codes.PermissionDenied},
+}
+
+func TestPutAuthorization(t *testing.T) {
+	for _, c := range authorizeCases {
+		server := NewServer("http://servicebus.qa01.local/Execute.svc/Execute")
+		server.ServiceBusCaller = &FakeServer{Response: &pb.JSONRPCResponse{}}
+		server.Authorizer = c.authorizer
+		ctx := context.Background()
+		if c.identity != nil {
+			ctx = auth.NewContext(ctx, c.identity)
+		}
+		_, err := server.Put(ctx, &pb.PutRequest{Contractorid: 72494, Ordernumber: 600016555, Filename: "test.png"})
+		if status.Code(err) != c.expectedCode {
+			t.Errorf("Expected code %v but got %v", c.expectedCode, err)
+		}
+	}
+}
+
+var routeCases = []struct {
+	request          *pb.PutRequest
+	expectedPath     string
+	expectedMethod   string
+	expectedPosition bool
+}{
+	{
+		request:        &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.png", Imagetype: 1, Deptcode: "01"},
+		expectedPath:   "/default",
+		expectedMethod: "CONTENTSERVICE.PUT",
+	},
+	{
+		request:        &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.pdf", Imagetype: 2, Deptcode: "01"},
+		expectedPath:   "/documents",
+		expectedMethod: "CONTENTSERVICE.DOCUMENTPUT",
+	},
+	{
+		request:          &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.png", Imagetype: 1, Deptcode: "02"},
+		expectedPath:     "/default",
+		expectedMethod:   "CONTENTSERVICE.DEPT02PUT",
+		expectedPosition: true,
+	},
+}
+
+func TestPutRoutes(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	serviceBus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"jsonrpc":"2.0","result":{"id":1}}`))
+	}))
+	defer serviceBus.Close()
+	server := NewServer(serviceBus.URL + "/default")
+	server.Routes = &routing.Table{Routes: []routing.Route{
+		{Imagetypes: []int32{2}, Method: "CONTENTSERVICE.DOCUMENTPUT", Endpoint: serviceBus.URL + "/documents"},
+		{Deptcodes: []string{"02"}, Method: "CONTENTSERVICE.DEPT02PUT", Params: routing.ParamsArray},
+	}}
+	for _, c := range routeCases {
+		if _, err := server.Put(context.Background(), c.request); err != nil {
+			t.Errorf("%s: unexpected error %v", c.expectedMethod, err)
+			continue
+		}
+		if path != c.expectedPath {
+			t.Errorf("%s: expected path %s but got %s", c.expectedMethod, c.expectedPath, path)
+		}
+		if body["method"] != c.expectedMethod {
+			t.Errorf("Expected method %s but got %v", c.expectedMethod, body["method"])
+		}
+		if _, positional := body["params"].([]interface{}); positional != c.expectedPosition {
+			t.Errorf("%s: expected positional params %v but got %v", c.expectedMethod, c.expectedPosition, body["params"])
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/routing"
+)
+
+// volatileResponseFields are the JSONRPCResponse fields expected to differ
+// between ServiceBus environments, such as generated ids, dates and paths
+var volatileResponseFields = map[string]bool{
+	"id":                                     true,
+	"result.id":                              true,
+	"result.guid":                            true,
+	"result.scandate":                        true,
+	"result.datecreated":                     true,
+	"result.datemodefied":                    true,
+	"result.imagefilename":                   true,
+	"result.webfilename":                     true,
+	"result.inspiresponsedata.photodetailid": true,
+	"result.vendorwebresponsedata.documentid":   true,
+	"result.vendorwebresponsedata.annotationid": true,
+}
+
+// shadowCaller sends every request to primary and mirrors a sample of them
+// to shadow in the background, reporting where the responses differ. Only
+// the primary's response is returned.
+type shadowCaller struct {
+	primary ServiceBusCaller
+	shadow  ServiceBusCaller
+	// percent of requests mirrored
+	mu      sync.RWMutex
+	percent float64
+	timeout time.Duration
+	report  *shadowReport
+	// slots bounds the mirrored requests in flight; requests arriving when
+	// it is full are not mirrored
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mirrored expvar.Int
+	dropped  expvar.Int
+	errors   expvar.Int
+	diffs    expvar.Int
+}
+
+func newShadowCaller(primary, shadow ServiceBusCaller, percent float64, timeout time.Duration, report *shadowReport) *shadowCaller {
+	return &shadowCaller{primary: primary, shadow: shadow, percent: percent, timeout: timeout, report: report, slots: make(chan struct{}, 16)}
+}
+
+func (s *shadowCaller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	response, err := s.primary.callServiceBus(ctx, request)
+	s.mu.RLock()
+	percent := s.percent
+	s.mu.RUnlock()
+	if err != nil || rand.Float64()*100 >= percent {
+		return response, err
+	}
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.dropped.Add(1)
+		return response, err
+	}
+	s.mirrored.Add(1)
+	s.wg.Add(1)
+	// The server adds to the result it is returned, such as the thumbnail
+	// size, so it is compared with the shadow's as ServiceBus returned it
+	primary := proto.Clone(response).(*pb.JSONRPCResponse)
+	go func() {
+		defer func() {
+			<-s.slots
+			s.wg.Done()
+		}()
+		s.mirror(ctx, request, primary)
+	}()
+	return response, err
+}
+
+// setPercent changes the percentage of requests mirrored
+func (s *shadowCaller) setPercent(percent float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.percent = percent
+}
+
+// wait blocks until every mirrored request has completed
+func (s *shadowCaller) wait() {
+	s.wg.Wait()
+}
+
+// mirror sends request to the shadow, detached from the caller's deadline
+// and cancellation, and reports any difference from the primary response
+func (s *shadowCaller) mirror(ctx context.Context, request *pb.JSONRPCRequest, primary *pb.JSONRPCResponse) {
+	shadowCtx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if route, ok := routing.FromContext(ctx); ok {
+		// The route's endpoint override belongs to the primary environment
+		route.Endpoint = ""
+		shadowCtx = routing.NewContext(shadowCtx, route)
+	}
+	entry := &shadowReportEntry{
+		Time:         time.Now(),
+		Method:       request.GetMethod(),
+		Contractorid: request.GetParams().GetContractorid(),
+		Ordernumber:  request.GetParams().GetOrdernumber(),
+		Filename:     request.GetParams().GetFilename(),
+	}
+	shadow, err := s.shadow.callServiceBus(shadowCtx, request)
+	if err != nil {
+		s.errors.Add(1)
+		entry.ShadowError = err.Error()
+	} else {
+		entry.Differences = diffResponses(primary, shadow)
+		if len(entry.Differences) == 0 {
+			return
+		}
+		s.diffs.Add(1)
+	}
+	if err := s.report.write(entry); err != nil {
+		logger.Errorf("Failed to write shadow report: %v", err)
+	}
+}
+
+// publish exposes the mirrored request counts as the servicebus_shadow expvar
+func (s *shadowCaller) publish() {
+	m := expvar.NewMap("servicebus_shadow")
+	m.Set("mirrored", &s.mirrored)
+	m.Set("dropped", &s.dropped)
+	m.Set("errors", &s.errors)
+	m.Set("diffs", &s.diffs)
+}
+
+// responseDifference is a field whose value differs between the primary and
+// shadow responses. A missing field has a nil value.
+type responseDifference struct {
+	Field   string      `json:"field"`
+	Primary interface{} `json:"primary"`
+	Shadow  interface{} `json:"shadow"`
+}
+
+// diffResponses compares the non volatile fields of primary and shadow,
+// returning the differences ordered by field
+func diffResponses(primary, shadow *pb.JSONRPCResponse) []responseDifference {
+	primaryFields, shadowFields := flattenJSON(primary), flattenJSON(shadow)
+	var differences []responseDifference
+	for field, value := range primaryFields {
+		if !reflect.DeepEqual(value, shadowFields[field]) {
+			differences = append(differences, responseDifference{Field: field, Primary: value, Shadow: shadowFields[field]})
+		}
+	}
+	for field, value := range shadowFields {
+		if _, ok := primaryFields[field]; !ok {
+			differences = append(differences, responseDifference{Field: field, Shadow: value})
+		}
+	}
+	sort.Slice(differences, func(i, j int) bool { return differences[i].Field < differences[j].Field })
+	return differences
+}
+
+// flattenJSON returns the fields of v's JSON encoding keyed by dotted path,
+// leaving out volatileResponseFields
+func flattenJSON(v interface{}) map[string]interface{} {
+	var decoded interface{}
+	encoded, _ := json.Marshal(v)
+	json.Unmarshal(encoded, &decoded)
+	fields := make(map[string]interface{})
+	flattenValue("", decoded, fields)
+	return fields
+}
+
+func flattenValue(path string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s.%d", path, i), child, fields)
+		}
+	default:
+		if !volatileResponseFields[path] {
+			fields[path] = v
+		}
+	}
+}
+
+// shadowReportEntry records a mirrored request whose shadow response
+// differed from the primary or failed
+type shadowReportEntry struct {
+	Time         time.Time            `json:"time"`
+	Method       string               `json:"method"`
+	Contractorid int64                `json:"contractorid"`
+	Ordernumber  int64                `json:"ordernumber"`
+	Filename     string               `json:"filename"`
+	ShadowError  string               `json:"shadow_error,omitempty"`
+	Differences  []responseDifference `json:"differences,omitempty"`
+}
+
+// shadowReport writes report entries as JSON lines
+type shadowReport struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newShadowReport(w io.Writer) *shadowReport {
+	return &shadowReport{encoder: json.NewEncoder(w)}
+}
+
+// openShadowReport appends the report to the file at path, or writes it to
+// stdout if path is "-"
+func openShadowReport(path string) (*shadowReport, error) {
+	if path == "-" {
+		return newShadowReport(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return newShadowReport(file), nil
+}
+
+func (r *shadowReport) write(entry *shadowReportEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.encoder.Encode(entry)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	"github.com/divyag9/gothinnercontentservice/imaging"
+)
+
+var diffCases = []struct {
+	primary  *pb.JSONRPCResponse
+	shadow   *pb.JSONRPCResponse
+	expected []responseDifference
+}{
+	{
+		primary: &pb.JSONRPCResponse{Id: 1, Result: &pb.JSONRPCResult{Id: 10, Deptcode: "01", Scandate: "2017-03-09T10:33:09", Guid: "a"}},
+		shadow:  &pb.JSONRPCResponse{Id: 2, Result: &pb.JSONRPCResult{Id: 20, Deptcode: "01", Scandate: "2019-01-01T00:00:00", Guid: "b"}},
+	},
+	{
+		primary: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Deptcode: "01", Filesize: 180, Inspiresponsedata: &pb.InspiPutResponse{Photodetailid: 5}}},
+		shadow:  &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Deptcode: "02", Inspiresponsedata: &pb.InspiPutResponse{Photodetailid: 6}, Mimetype: "image/png"}},
+		expected: []responseDifference{
+			{Field: "result.deptcode", Primary: "01", Shadow: "02"},
+			{Field: "result.filesize", Primary: float64(180)},
+			{Field: "result.mimetype", Shadow: "image/png"},
+		},
+	},
+	{
+		primary:  &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Deptcode: "01"}},
+		shadow:   &pb.JSONRPCResponse{Error: &pb.JSONRPCError{Code: 500, Message: "Failed"}},
+		expected: []responseDifference{{Field: "error.code", Shadow: float64(500)}, {Field: "error.message", Shadow: "Failed"}, {Field: "result.deptcode", Primary: "01"}},
+	},
+}
+
+func TestDiffResponses(t *testing.T) {
+	for i, c := range diffCases {
+		if differences := diffResponses(c.primary, c.shadow); !reflect.DeepEqual(differences, c.expected) {
+			t.Errorf("Case %d: expected %+v but got %+v", i, c.expected, differences)
+		}
+	}
+}
+
+func TestShadowCaller(t *testing.T) {
+	primary := &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1, Deptcode: "01"}}}
+	shadow := &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 2, Deptcode: "02"}}}
+	var report bytes.Buffer
+	caller := newShadowCaller(primary, shadow, 100, time.Second, newShadowReport(&report))
+	request := &pb.JSONRPCRequest{Method: putMethod, Params: &pb.PutParams{Contractorid: 72494, Ordernumber: 1, Filename: "a.png"}}
+	response, err := caller.callServiceBus(context.Background(), request)
+	if err != nil || response.GetResult().GetId() != 1 {
+		t.Fatalf("Expected the primary response but got %v, %v", response, err)
+	}
+	caller.wait()
+	entry := &shadowReportEntry{}
+	if err := json.Unmarshal(report.Bytes(), entry); err != nil {
+		t.Fatalf("Error reading report %q: %v", report.String(), err)
+	}
+	if entry.Ordernumber != 1 || len(entry.Differences) != 1 || entry.Differences[0].Field != "result.deptcode" {
+		t.Errorf("Unexpected report entry %+v", entry)
+	}
+	if caller.diffs.Value() != 1 {
+		t.Errorf("Expected 1 diff but got %d", caller.diffs.Value())
+	}
+}
+
+func TestShadowPutReportsOnlyServiceBusDifferences(t *testing.T) {
+	primary := &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1, Deptcode: "01"}}}
+	shadow := &FakeServer{Response: &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1, Deptcode: "01"}}}
+	var report bytes.Buffer
+	caller := newShadowCaller(primary, shadow, 100, time.Second, newShadowReport(&report))
+	deriver := &FakeDeriver{Derivatives: []imaging.Derivative{{Name: thumbnailDerivative, Contents: []byte("thumbnail")}}}
+	server := &Server{ServiceBusCaller: caller, Deriver: deriver, Rotator: &FakeRotator{Degrees: 90}, AutoOrient: true}
+	response, err := server.Put(context.Background(), &pb.PutRequest{Contractorid: 72494, Ordernumber: 1, Filename: "a.png", Filecontents: []byte("png")})
+	if err != nil || response.GetResult().GetThumbnailsize() != 9 || response.GetResult().GetImagerotated() != 90 {
+		t.Fatalf("Expected the thumbnail size and rotation in the response but got %v, %v", response, err)
+	}
+	caller.wait()
+	if report.Len() != 0 {
+		t.Errorf("Expected the fields the server adds not to be reported as differences but got %s", report.String())
+	}
+}
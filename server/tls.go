@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// clientAuthTypes maps the client_auth flag to the TLS client certificate policy
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":     tls.NoClientCert,
+	"optional": tls.VerifyClientCertIfGiven,
+	"required": tls.RequireAndVerifyClientCert,
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pemBytes, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("No certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// newServerTLSConfig creates the server TLS configuration, verifying client
+// certificates against clientCAFile according to clientAuth
+func newServerTLSConfig(certFile, keyFile, clientCAFile, clientAuth string) (*tls.Config, error) {
+	clientAuthType, ok := clientAuthTypes[clientAuth]
+	if !ok {
+		return nil, fmt.Errorf("Unknown client_auth %q, expected none, optional or required", clientAuth)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, ClientAuth: clientAuthType}
+	if clientAuthType != tls.NoClientCert {
+		if clientCAFile == "" {
+			return nil, fmt.Errorf("client_ca_file is required when client_auth is %s", clientAuth)
+		}
+		config.ClientCAs, err = loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// certReloader serves the TLS configuration built from the certificate, key
+// and client CA files, rebuilding it when any of them change. Existing
+// connections keep the configuration they were established with.
+type certReloader struct {
+	certFile, keyFile, clientCAFile, clientAuth string
+
+	config atomic.Value
+
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+}
+
+// newCertReloader loads the initial TLS configuration
+func newCertReloader(certFile, keyFile, clientCAFile, clientAuth string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile, clientAuth: clientAuth}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// tlsConfig returns a configuration which uses the current certificates for
+// each new handshake, negotiating one of nextProtos
+func (r *certReloader) tlsConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			config := r.config.Load().(*tls.Config).Clone()
+			config.NextProtos = nextProtos
+			return config, nil
+		},
+		NextProtos: nextProtos,
+	}
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+	return files
+}
+
+// reload rebuilds the configuration if any of the files have changed. An
+// invalid update, e.g. a certificate written before its key, leaves the
+// current configuration in place.
+func (r *certReloader) reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	modTimes := make(map[string]time.Time)
+	changed := r.modTimes == nil
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		modTimes[file] = info.ModTime()
+		changed = changed || !info.ModTime().Equal(r.modTimes[file])
+	}
+	if !changed {
+		return false, nil
+	}
+	config, err := newServerTLSConfig(r.certFile, r.keyFile, r.clientCAFile, r.clientAuth)
+	if err != nil {
+		return false, err
+	}
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		return false, err
+	}
+	config.Certificates[0].Leaf = leaf
+	r.config.Store(config)
+	r.modTimes = modTimes
+	logger.Infof("Loaded TLS certificate %s for %s, expires %s", r.certFile, leaf.Subject.CommonName, leaf.NotAfter.Format(time.RFC3339))
+	return true, nil
+}
+
+// daysToExpiry returns the days remaining until the current certificate expires
+func (r *certReloader) daysToExpiry() float64 {
+	config := r.config.Load().(*tls.Config)
+	return time.Until(config.Certificates[0].Leaf.NotAfter).Hours() / 24
+}
+
+// Watch checks the files for changes every interval until stop is closed
+func (r *certReloader) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := r.reload(); err != nil {
+				logger.Errorf("Failed to reload TLS certificate, keeping current certificate: %v", err)
+			}
+		}
+	}
+}
+
+// publishExpiry exposes the days until the certificate expires as the
+// tls_certificate_days_to_expiry expvar
+func (r *certReloader) publishExpiry() {
+	expvar.Publish("tls_certificate_days_to_expiry", expvar.Func(func() interface{} {
+		return r.daysToExpiry()
+	}))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self signed certificate and key for
+// commonName to dir and returns their file names
+func writeTestCertificate(t *testing.T, dir, commonName string, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{commonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, commonName+".pem")
+	keyFile := filepath.Join(dir, commonName+".key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir, "server1", time.Now().Add(24*time.Hour))
+	caFile, _ := writeTestCertificate(t, dir, "clientca", time.Now().Add(24*time.Hour))
+
+	cases := []struct {
+		clientCAFile   string
+		clientAuth     string
+		expectedErr    bool
+		expectedPolicy tls.ClientAuthType
+	}{
+		{clientAuth: "none", expectedPolicy: tls.NoClientCert},
+		{clientCAFile: caFile, clientAuth: "optional", expectedPolicy: tls.VerifyClientCertIfGiven},
+		{clientCAFile: caFile, clientAuth: "required", expectedPolicy: tls.RequireAndVerifyClientCert},
+		{clientAuth: "required", expectedErr: true},
+		{clientCAFile: keyFile, clientAuth: "required", expectedErr: true},
+		{clientCAFile: caFile, clientAuth: "sometimes", expectedErr: true},
+	}
+	for _, c := range cases {
+		config, err := newServerTLSConfig(certFile, keyFile, c.clientCAFile, c.clientAuth)
+		if (err != nil) != c.expectedErr {
+			t.Errorf("%s %s: expected error %v but got %v", c.clientAuth, c.clientCAFile, c.expectedErr, err)
+			continue
+		}
+		if err == nil && config.ClientAuth != c.expectedPolicy {
+			t.Errorf("Expected client auth %v but got %v", c.expectedPolicy, config.ClientAuth)
+		}
+		if err == nil && c.clientCAFile != "" && config.ClientCAs == nil {
+			t.Errorf("Expected client CAs to be loaded from %s", c.clientCAFile)
+		}
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir, "server1", time.Now().Add(10*24*time.Hour))
+	reloader, err := newCertReloader(certFile, keyFile, "", "none")
+	if err != nil {
+		t.Fatalf("Error loading certificate: %v", err)
+	}
+	if days := reloader.daysToExpiry(); days < 9 || days > 10 {
+		t.Errorf("Expected about 10 days to expiry but got %v", days)
+	}
+	if reloaded, err := reloader.reload(); reloaded || err != nil {
+		t.Errorf("Expected no reload for unchanged files but got %v, %v", reloaded, err)
+	}
+
+	// Rotate to a certificate with a later expiry
+	newCertFile, newKeyFile := writeTestCertificate(t, dir, "server2", time.Now().Add(90*24*time.Hour))
+	for from, to := range map[string]string{newCertFile: certFile, newKeyFile: keyFile} {
+		if err := os.Rename(from, to); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(to, time.Now(), time.Now().Add(time.Second))
+	}
+	if reloaded, err := reloader.reload(); !reloaded || err != nil {
+		t.Fatalf("Expected reload but got %v, %v", reloaded, err)
+	}
+	config, _ := reloader.tlsConfig("h2").GetConfigForClient(&tls.ClientHelloInfo{})
+	if config.Certificates[0].Leaf.Subject.CommonName != "server2" {
+		t.Errorf("Expected rotated certificate but got %s", config.Certificates[0].Leaf.Subject.CommonName)
+	}
+
+	// A half written rotation keeps the current certificate
+	if err := ioutil.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(keyFile, time.Now(), time.Now().Add(2*time.Second))
+	if _, err := reloader.reload(); err == nil {
+		t.Errorf("Expected error reloading invalid key")
+	}
+	config, _ = reloader.tlsConfig("h2").GetConfigForClient(&tls.ClientHelloInfo{})
+	if config.Certificates[0].Leaf.Subject.CommonName != "server2" {
+		t.Errorf("Expected current certificate to remain but got %s", config.Certificates[0].Leaf.Subject.CommonName)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadResult is the outcome of one file of a /v1/uploads form
+type uploadResult struct {
+	Filename string           `json:"filename"`
+	Response interface{}      `json:"response,omitempty"`
+	Error    *httpErrorDetail `json:"error,omitempty"`
+}
+
+// uploadResponse is the body returned by /v1/uploads
+type uploadResponse struct {
+	Results []*uploadResult `json:"results"`
+}
+
+// handleUpload accepts a browser multipart/form-data upload of one or more
+// files. Parts are read as they arrive: metadata fields apply to the file
+// parts which follow them, and each file is sent through Put as soon as it
+// has been read, so the form's fields must precede its files.
+func (g *gateway) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeMethodNotAllowed(w, r, "POST")
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		writeError(w, status.Errorf(codes.InvalidArgument, "Unsupported content type %q, expected multipart/form-data", mediaType))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, g.limits().UploadBytes)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "Invalid multipart form: %s", err))
+		return
+	}
+
+	fields := make(map[string]string)
+	response := &uploadResponse{Results: []*uploadResult{}}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, bodyError(err, "Invalid multipart form: %s"))
+			return
+		}
+		if part.FileName() == "" {
+			value, err := readPart(part, maxFieldBytes)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			fields[part.FormName()] = string(value)
+			continue
+		}
+		// Forms of many files are bulk imports, so their files after the
+		// first wait behind single uploads. The first is sent before it is
+		// known to have followers, unless the client asked for the batch lane.
+		if len(response.Results) == 1 {
+			r = r.WithContext(withPriority(r.Context(), priorityBatch))
+		}
+		response.Results = append(response.Results, g.uploadFile(r, fields, part.FileName(), part))
+	}
+	if len(response.Results) == 0 {
+		writeError(w, status.Error(codes.InvalidArgument, "No files in upload"))
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// maxFieldBytes bounds each metadata field of an upload form
+const maxFieldBytes = 1 << 10
+
+// readPart reads at most limit bytes of part
+func readPart(part io.Reader, limit int64) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	n, err := io.Copy(buf, io.LimitReader(part, limit+1))
+	if err != nil {
+		return nil, bodyError(err, "Error reading upload: %s")
+	}
+	if n > limit {
+		return nil, &tooLargeError{limit: limit}
+	}
+	return buf.Bytes(), nil
+}
+
+func (g *gateway) uploadFile(r *http.Request, fields map[string]string, filename string, part io.Reader) *uploadResult {
+	result := &uploadResult{Filename: filename}
+	contents, err := readPart(part, g.limits().UploadFileBytes)
+	if err != nil {
+		// Drain the rest of an oversized file so the following parts can be read
+		io.Copy(ioutil.Discard, part)
+		result.Error = newHTTPErrorDetail(err)
+		return result
+	}
+	request, err := putRequestFromForm(func(name string) string { return fields[name] })
+	if err != nil {
+		result.Error = newHTTPErrorDetail(err)
+		return result
+	}
+	request.Filename = filename
+	request.Filecontents = contents
+	response, err := g.put(r, request)
+	if err != nil {
+		result.Error = newHTTPErrorDetail(err)
+		return result
+	}
+	result.Response = response
+	return result
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+)
+
+func TestUpload(t *testing.T) {
+	handler, caller := newTestGateway(t)
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("contractorid", "72494")
+	writer.WriteField("ordernumber", "600016555")
+	writer.WriteField("deptcode", "01")
+	for name, contents := range map[string]string{"front.png": "front", "back.png": "back"} {
+		part, _ := writer.CreateFormFile("photos", name)
+		part.Write([]byte(contents))
+	}
+	part, _ := writer.CreateFormFile("photos", "huge.png")
+	part.Write(bytes.Repeat([]byte("x"), 64))
+	writer.WriteField("contractorid", "bad")
+	part, _ = writer.CreateFormFile("photos", "late.png")
+	part.Write([]byte("late"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/v1/uploads", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body)
+	}
+	response := &struct {
+		Results []struct {
+			Filename string
+			Response *struct{ Result struct{ Id int32 } }
+			Error    *httpErrorDetail
+		}
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatalf("Expected JSON but got %s", w.Body)
+	}
+	if len(response.Results) != 4 {
+		t.Fatalf("Expected 4 results but got %s", w.Body)
+	}
+	for _, result := range response.Results {
+		switch result.Filename {
+		case "front.png", "back.png":
+			if result.Error != nil || result.Response.Result.Id != 1810448062 {
+				t.Errorf("Expected %s to succeed but got %+v", result.Filename, result.Error)
+			}
+		case "huge.png":
+			if result.Error == nil || result.Error.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("Expected %s to be rejected as too large but got %+v", result.Filename, result)
+			}
+		case "late.png":
+			if result.Error == nil || result.Error.Status != "INVALID_ARGUMENT" {
+				t.Errorf("Expected %s to be rejected but got %+v", result.Filename, result)
+			}
+		}
+	}
+	if caller.request.Params.Deptcode != "01" || caller.request.Params.Contractorid != 72494 {
+		t.Errorf("Expected form fields on the request but got %v", caller.request.Params)
+	}
+}
+
+func TestUploadWithoutFiles(t *testing.T) {
+	handler, _ := newTestGateway(t)
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("contractorid", "72494")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/v1/uploads", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 but got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	handler, _ := newTestGateway(t)
+	body, contentType := multipartBody(t, map[string]string{"contractorid": "72494"}, "photo.png", bytes.Repeat([]byte("x"), 1<<20))
+	req := httptest.NewRequest("POST", "/v1/uploads", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 but got %d: %s", w.Code, w.Body)
+	}
+}
+
+// priorityCaller records the lane of each ServiceBus call
+type priorityCaller struct {
+	priorities []priority
+}
+
+func (p *priorityCaller) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	p.priorities = append(p.priorities, priorityFromContext(ctx))
+	return &pb.JSONRPCResponse{Result: &pb.JSONRPCResult{Id: 1810448062}}, nil
+}
+
+var uploadPriorityCases = []struct {
+	files    int
+	header   string
+	expected []priority
+}{
+	{files: 1, expected: []priority{priorityInteractive}},
+	{files: 1, header: "batch", expected: []priority{priorityBatch}},
+	{files: 3, expected: []priority{priorityInteractive, priorityBatch, priorityBatch}},
+}
+
+func TestUploadPriority(t *testing.T) {
+	for _, c := range uploadPriorityCases {
+		caller := &priorityCaller{}
+		handler := newGateway(&Server{ServiceBusCaller: caller}, nil, nil, gatewayLimits{RequestBytes: 1 << 20, UploadBytes: 1 << 20, UploadFileBytes: 16})
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("contractorid", "72494")
+		for i := 0; i < c.files; i++ {
+			part, _ := writer.CreateFormFile("photos", fmt.Sprintf("photo%d.png", i))
+			part.Write([]byte("png"))
+		}
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/v1/uploads", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		if c.header != "" {
+			req.Header.Set(priorityHeader, c.header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if !reflect.DeepEqual(caller.priorities, c.expected) {
+			t.Errorf("Expected %v but got %v", c.expected, caller.priorities)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+)
+
+// serverV2 serves contentservice.v2.ContentService by converting its typed
+// messages to and from the ServiceBus representation used by Server
+type serverV2 struct {
+	pbv2.UnimplementedContentServiceServer
+	server *Server
+	// location is the time zone of the dates ServiceBus returns without one
+	location *time.Location
+}
+
+// newServerV2 creates the v2 service backed by server
+func newServerV2(server *Server, location *time.Location) *serverV2 {
+	return &serverV2{server: server, location: location}
+}
+
+// destinationMethods are the ServiceBus methods storing content for each
+// destination system when no route matches
+var destinationMethods = map[pbv2.Destination]string{
+	pbv2.Destination_DESTINATION_INSPI:     putMethod,
+	pbv2.Destination_DESTINATION_VENDORWEB: "CONTENTSERVICE.VENDORWEBPUT",
+}
+
+// Put performs the servicebus put through the method for the request's destination
+func (s *serverV2) Put(ctx context.Context, request *pbv2.PutRequest) (*pbv2.PutResponse, error) {
+	method, ok := destinationMethods[request.GetDestination()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "destination is required, got %v", request.GetDestination())
+	}
+	if err := validateDestinationRequest(request); err != nil {
+		return nil, err
+	}
+	response, err := s.server.put(ctx, putParamsFromV2(request), destinationName(request.GetDestination()), method)
+	if err != nil {
+		return nil, err
+	}
+	return putResponseToV2(response, request.GetDestination(), s.location), nil
+}
+
+// validateDestinationRequest checks that request only carries data for its
+// own destination
+func validateDestinationRequest(request *pbv2.PutRequest) error {
+	destination := request.GetDestination()
+	if request.GetInspi() != nil && destination != pbv2.Destination_DESTINATION_INSPI {
+		return status.Errorf(codes.InvalidArgument, "inspi is only valid for DESTINATION_INSPI, got %v", destination)
+	}
+	if request.GetVendorWeb() != nil && destination != pbv2.Destination_DESTINATION_VENDORWEB {
+		return status.Errorf(codes.InvalidArgument, "vendor_web is only valid for DESTINATION_VENDORWEB, got %v", destination)
+	}
+	return nil
+}
+
+// destinationName is the name routes use for destination, such as "inspi"
+func destinationName(destination pbv2.Destination) string {
+	return strings.ToLower(strings.TrimPrefix(destination.String(), "DESTINATION_"))
+}
+
+func putParamsFromV2(request *pbv2.PutRequest) *pb.PutParams {
+	params := &pb.PutParams{
+		Contractorid: request.GetContractorId(),
+		Ordernumber:  request.GetOrderNumber(),
+		Imagetype:    int32(request.GetImageType()),
+		Filename:     request.GetFilename(),
+		Imagewidth:   request.GetImageWidth(),
+		Imageheight:  request.GetImageHeight(),
+		Releasedate:  dateToServiceBus(request.GetReleaseDate()),
+		Deptcode:     request.GetDeptCode(),
+		Filecontents: request.GetFileContents(),
+	}
+	if inspi := request.GetInspi(); inspi != nil {
+		params.Inspirequestdata = &pb.InspiPutRequest{Photodetailid: inspi.GetPhotoDetailId()}
+	}
+	if vendorWeb := request.GetVendorWeb(); vendorWeb != nil {
+		params.Vendorwebrequestdata = &pb.VendorWebPutRequest{Documentid: vendorWeb.GetDocumentId()}
+	}
+	return params
+}
+
+// putResponseToV2 converts response, keeping only the data ServiceBus
+// returned for the destination the content was put for. The content has
+// already been stored, so data for another destination is logged and left
+// out rather than failing the call, which the client would retry.
+func putResponseToV2(response *pb.PutResponse, destination pbv2.Destination, location *time.Location) *pbv2.PutResponse {
+	responseV2 := &pbv2.PutResponse{}
+	if e := response.GetError(); e != nil {
+		responseV2.Error = &pbv2.PutError{Code: e.GetCode(), Message: e.GetMessage(), Data: e.GetData()}
+	}
+	if m := response.GetCapturemetadata(); m != nil {
+		responseV2.CaptureMetadata = &pbv2.CaptureMetadata{CaptureTime: timestampFromServiceBus(m.GetCapturetime(), location)}
+		if gps := m.GetGps(); gps != nil {
+			responseV2.CaptureMetadata.Gps = &pbv2.GPSPosition{Latitude: gps.GetLatitude(), Longitude: gps.GetLongitude(), Altitude: gps.GetAltitude()}
+		}
+	}
+	r := response.GetResult()
+	if r == nil {
+		return responseV2
+	}
+	responseV2.Result = &pbv2.PutResult{
+		ContractorId:  r.GetContractorid(),
+		ReleaseDate:   timestampFromServiceBus(r.GetReleasedate(), location),
+		ScanDate:      timestampFromServiceBus(r.GetScandate(), location),
+		ImageType:     pbv2.ImageType(r.GetImagetype()),
+		ImageWidth:    r.GetImagewidth(),
+		ImageHeight:   r.GetImageheight(),
+		DeptCode:      r.GetDeptcode(),
+		DescPrefix:    r.GetDescprefix(),
+		DescText:      r.GetDesctext(),
+		Category:      r.GetCategory(),
+		OrderNumber:   r.GetOrdernumber(),
+		ArchiveStatus: archiveStatusFromServiceBus(r.GetArchived()),
+		DateCreated:   timestampFromServiceBus(r.GetDatecreated(), location),
+		DateModified:  timestampFromServiceBus(r.GetDatemodefied(), location),
+		FileSize:      r.GetFilesize(),
+		Id:            r.GetId(),
+		ImageFilename: r.GetImagefilename(),
+		ImageRotated:  r.GetImagerotated(),
+		ThumbnailSize: r.GetThumbnailsize(),
+		WebFilename:   r.GetWebfilename(),
+		MimeType:      r.GetMimetype(),
+		Guid:          r.GetGuid(),
+	}
+	inspi, vendorWeb := r.GetInspiresponsedata(), r.GetVendorwebresponsedata()
+	if vendorWeb != nil && destination != pbv2.Destination_DESTINATION_VENDORWEB {
+		logger.Warningf("ServiceBus returned VendorWeb response data for %v put %d, which is left out", destination, r.GetId())
+		vendorWeb = nil
+	}
+	if inspi != nil && destination != pbv2.Destination_DESTINATION_INSPI {
+		logger.Warningf("ServiceBus returned INSPI response data for %v put %d, which is left out", destination, r.GetId())
+		inspi = nil
+	}
+	switch {
+	case inspi != nil:
+		responseV2.Result.DestinationResponse = &pbv2.PutResult_Inspi{Inspi: &pbv2.InspiPutResponse{PhotoDetailId: inspi.GetPhotodetailid()}}
+	case vendorWeb != nil:
+		responseV2.Result.DestinationResponse = &pbv2.PutResult_VendorWeb{VendorWeb: &pbv2.VendorWebPutResponse{DocumentId: vendorWeb.GetDocumentid(), AnnotationId: vendorWeb.GetAnnotationid()}}
+	}
+	return responseV2
+}
+
+// dateToServiceBus formats d as the yyyy-mm-dd ServiceBus expects
+func dateToServiceBus(d *date.Date) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.GetYear(), d.GetMonth(), d.GetDay())
+}
+
+// serviceBusTimeLayouts are the formats of ServiceBus dates, which usually
+// carry no time zone
+var serviceBusTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// timestampFromServiceBus parses a ServiceBus date in location. The content
+// has already been stored when dates are converted, so a date which cannot
+// be parsed is logged and left out rather than failing the call.
+func timestampFromServiceBus(value string, location *time.Location) *timestamppb.Timestamp {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range serviceBusTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return timestamppb.New(t)
+		}
+	}
+	logger.Warningf("Unable to parse ServiceBus date %q", value)
+	return nil
+}
+
+// archiveStatusFromServiceBus converts the ServiceBus "N"/"Y" archived flag
+func archiveStatusFromServiceBus(archived string) pbv2.ArchiveStatus {
+	switch archived {
+	case "N":
+		return pbv2.ArchiveStatus_ARCHIVE_STATUS_ACTIVE
+	case "Y":
+		return pbv2.ArchiveStatus_ARCHIVE_STATUS_ARCHIVED
+	default:
+		return pbv2.ArchiveStatus_ARCHIVE_STATUS_UNSPECIFIED
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/divyag9/gothinnercontentservice/contentservice"
+	pbv2 "github.com/divyag9/gothinnercontentservice/contentservice/v2"
+)
+
+func TestPutV2(t *testing.T) {
+	caller := &FakeServer{Response: &pb.JSONRPCResponse{Jsonrpc: "2.0",
+		Result: &pb.JSONRPCResult{Contractorid: 72494,
+			Releasedate:       "2015-08-06T15:09:30",
+			Scandate:          "2017-03-09T10:33:09.25",
+			Imagetype:         2,
+			Ordernumber:       600016555,
+			Archived:          "Y",
+			Datecreated:       "2017-03-09T10:33:09",
+			Datemodefied:      "not a date",
+			Id:                1810448062,
+			Inspiresponsedata: &pb.InspiPutResponse{Photodetailid: 5},
+		}}}
+	recorder := &recordingCaller{}
+	server := &Server{ServiceBusCaller: recorder}
+	location := time.FixedZone("CST", -6*60*60)
+	serverV2 := newServerV2(server, location)
+
+	request := &pbv2.PutRequest{ContractorId: 72494,
+		OrderNumber: 600016555,
+		ImageType:   pbv2.ImageType_IMAGE_TYPE_DOCUMENT,
+		Filename:    "test.pdf",
+		ReleaseDate: &date.Date{Year: 2015, Month: 8, Day: 6},
+		DeptCode:    "01",
+		Destination: pbv2.Destination_DESTINATION_INSPI,
+	}
+	if _, err := serverV2.Put(context.Background(), request); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expectedRequest := &pb.PutParams{Contractorid: 72494, Ordernumber: 600016555, Imagetype: 2, Filename: "test.pdf", Releasedate: "2015-08-06", Deptcode: "01"}
+	if recorder.request.Method != "CONTENTSERVICE.PUT" {
+		t.Errorf("Expected INSPI put method but got %s", recorder.request.Method)
+	}
+	if !proto.Equal(recorder.request.Params, expectedRequest) {
+		t.Errorf("Expected %v but got %v", expectedRequest, recorder.request.Params)
+	}
+
+	server.ServiceBusCaller = caller
+	response, err := serverV2.Put(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := &pbv2.PutResponse{Result: &pbv2.PutResult{ContractorId: 72494,
+		ReleaseDate:         timestamppb.New(time.Date(2015, 8, 6, 15, 9, 30, 0, location)),
+		ScanDate:            timestamppb.New(time.Date(2017, 3, 9, 10, 33, 9, 250000000, location)),
+		ImageType:           pbv2.ImageType_IMAGE_TYPE_DOCUMENT,
+		OrderNumber:         600016555,
+		ArchiveStatus:       pbv2.ArchiveStatus_ARCHIVE_STATUS_ARCHIVED,
+		DateCreated:         timestamppb.New(time.Date(2017, 3, 9, 10, 33, 9, 0, location)),
+		Id:                  1810448062,
+		DestinationResponse: &pbv2.PutResult_Inspi{Inspi: &pbv2.InspiPutResponse{PhotoDetailId: 5}},
+	}}
+	if !proto.Equal(response, expected) {
+		t.Errorf("Expected %v but got %v", expected, response)
+	}
+}
+
+func TestPutV2Error(t *testing.T) {
+	server := &Server{ServiceBusCaller: &FakeServer{Response: &pb.JSONRPCResponse{Error: &pb.JSONRPCError{Code: -32000, Message: "Order not found"}}}}
+	response, err := newServerV2(server, time.UTC).Put(context.Background(), &pbv2.PutRequest{ContractorId: 72494, OrderNumber: 1, Filename: "a.png", Destination: pbv2.Destination_DESTINATION_VENDORWEB})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	expected := &pbv2.PutResponse{Error: &pbv2.PutError{Code: -32000, Message: "Order not found"}}
+	if !proto.Equal(response, expected) {
+		t.Errorf("Expected %v but got %v", expected, response)
+	}
+}
+
+var archiveStatusCases = []struct {
+	archived string
+	expected pbv2.ArchiveStatus
+}{
+	{archived: "N", expected: pbv2.ArchiveStatus_ARCHIVE_STATUS_ACTIVE},
+	{archived: "Y", expected: pbv2.ArchiveStatus_ARCHIVE_STATUS_ARCHIVED},
+	{archived: "", expected: pbv2.ArchiveStatus_ARCHIVE_STATUS_UNSPECIFIED},
+}
+
+func TestArchiveStatusFromServiceBus(t *testing.T) {
+	for _, c := range archiveStatusCases {
+		if status := archiveStatusFromServiceBus(c.archived); status != c.expected {
+			t.Errorf("Expected %v for %q but got %v", c.expected, c.archived, status)
+		}
+	}
+}
+
+var destinationCases = []struct {
+	destination    pbv2.Destination
+	result         *pb.JSONRPCResult
+	expectedMethod string
+	expectedCode   codes.Code
+	expected       *pbv2.PutResult
+}{
+	{
+		destination:    pbv2.Destination_DESTINATION_VENDORWEB,
+		result:         &pb.JSONRPCResult{Vendorwebresponsedata: &pb.VendorWebPutResponse{Documentid: 7, Annotationid: 8}},
+		expectedMethod: "CONTENTSERVICE.VENDORWEBPUT",
+		expected:       &pbv2.PutResult{DestinationResponse: &pbv2.PutResult_VendorWeb{VendorWeb: &pbv2.VendorWebPutResponse{DocumentId: 7, AnnotationId: 8}}},
+	},
+	{
+		// Data for another destination is left out of the stored content's result
+		destination:    pbv2.Destination_DESTINATION_INSPI,
+		result:         &pb.JSONRPCResult{Id: 1, Vendorwebresponsedata: &pb.VendorWebPutResponse{Documentid: 7}},
+		expectedMethod: "CONTENTSERVICE.PUT",
+		expected:       &pbv2.PutResult{Id: 1},
+	},
+	{
+		// Data for another destination is left out of the stored content's result
+		destination:    pbv2.Destination_DESTINATION_VENDORWEB,
+		result:         &pb.JSONRPCResult{Id: 1, Inspiresponsedata: &pb.InspiPutResponse{Photodetailid: 5}},
+		expectedMethod: "CONTENTSERVICE.VENDORWEBPUT",
+		expected:       &pbv2.PutResult{Id: 1},
+	},
+	{
+		destination:  pbv2.Destination_DESTINATION_UNSPECIFIED,
+		expectedCode: codes.InvalidArgument,
+	},
+}
+
+func TestPutV2Destinations(t *testing.T) {
+	for _, c := range destinationCases {
+		caller := &methodRecorder{response: &pb.JSONRPCResponse{Result: c.result}}
+		server := &Server{ServiceBusCaller: caller}
+		response, err := newServerV2(server, time.UTC).Put(context.Background(), &pbv2.PutRequest{ContractorId: 72494, OrderNumber: 1, Filename: "a.png", Destination: c.destination})
+		if status.Code(err) != c.expectedCode {
+			t.Errorf("%v: expected code %v but got %v", c.destination, c.expectedCode, err)
+		}
+		if caller.method != c.expectedMethod {
+			t.Errorf("%v: expected method %q but got %q", c.destination, c.expectedMethod, caller.method)
+		}
+		if c.expected != nil && !proto.Equal(response.GetResult(), c.expected) {
+			t.Errorf("%v: expected %v but got %v", c.destination, c.expected, response.GetResult())
+		}
+	}
+}
+
+type methodRecorder struct {
+	response *pb.JSONRPCResponse
+	method   string
+}
+
+func (m *methodRecorder) callServiceBus(ctx context.Context, request *pb.JSONRPCRequest) (*pb.JSONRPCResponse, error) {
+	m.method = request.GetMethod()
+	return m.response, nil
+}
+
+var destinationRequestCases = []struct {
+	request        *pbv2.PutRequest
+	expectedCode   codes.Code
+	expectedParams *pb.PutParams
+}{
+	{
+		request:        &pbv2.PutRequest{Destination: pbv2.Destination_DESTINATION_INSPI, DestinationRequest: &pbv2.PutRequest_Inspi{Inspi: &pbv2.InspiPutRequest{PhotoDetailId: 5}}},
+		expectedParams: &pb.PutParams{Inspirequestdata: &pb.InspiPutRequest{Photodetailid: 5}},
+	},
+	{
+		request:        &pbv2.PutRequest{Destination: pbv2.Destination_DESTINATION_VENDORWEB, DestinationRequest: &pbv2.PutRequest_VendorWeb{VendorWeb: &pbv2.VendorWebPutRequest{DocumentId: 7}}},
+		expectedParams: &pb.PutParams{Vendorwebrequestdata: &pb.VendorWebPutRequest{Documentid: 7}},
+	},
+	{
+		request:      &pbv2.PutRequest{Destination: pbv2.Destination_DESTINATION_VENDORWEB, DestinationRequest: &pbv2.PutRequest_Inspi{Inspi: &pbv2.InspiPutRequest{PhotoDetailId: 5}}},
+		expectedCode: codes.InvalidArgument,
+	},
+	{
+		request:      &pbv2.PutRequest{Destination: pbv2.Destination_DESTINATION_INSPI, DestinationRequest: &pbv2.PutRequest_VendorWeb{VendorWeb: &pbv2.VendorWebPutRequest{DocumentId: 7}}},
+		expectedCode: codes.InvalidArgument,
+	},
+}
+
+func TestPutV2DestinationRequests(t *testing.T) {
+	for _, c := range destinationRequestCases {
+		recorder := &recordingCaller{}
+		_, err := newServerV2(&Server{ServiceBusCaller: recorder}, time.UTC).Put(context.Background(), c.request)
+		if status.Code(err) != c.expectedCode {
+			t.Errorf("%v: expected code %v but got %v", c.request, c.expectedCode, err)
+		}
+		if c.expectedParams == nil {
+			if recorder.request != nil {
+				t.Errorf("%v: expected nothing sent but got %v", c.request, recorder.request)
+			}
+			continue
+		}
+		if !proto.Equal(recorder.request.GetParams(), c.expectedParams) {
+			t.Errorf("Expected %v but got %v", c.expectedParams, recorder.request.GetParams())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterExporter writes each span as a line of JSON
+type WriterExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriterExporter creates an exporter writing JSON lines to w
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{encoder: json.NewEncoder(w)}
+}
+
+// ExportSpan writes span to the underlying writer
+func (e *WriterExporter) ExportSpan(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoder.Encode(span)
+}
+
+// NewFileExporter creates an exporter appending JSON lines to the file at
+// path, or writing to stdout if path is "-"
+func NewFileExporter(path string) (*WriterExporter, error) {
+	if path == "-" {
+		return NewWriterExporter(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterExporter(file), nil
+}
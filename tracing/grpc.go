@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Extract returns the remote span context carried in the incoming gRPC
+// metadata of ctx, or a zero SpanContext if there is none
+func Extract(ctx context.Context) SpanContext {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return SpanContext{}
+	}
+	values := md.Get(TraceparentHeader)
+	if len(values) == 0 {
+		return SpanContext{}
+	}
+	sc, err := ParseTraceparent(values[0])
+	if err != nil {
+		return SpanContext{}
+	}
+	return sc
+}
+
+// Inject adds the traceparent of the current span in ctx to the outgoing
+// gRPC metadata
+func Inject(ctx context.Context) context.Context {
+	span := FromContext(ctx)
+	if span == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, TraceparentHeader, span.Context().Traceparent())
+}
+
+// InjectHTTP sets the traceparent header of the current span in ctx on header
+func InjectHTTP(ctx context.Context, header http.Header) {
+	span := FromContext(ctx)
+	if span == nil {
+		return
+	}
+	header.Set(TraceparentHeader, span.Context().Traceparent())
+}
+
+// UnaryServerInterceptor starts a span for each unary call, continuing the
+// trace sent by the caller
+func (t *Tracer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := t.Start(ctx, info.FullMethod, Extract(ctx))
+		defer span.End()
+		resp, err := handler(ctx, req)
+		span.SetError(err)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor starts a span for each unary call and sends its
+// traceparent to the server
+func (t *Tracer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := t.Start(ctx, method, SpanContext{})
+		defer span.End()
+		err := invoker(Inject(ctx), method, req, reply, cc, opts...)
+		span.SetError(err)
+		return err
+	}
+}
@@ -0,0 +1,206 @@
+// Package tracing records timed spans for requests flowing between the
+// client, the content service and ServiceBus, and propagates them using the
+// W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C trace context header name
+const TraceparentHeader = "traceparent"
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether both the trace and span ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Traceparent formats the span context as a W3C traceparent value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// ParseTraceparent parses a W3C traceparent value
+func ParseTraceparent(value string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, fmt.Errorf("Invalid traceparent: %q", value)
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("Invalid traceparent: %q", value)
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("Invalid traceparent: %q", value)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("Invalid trace id: %s", err)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("Invalid span id: %s", err)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, fmt.Errorf("Invalid trace flags: %s", err)
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+	if !sc.IsValid() {
+		return sc, errors.New("Invalid traceparent: zero trace or span id")
+	}
+
+	return sc, nil
+}
+
+// SpanData is the finished record of a span handed to an Exporter
+type SpanData struct {
+	Name         string            `json:"name"`
+	TraceID      string            `json:"trace_id"`
+	SpanID       string            `json:"span_id"`
+	ParentSpanID string            `json:"parent_span_id,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	Duration     time.Duration     `json:"duration_ns"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Exporter receives finished spans
+type Exporter interface {
+	ExportSpan(span *SpanData)
+}
+
+// Tracer starts spans and hands them to its Exporter when they end
+type Tracer struct {
+	Exporter Exporter
+}
+
+// NewTracer creates a tracer exporting to exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{Exporter: exporter}
+}
+
+// Span is an in-progress timed operation
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+	parent  [8]byte
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+type spanKey struct{}
+
+// Start starts a span which is a child of the span in ctx, or of remote if
+// ctx holds no span. A zero remote starts a new trace.
+func (t *Tracer) Start(ctx context.Context, name string, remote SpanContext) (context.Context, *Span) {
+	if parent := FromContext(ctx); parent != nil {
+		remote = parent.context
+	}
+	span := &Span{tracer: t}
+	if remote.IsValid() {
+		span.context.TraceID = remote.TraceID
+		span.context.Sampled = remote.Sampled
+		span.parent = remote.SpanID
+	} else {
+		rand.Read(span.context.TraceID[:])
+		span.context.Sampled = true
+	}
+	rand.Read(span.context.SpanID[:])
+	span.data.Name = name
+	span.data.Start = time.Now()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartSpan starts a child of the span in ctx using the same tracer. If ctx
+// holds no span the returned span records nothing.
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, SpanContext{})
+}
+
+// FromContext returns the current span in ctx, if any
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Context returns the span's identifiers. It is safe to call on a nil span.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttribute records a key/value pair on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = fmt.Sprint(value)
+}
+
+// SetError records err on the span if it is not nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it. Only the first call has any effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	s.data.Duration = s.data.End.Sub(s.data.Start)
+	s.data.TraceID = hex.EncodeToString(s.context.TraceID[:])
+	s.data.SpanID = hex.EncodeToString(s.context.SpanID[:])
+	if s.parent != [8]byte{} {
+		s.data.ParentSpanID = hex.EncodeToString(s.parent[:])
+	}
+	data := s.data
+	s.mu.Unlock()
+
+	if s.tracer != nil && s.tracer.Exporter != nil && s.context.Sampled {
+		s.tracer.Exporter.ExportSpan(&data)
+	}
+}
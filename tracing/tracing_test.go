@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type recordingExporter struct {
+	spans []*SpanData
+}
+
+func (r *recordingExporter) ExportSpan(span *SpanData) {
+	r.spans = append(r.spans, span)
+}
+
+var traceparentCases = []struct {
+	value       string
+	expectedErr bool
+	sampled     bool
+}{
+	{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sampled: true},
+	{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", sampled: false},
+	{value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", expectedErr: true},
+	{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", expectedErr: true},
+	{value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", expectedErr: true},
+	{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", expectedErr: true},
+	{value: "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", expectedErr: true},
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for _, c := range traceparentCases {
+		sc, err := ParseTraceparent(c.value)
+		if (err != nil) != c.expectedErr {
+			t.Errorf("%s: expected error %v but got %v", c.value, c.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if sc.Sampled != c.sampled {
+			t.Errorf("%s: expected sampled %v but got %v", c.value, c.sampled, sc.Sampled)
+		}
+		if sc.Traceparent() != c.value {
+			t.Errorf("Expected %s but got %s", c.value, sc.Traceparent())
+		}
+	}
+}
+
+func TestChildSpansShareTrace(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, root := tracer.Start(context.Background(), "root", remote)
+	_, child := StartSpan(ctx, "child")
+	child.SetAttribute("bytes", 180)
+	child.End()
+	root.End()
+	root.End()
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 spans but got %d", len(exporter.spans))
+	}
+	childData, rootData := exporter.spans[0], exporter.spans[1]
+	if rootData.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || childData.TraceID != rootData.TraceID {
+		t.Errorf("Expected spans to continue the remote trace but got %s and %s", rootData.TraceID, childData.TraceID)
+	}
+	if rootData.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("Expected root parent to be the remote span but got %s", rootData.ParentSpanID)
+	}
+	if childData.ParentSpanID != rootData.SpanID {
+		t.Errorf("Expected child parent %s but got %s", rootData.SpanID, childData.ParentSpanID)
+	}
+	if !reflect.DeepEqual(childData.Attributes, map[string]string{"bytes": "180"}) {
+		t.Errorf("Unexpected attributes %v", childData.Attributes)
+	}
+}
+
+func TestStartSpanWithoutParent(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "orphan")
+	if span != nil || FromContext(ctx) != nil {
+		t.Errorf("Expected no span without a parent")
+	}
+	span.SetAttribute("key", "value")
+	span.SetError(nil)
+	span.End()
+}
+
+func TestPropagation(t *testing.T) {
+	tracer := NewTracer(&recordingExporter{})
+	ctx, span := tracer.Start(context.Background(), "client", SpanContext{})
+
+	md, _ := metadata.FromOutgoingContext(Inject(ctx))
+	incoming := metadata.NewIncomingContext(context.Background(), md)
+	if Extract(incoming) != span.Context() {
+		t.Errorf("Expected %v but got %v", span.Context(), Extract(incoming))
+	}
+
+	header := http.Header{}
+	InjectHTTP(ctx, header)
+	if header.Get(TraceparentHeader) != span.Context().Traceparent() {
+		t.Errorf("Expected %s but got %s", span.Context().Traceparent(), header.Get(TraceparentHeader))
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := NewTracer(NewWriterExporter(buf))
+	_, span := tracer.Start(context.Background(), "put", SpanContext{})
+	span.End()
+
+	data := &SpanData{}
+	if err := json.Unmarshal(buf.Bytes(), data); err != nil {
+		t.Fatalf("Expected JSON span but got %v", err)
+	}
+	if data.Name != "put" {
+		t.Errorf("Expected name put but got %s", data.Name)
+	}
+}